@@ -0,0 +1,517 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// traceFunc 插入程式中的追蹤函式，在每個 fmt.Print* 的輸出前後印出標記
+	traceFunc = "md2ipynbTrace"
+	// traceStart、traceEnd 標記的開頭與結尾：\x1e<編號>\x1f 開始、\x1e\x1f 結束
+	traceStart = '\x1e'
+	traceEnd   = '\x1f'
+)
+
+// printSite 程式中一個 fmt.Print* 敘述
+type printSite struct {
+	// Line 呼叫結束的行（cell 中的行，從 0 開始），輸出註解寫在這一行的行尾
+	Line int
+	// Column 呼叫結束的位置（該行中的 byte）
+	Column int
+}
+
+// instrumentedProgram 插入追蹤標記後的程式
+type instrumentedProgram struct {
+	Source string
+	Sites  []printSite
+	// MainEnd func main 結尾 `}` 所在的行；-1 表示 func main 寫在同一行
+	MainEnd int
+	// MainIndent func main 中敘述的縮排
+	MainIndent string
+}
+
+// instrumentPrints 將每個 fmt.Print* 敘述包進追蹤函式，執行時就能知道每段輸出來自哪一行。
+// 只處理有 func main 的完整程式；行號不變，方便對應回原本的程式
+func instrumentPrints(source string) (*instrumentedProgram, bool) {
+	if !mainFuncRegex.MatchString(source) {
+		return nil, false
+	}
+	fset := token.NewFileSet()
+	file, offset, err := parseCodeCell(fset, source)
+	if err != nil || offset > 1 {
+		return nil, false
+	}
+	prefix := 0
+	if offset == 1 {
+		prefix = len("package main\n")
+	}
+
+	fmtName := ""
+	for _, spec := range file.Imports {
+		if path, _ := strconv.Unquote(spec.Path.Value); path == "fmt" {
+			fmtName = "fmt"
+			if spec.Name != nil {
+				fmtName = spec.Name.Name
+			}
+		}
+	}
+	if fmtName == "" || fmtName == "." || fmtName == "_" {
+		return nil, false
+	}
+
+	program := &instrumentedProgram{MainEnd: -1, MainIndent: "\t"}
+	lines := strings.Split(source, "\n")
+	var calls []*ast.CallExpr
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncDecl:
+			if n.Name.Name == "main" && n.Recv == nil && n.Body != nil {
+				rbrace := fset.Position(n.Body.Rbrace)
+				if rbrace.Line != fset.Position(n.Body.Lbrace).Line {
+					program.MainEnd = rbrace.Line - 1 - offset
+				}
+				if len(n.Body.List) > 0 {
+					if line := fset.Position(n.Body.List[0].Pos()).Line - 1 - offset; line >= 0 && line < len(lines) {
+						program.MainIndent = lines[line][:len(lines[line])-len(strings.TrimLeft(lines[line], " \t"))]
+					}
+				}
+			}
+		case *ast.ExprStmt:
+			call, ok := n.X.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			selector, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || !printFuncs[selector.Sel.Name] {
+				return true
+			}
+			if pkg, ok := selector.X.(*ast.Ident); ok && pkg.Name == fmtName {
+				calls = append(calls, call)
+				end := fset.Position(call.End())
+				program.Sites = append(program.Sites, printSite{Line: end.Line - 1 - offset, Column: end.Column - 1})
+				return false
+			}
+		}
+		return true
+	})
+
+	// 由後往前取代，前面的位置才不會變動
+	instrumented := source
+	for i := len(calls) - 1; i >= 0; i-- {
+		start := fset.Position(calls[i].Pos()).Offset - prefix
+		end := fset.Position(calls[i].End()).Offset - prefix
+		wrapped := fmt.Sprintf("%s(%d, func() { %s })", traceFunc, i, instrumented[start:end])
+		instrumented = instrumented[:start] + wrapped + instrumented[end:]
+	}
+	instrumented += fmt.Sprintf("\n\nfunc %s(site int, call func()) {\n\t%s.Print(\"%c\", site, \"%c\")\n\tcall()\n\t%s.Print(\"%c%c\")\n}\n",
+		traceFunc, fmtName, traceStart, traceEnd, fmtName, traceStart, traceEnd)
+	program.Source = instrumented
+	return program, true
+}
+
+// printTrace 執行插入追蹤標記的程式後，各個 fmt.Print* 的輸出
+type printTrace struct {
+	// Outputs 每個 site 所有執行次數的輸出，依序串接
+	Outputs map[int]string
+	// Calls 每個 site 執行的次數
+	Calls map[int]int
+	// Stdout 去掉標記後的完整輸出
+	Stdout string
+	// Interleaved 輸出標記交錯（例如多個 goroutine 同時輸出），無法對應到各行
+	Interleaved bool
+}
+
+// parseTrace 拆解含追蹤標記的輸出
+func parseTrace(stdout string) printTrace {
+	trace := printTrace{Outputs: map[int]string{}, Calls: map[int]int{}}
+	var plain strings.Builder
+	open := -1
+	for stdout != "" {
+		i := strings.IndexRune(stdout, traceStart)
+		if i < 0 {
+			i = len(stdout)
+		}
+		plain.WriteString(stdout[:i])
+		if open >= 0 {
+			trace.Outputs[open] += stdout[:i]
+		}
+		stdout = stdout[i:]
+		if stdout == "" {
+			break
+		}
+
+		j := strings.IndexRune(stdout, traceEnd)
+		if j < 0 {
+			plain.WriteString(stdout)
+			break
+		}
+		site, err := strconv.Atoi(stdout[1:j])
+		stdout = stdout[j+1:]
+		switch {
+		case err != nil: // 結束標記
+			open = -1
+		case open >= 0:
+			trace.Interleaved = true
+			open = site
+			trace.Calls[site]++
+		default:
+			open = site
+			trace.Outputs[site] += ""
+			trace.Calls[site]++
+		}
+	}
+	trace.Stdout = plain.String()
+	return trace
+}
+
+// annotation 填寫輸出註解的結果
+type annotation struct {
+	Source string
+	// Unstable 每次執行輸出都不同（時間、記憶體位址、goroutine 順序）而沒有填寫的地方
+	Unstable int
+	// Multiline 輸出多行或執行多次（例如在迴圈或多次呼叫的函式中）而沒有填寫在行尾的地方
+	Multiline int
+}
+
+// annotateProgram 依多次執行的結果填寫或更新輸出註解：
+//   - 已經有 `// 輸出:` 區塊時更新區塊內容
+//   - 只輸出一行的 fmt.Print* 寫在行尾（`// 輸出: ...`），已有的行尾輸出註解會被更新；
+//     輸出多行或執行多次的地方略過（迴圈中的 fmt.Print("=") 串起來不是這一行的輸出）
+//   - 輸出交錯、或沒有任何一個地方只輸出一行時，在 func main 結尾加上 `// 輸出:` 區塊
+//
+// 每次執行結果不同的輸出不會填寫，保留原本手寫的說明（例如「約 3 秒」）
+func annotateProgram(source string, program *instrumentedProgram, traces []printTrace) annotation {
+	lines := strings.Split(source, "\n")
+	first := traces[0]
+	output := strings.Split(strings.TrimRight(first.Stdout, "\n"), "\n")
+
+	for i, line := range lines {
+		match := outputBlockRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		if !stableStdout(traces, match[1] != "" || match[2] != "") {
+			return annotation{Source: source, Unstable: 1}
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		end := i + 1
+		for end < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[end]), "//") {
+			end++
+		}
+		block := outputBlock(indent, output)
+		lines = append(lines[:i+1], append(block, lines[end:]...)...)
+		return annotation{Source: strings.Join(lines, "\n")}
+	}
+
+	perLine := map[int]int{}
+	for _, site := range program.Sites {
+		perLine[site.Line]++
+	}
+	// siteOutput 回傳可以寫在行尾的輸出；multiline 表示這個地方在某次執行中輸出了多行或執行了多次
+	siteOutput := func(index int) (out string, multiline bool) {
+		site := program.Sites[index]
+		if perLine[site.Line] > 1 || site.Line >= len(lines) {
+			return "", false
+		}
+		for _, trace := range traces {
+			if trace.Calls[index] > 1 || strings.Contains(strings.TrimSuffix(trace.Outputs[index], "\n"), "\n") {
+				return "", true
+			}
+		}
+		return strings.TrimSuffix(first.Outputs[index], "\n"), false
+	}
+
+	inline := false
+	interleaved := false
+	for index := range program.Sites {
+		if out, _ := siteOutput(index); strings.TrimSpace(out) != "" {
+			inline = true
+		}
+	}
+	for _, trace := range traces {
+		interleaved = interleaved || trace.Interleaved
+	}
+	if interleaved || !inline {
+		switch {
+		case program.MainEnd < 0 || strings.TrimSpace(first.Stdout) == "":
+			return annotation{Source: source}
+		case !stableStdout(traces, false):
+			return annotation{Source: source, Unstable: 1}
+		}
+		indent := program.MainIndent
+		block := append([]string{indent + "// 輸出:"}, outputBlock(indent, output)...)
+		lines = append(lines[:program.MainEnd], append(block, lines[program.MainEnd:]...)...)
+		return annotation{Source: strings.Join(lines, "\n")}
+	}
+
+	result := annotation{}
+	for index, site := range program.Sites {
+		out, multiline := siteOutput(index)
+		if multiline {
+			result.Multiline++
+			continue
+		}
+		if strings.TrimSpace(out) == "" {
+			continue
+		}
+		stable := true
+		for _, trace := range traces[1:] {
+			stable = stable && trace.Outputs[index] == first.Outputs[index]
+		}
+		if !stable {
+			result.Unstable++
+			continue
+		}
+
+		line := lines[site.Line]
+		code, rest := line[:site.Column], strings.TrimSpace(line[site.Column:])
+		switch {
+		case rest == "":
+			lines[site.Line] = code + " // 輸出: " + out
+		case strings.HasPrefix(rest, "//"):
+			// 只更新輸出註解，保留「無序」「（說明）」等標示；其他說明註解不動
+			if match := outputInlineRegex.FindStringSubmatchIndex(rest); match != nil {
+				lines[site.Line] = code + " " + rest[:match[6]] + out
+			}
+		}
+	}
+	result.Source = strings.Join(lines, "\n")
+	return result
+}
+
+// stableStdout 每次執行的完整輸出是否相同；unordered 時只比較排序後的各行
+func stableStdout(traces []printTrace, unordered bool) bool {
+	normalize := func(stdout string) string {
+		if !unordered {
+			return stdout
+		}
+		lines := strings.Split(strings.TrimRight(stdout, "\n"), "\n")
+		sort.Strings(lines)
+		return strings.Join(lines, "\n")
+	}
+	for _, trace := range traces[1:] {
+		if normalize(trace.Stdout) != normalize(traces[0].Stdout) {
+			return false
+		}
+	}
+	return true
+}
+
+// outputBlock 將輸出寫成 `// ...` 註解，每行一個
+func outputBlock(indent string, output []string) []string {
+	block := make([]string, len(output))
+	for i, line := range output {
+		block[i] = strings.TrimRight(indent+"// "+line, " ")
+	}
+	return block
+}
+
+// AnnotateCell 執行 code cell runs 次，依輸出填寫 `// 輸出:` 註解；沒有 func main、無法編譯或逾時時 source 不變。
+// 執行中 panic（例如示範 nil 指標）時仍依 panic 之前的輸出填寫
+func AnnotateCell(ctx context.Context, source string, runs int, timeout time.Duration) (annotation, CellRun) {
+	program, ok := instrumentPrints(source)
+	if !ok {
+		return annotation{Source: source}, CellRun{Status: runSkipped}
+	}
+
+	var first CellRun
+	var traces []printTrace
+	for i := 0; i < max(runs, 1); i++ {
+		run := RunGoProgram(ctx, program.Source, timeout)
+		if run.Status != runPassed && run.Status != runRuntimeError {
+			return annotation{Source: source}, run
+		}
+		trace := parseTrace(run.Stdout)
+		run.Stdout = trace.Stdout
+		if i == 0 {
+			first = run
+		}
+		traces = append(traces, trace)
+	}
+	return annotateProgram(source, program, traces), first
+}
+
+// annotateTarget 要填寫輸出的檔案：來源檔、notebook 或 Go 範例程式
+type annotateTarget struct {
+	*lintTarget
+	// Go 範例程式（.go）的內容；整個檔案視為一個 cell
+	GoSource string
+}
+
+// cells 回傳 code cell 的索引與內容
+func (t *annotateTarget) cells() map[int]string {
+	if t.lintTarget == nil {
+		return map[int]string{0: t.GoSource}
+	}
+	cells := map[int]string{}
+	for i, cell := range t.Notebook.Cells {
+		if cell.CellType != "code" || strings.TrimSpace(cell.Text()) == "" {
+			continue
+		}
+		// 來源檔中找不到原樣內容的 cell（例如由練習標記組合而成）無法寫回
+		if t.Lines != nil && t.cellStart(i) < 0 {
+			continue
+		}
+		cells[i] = cell.Text()
+	}
+	return cells
+}
+
+// rewrite 以新的 cell 內容產生檔案；來源檔只取代 cell 所在的行，其餘內容不動
+func (t *annotateTarget) rewrite(changed map[int]string) ([]byte, error) {
+	if t.lintTarget == nil {
+		return []byte(changed[0]), nil
+	}
+	if t.Lines == nil {
+		for i, text := range changed {
+			t.Notebook.Cells[i].Source = splitLines(text)
+		}
+		return t.Notebook.ToJSON()
+	}
+
+	indexes := make([]int, 0, len(changed))
+	for i := range changed {
+		indexes = append(indexes, i)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(indexes)))
+
+	lines := append([]string(nil), t.Lines...)
+	for _, i := range indexes {
+		start := t.cellStart(i)
+		end := start + len(strings.Split(t.Notebook.Cells[i].Text(), "\n"))
+		if start < 0 || end > len(lines) {
+			return nil, fmt.Errorf("failed to locate cell %d in %s", i, t.Path)
+		}
+		lines = append(lines[:start], append(strings.Split(changed[i], "\n"), lines[end:]...)...)
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+func loadAnnotateTarget(path string) (*annotateTarget, error) {
+	if filepath.Ext(path) == ".go" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return &annotateTarget{GoSource: string(data)}, nil
+	}
+	target, err := loadLintTarget(path)
+	if err != nil {
+		return nil, err
+	}
+	return &annotateTarget{lintTarget: target}, nil
+}
+
+// runAnnotate annotate 子命令：執行 code cell，依實際輸出填寫或更新 `// 輸出:` 註解
+func runAnnotate(args []string) error {
+	fs := flag.NewFlagSet("annotate", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "只輸出 unified diff，不寫入檔案")
+	timeout := fs.Duration("timeout", 0, "每個 cell 的執行時間上限（預設為設定檔的 exec_timeout）")
+	runs := fs.Int("runs", 2, "每個 cell 執行的次數；每次輸出不同的地方不填寫")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s annotate [--dry-run] [--runs N] [--timeout d] file-or-dir...\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "可以是來源檔、notebook 或 Go 範例程式（例如 ch6/pointer.go）\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	paths, err := collectLintFiles(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	failed := 0
+	for _, path := range paths {
+		limit := *timeout
+		if limit == 0 {
+			cfg, err := LoadConfig(path)
+			if err != nil {
+				return err
+			}
+			limit = time.Duration(cfg.ExecTimeout)
+		}
+		target, err := loadAnnotateTarget(path)
+		if err != nil {
+			return err
+		}
+
+		cells := target.cells()
+		indexes := make([]int, 0, len(cells))
+		for i := range cells {
+			indexes = append(indexes, i)
+		}
+		sort.Ints(indexes)
+
+		changed := map[int]string{}
+		var diffs []CellDiff
+		skipped, unstable, multiline := 0, 0, 0
+		for _, i := range indexes {
+			result, run := AnnotateCell(ctx, cells[i], *runs, limit)
+			unstable += result.Unstable
+			multiline += result.Multiline
+			annotated := result.Source
+			switch run.Status {
+			case runRuntimeError:
+				fmt.Printf("ℹ️  %s: cell %d 執行中結束，只填寫之前的輸出: %s\n", path, i, firstLine(run.Stderr))
+			case runCompileError, runTimeout:
+				skipped++
+				fmt.Printf("❌ %s: cell %d %s，略過\n  %s\n", path, i, run.Status, firstLine(run.Stderr))
+				continue
+			}
+			if annotated != cells[i] {
+				changed[i] = annotated
+				diffs = append(diffs, CellDiff{Kind: '~', Index: i, CellType: "code", Old: cells[i], New: annotated})
+			}
+		}
+		failed += skipped
+		if unstable > 0 {
+			fmt.Printf("ℹ️  %s: %d 個輸出每次執行都不同，保留原本的註解\n", path, unstable)
+		}
+		if multiline > 0 {
+			fmt.Printf("ℹ️  %s: %d 個地方輸出多行或執行多次，沒有寫在行尾\n", path, multiline)
+		}
+		if len(changed) == 0 {
+			if skipped == 0 && unstable == 0 && multiline == 0 {
+				fmt.Printf("✅ %s 的輸出註解都是最新的\n", path)
+			}
+			continue
+		}
+
+		if *dryRun {
+			WriteUnifiedDiff(os.Stdout, path, path+" (dry-run)", diffs)
+			fmt.Printf("📝 %d 個 cells 會變更（未寫入）\n", len(diffs))
+			continue
+		}
+		data, err := target.rewrite(changed)
+		if err != nil {
+			return err
+		}
+		WriteUnifiedDiff(os.Stdout, path, path, diffs)
+		if err := writeFileAtomic(path, data, false); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Printf("📝 已更新 %d 個 cells 的輸出註解: %s\n", len(diffs), path)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d cells failed to run", failed)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInstrumentPrints(t *testing.T) {
+	source := "import f \"fmt\"\n\nfunc main() {\n    f.Println(\"a\",\n        1)\n    defer f.Println(\"later\")\n}"
+	program, ok := instrumentPrints(source)
+	if !ok {
+		t.Fatal("Expected the gonb cell to be instrumented")
+	}
+	if len(program.Sites) != 1 || program.Sites[0] != (printSite{Line: 4, Column: 10}) {
+		t.Errorf("Unexpected sites: %+v", program.Sites)
+	}
+	if program.MainEnd != 6 || program.MainIndent != "    " {
+		t.Errorf("Unexpected main end %d, indent %q", program.MainEnd, program.MainIndent)
+	}
+	if !strings.Contains(program.Source, "md2ipynbTrace(0, func() { f.Println(\"a\",\n        1) })") {
+		t.Errorf("Call should be wrapped without changing line numbers:\n%s", program.Source)
+	}
+
+	if _, ok := instrumentPrints("x := 1\nfmt.Println(x)"); ok {
+		t.Error("Fragments without func main cannot run")
+	}
+}
+
+func TestParseTrace(t *testing.T) {
+	trace := parseTrace("start\n\x1e0\x1fa\n\x1e\x1f\x1e1\x1fb\x1e\x1f\x1e1\x1fc\n\x1e\x1f")
+	if trace.Stdout != "start\na\nbc\n" || trace.Interleaved {
+		t.Errorf("Unexpected stdout %q", trace.Stdout)
+	}
+	if trace.Outputs[0] != "a\n" || trace.Outputs[1] != "bc\n" {
+		t.Errorf("Unexpected outputs %q", trace.Outputs)
+	}
+	if trace := parseTrace("\x1e0\x1f\x1e1\x1fx\x1e\x1f\x1e\x1f"); !trace.Interleaved {
+		t.Error("Nested markers mean goroutines printed at the same time")
+	}
+}
+
+func TestAnnotateProgram(t *testing.T) {
+	source := "import \"fmt\"\n\nfunc main() {\n\tfmt.Println(x)\n\tfmt.Println(y) // 輸出（順序不定）: old\n\tfmt.Println(z) // 印出 z\n\tfmt.Println(t) // 輸出: 約 1 秒\n}"
+	program, _ := instrumentPrints(source)
+	run := func(t string) printTrace {
+		return printTrace{Outputs: map[int]string{0: "1\n", 1: "2\n", 2: "3\n", 3: t + "\n"}}
+	}
+
+	got := annotateProgram(source, program, []printTrace{run("1.01s"), run("0.99s")})
+	want := "import \"fmt\"\n\nfunc main() {\n\tfmt.Println(x) // 輸出: 1\n\tfmt.Println(y) // 輸出（順序不定）: 2\n\tfmt.Println(z) // 印出 z\n\tfmt.Println(t) // 輸出: 約 1 秒\n}"
+	if got.Source != want || got.Unstable != 1 {
+		t.Errorf("annotateProgram = %q (unstable %d), want %q", got.Source, got.Unstable, want)
+	}
+
+	// 同一行執行多次：整段輸出寫在 func main 結尾
+	loop := printTrace{Outputs: map[int]string{0: "1\n1\n"}, Stdout: "1\n1\n"}
+	got = annotateProgram(source, program, []printTrace{loop})
+	if !strings.HasSuffix(got.Source, "\t// 輸出:\n\t// 1\n\t// 1\n}") {
+		t.Errorf("Expected an output block:\n%s", got.Source)
+	}
+	// 只有部分地方輸出多行：其他地方照樣寫在行尾，多行的地方略過
+	mixed := printTrace{Outputs: map[int]string{0: "1\n", 1: "2\n2\n", 2: "3\n"}, Stdout: "1\n2\n2\n3\n"}
+	got = annotateProgram(source, program, []printTrace{mixed})
+	if !strings.Contains(got.Source, "\tfmt.Println(x) // 輸出: 1\n\tfmt.Println(y) // 輸出（順序不定）: old\n") || got.Multiline != 1 {
+		t.Errorf("Expected per-site annotation (multiline %d):\n%s", got.Multiline, got.Source)
+	}
+	if strings.Contains(got.Source, "// 輸出:\n") {
+		t.Errorf("No output block expected when some sites are annotated inline:\n%s", got.Source)
+	}
+	// 迴圈中的 fmt.Print("=") 執行多次，串起來只有一行也不寫在行尾
+	repeated := printTrace{Outputs: map[int]string{0: "==========", 1: "2\n"}, Calls: map[int]int{0: 10, 1: 1}, Stdout: "==========2\n"}
+	got = annotateProgram(source, program, []printTrace{repeated})
+	if strings.Contains(got.Source, "==========") || !strings.Contains(got.Source, "\tfmt.Println(y) // 輸出（順序不定）: 2\n") || got.Multiline != 1 {
+		t.Errorf("Expected the repeated site to be skipped (multiline %d):\n%s", got.Multiline, got.Source)
+	}
+	if trace := parseTrace(instrumentedOutput(0, "=") + instrumentedOutput(0, "=") + "\n"); trace.Calls[0] != 2 || trace.Outputs[0] != "==" {
+		t.Errorf("Expected two calls of site 0, got %+v", trace)
+	}
+	// 已經有區塊時更新區塊
+	block := "import \"fmt\"\n\nfunc main() {\n\tfmt.Println(x)\n\t// 輸出:\n\t// old\n\t// old\n}"
+	program, _ = instrumentPrints(block)
+	got = annotateProgram(block, program, []printTrace{{Outputs: map[int]string{0: "new\n"}, Stdout: "new\n"}})
+	if got.Source != "import \"fmt\"\n\nfunc main() {\n\tfmt.Println(x)\n\t// 輸出:\n\t// new\n}" {
+		t.Errorf("Expected the block to be refreshed:\n%s", got.Source)
+	}
+}
+
+// instrumentedOutput 插入追蹤標記的程式中，site 印出 out 時的輸出
+func instrumentedOutput(site int, out string) string {
+	return fmt.Sprintf("%c%d%c%s%c%c", traceStart, site, traceEnd, out, traceStart, traceEnd)
+}
+
+func TestAnnotateTarget_Rewrite(t *testing.T) {
+	// cell 以空行開頭，前面還有一個不屬於 cell 的空行：要以整段內容找出 cell 的位置
+	nb := NewNotebook()
+	nb.AddCodeCell("a", "\nfmt.Println(1)")
+	target := &annotateTarget{lintTarget: &lintTarget{
+		Path:     "ch5.go",
+		Notebook: nb,
+		Lines:    []string{"// %%", "", "", "fmt.Println(1)", ""},
+		Spans:    []CellSpan{{Start: 0, End: 4}},
+	}}
+
+	data, err := target.rewrite(map[int]string{0: "\nfmt.Println(1) // 輸出: 1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "// %%\n\n\nfmt.Println(1) // 輸出: 1\n"; string(data) != want {
+		t.Errorf("rewrite = %q, want %q", data, want)
+	}
+}
+
+func TestRunAnnotate(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not available")
+	}
+
+	dir := t.TempDir()
+	source := filepath.Join(dir, "ch5_functions_source.md")
+	writeTestFile(t, source, "<!-- MARKDOWN_CELL -->\n# 第五章\n<!-- END_MARKDOWN_CELL -->\n\n"+
+		"<!-- CODE_CELL -->\n```go\n// 範例\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"sum:\", 1+2) // 輸出: 4\n\tfmt.Printf(\"%d\\n\", 2*3)\n}\n```\n<!-- END_CODE_CELL -->\n")
+
+	if err := run([]string{"annotate", "--dry-run", "--runs", "1", source}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(readFile(t, source), "// 輸出: 6") {
+		t.Fatal("--dry-run should not write the file")
+	}
+	if err := run([]string{"annotate", "--runs", "1", source}); err != nil {
+		t.Fatal(err)
+	}
+	got := readFile(t, source)
+	for _, want := range []string{"fmt.Println(\"sum:\", 1+2) // 輸出: sum: 3\n", "fmt.Printf(\"%d\\n\", 2*3) // 輸出: 6\n}\n```\n<!-- END_CODE_CELL -->\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Annotated source should contain %q:\n%s", want, got)
+		}
+	}
+
+	loop := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(1)\n\tfor {\n\t}\n}"
+	result, cellRun := AnnotateCell(context.Background(), loop, 1, 500*time.Millisecond)
+	if cellRun.Status != runTimeout || result.Source != loop {
+		t.Errorf("A timed-out cell should be left unchanged: %+v", cellRun)
+	}
+}
@@ -0,0 +1,745 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultBankPath bank build 預設輸出的題庫
+const defaultBankPath = "question_bank.json"
+
+var (
+	// questionHeadingRegex 手寫考題的題目標題，例如「## 題目 3：零值」「### 題目 2-1：切片基礎操作 (5分)」
+	questionHeadingRegex = regexp.MustCompile(`(?m)^#{2,3}[ \t]*題目[ \t]*(\d+(?:-\d+)?)[ \t]*[:：]?[ \t]*(.*?)[ \t]*$`)
+	// exerciseCommentRegex 手寫考題 code cell 中的練習，例如「// 練習4: 為自定義型態添加方法」
+	exerciseCommentRegex = regexp.MustCompile(`^\s*//\s*練習\s*(\d+)\s*[:：]\s*(.+?)\s*$`)
+	// examHeadingRegex 考題/go_exam.ipynb 只有題號的標題，例如「## 7」，題目是後面的程式
+	examHeadingRegex = regexp.MustCompile(`^#{1,3}\s*(\d+)\s*$`)
+	// reviewHeadingRegex 手寫改卷講解的題目標題，前面可能有圖示，例如「### ❌ 題目 3-3：高效字串建構 (得分：0/6) ❌」
+	reviewHeadingRegex = regexp.MustCompile(`^#{2,3}[ \t]*(?:\S+[ \t]+)?題目[ \t]*(\d+(?:-\d+)?)`)
+	// reviewSolutionRegex 改卷講解中解答的開頭，例如「**正確答案：**」「### 正確答案：」「**改進版本：**」
+	reviewSolutionRegex = regexp.MustCompile(`^(?:\*\*|#{3,4}[ \t]*)(?:正確答案|正確解答|參考答案|改進版本|改進建議)[:：]?(?:\*\*)?[ \t]*$`)
+	// markdownHeadingRegex 一到三級的 markdown 標題，結束手寫改卷講解中的一段
+	markdownHeadingRegex = regexp.MustCompile(`^#{1,3}\s`)
+	// reviewCellIDRegex review 產生的講解與解答 cell，例如 review-2-ch5-ex1、review-2-ch5-ex1-solution-2
+	reviewCellIDRegex = regexp.MustCompile(`^review-\d+-(.+?)(-solution(?:-\d+)?)?$`)
+)
+
+// BankQuestion 題庫中的一題
+type BankQuestion struct {
+	ID         string   `json:"id"`
+	Kind       string   `json:"kind"`
+	Chapter    int      `json:"chapter,omitempty"`
+	Title      string   `json:"title,omitempty"`
+	Difficulty string   `json:"difficulty,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	// Prompt 題目內容：題目是 markdown，練習是以註解寫出題目的程式
+	Prompt string `json:"prompt"`
+	Tests  string `json:"tests,omitempty"`
+	// Answer 解答，格式與 ANSWER 區塊相同：```go 區塊是程式，其餘是說明
+	Answer string `json:"answer,omitempty"`
+	// Source 題目所在的 notebook
+	Source string `json:"source"`
+	// Cell 手寫考題作答 cell 的索引，沒有時為 -1；不寫入題庫
+	Cell int `json:"-"`
+}
+
+// QuestionBank 所有章節考題的題庫，附上依章與 tag 的索引
+type QuestionBank struct {
+	Questions []BankQuestion `json:"questions"`
+	// Chapters 章 -> 題目 id；無法判斷章節的題目不列入
+	Chapters map[int][]string `json:"chapters"`
+	// Tags tag -> 題目 id
+	Tags map[string][]string `json:"tags"`
+}
+
+// isQuestionNotebook 題庫收錄的 notebook：chN_interview_questions.ipynb 與 考題/ 中的 notebook
+func isQuestionNotebook(path string) bool {
+	if filepath.Ext(path) != ".ipynb" {
+		return false
+	}
+	return strings.HasSuffix(path, "_interview_questions.ipynb") || filepath.Base(filepath.Dir(path)) == "考題"
+}
+
+// collectQuestionNotebooks 找出輸入的檔案與目錄中的考題 notebook，依章節排序
+func collectQuestionNotebooks(inputs []string) ([]string, error) {
+	return collectFiles(inputs, func(_ Config, files []string) []string {
+		var paths []string
+		for _, path := range files {
+			if isQuestionNotebook(path) {
+				paths = append(paths, path)
+			}
+		}
+		return paths
+	})
+}
+
+// ExtractQuestions 取出 notebook 中的題目。由標記格式產生的考題讀取 cell metadata 與旁邊的解答 notebook（key 可為 nil）；
+// 手寫的考題依標題判斷：「## 題目 N：標題」、code cell 中的「// 練習N: 標題」，以及 go_exam 只有題號的「## N」加上後面的程式
+func ExtractQuestions(path string, nb, key *Notebook, table map[string]int) []BankQuestion {
+	if questions := extractTaggedQuestions(path, nb, key); len(questions) > 0 {
+		return questions
+	}
+	return extractHandwrittenQuestions(path, nb, table)
+}
+
+// extractTaggedQuestions 由 cell metadata 的 question 取出題目
+func extractTaggedQuestions(path string, nb, key *Notebook) []BankQuestion {
+	var questions []BankQuestion
+	index := map[string]int{}
+	question := func(meta QuestionMeta) *BankQuestion {
+		if i, ok := index[meta.ID]; ok {
+			return &questions[i]
+		}
+		index[meta.ID] = len(questions)
+		questions = append(questions, BankQuestion{
+			ID: meta.ID, Kind: meta.Kind, Chapter: meta.Chapter, Title: meta.Title,
+			Difficulty: meta.Difficulty, Tags: meta.Tags, Source: filepath.ToSlash(path),
+		})
+		return &questions[len(questions)-1]
+	}
+
+	for _, cell := range nb.Cells {
+		meta, ok := questionMetaOf(cell)
+		if !ok {
+			continue
+		}
+		q := question(meta)
+		switch meta.Role {
+		case rolePrompt:
+			_, body, _ := strings.Cut(cell.Text(), "\n")
+			q.Prompt = strings.TrimSpace(body)
+		case roleAnswerSlot:
+			q.Tests = meta.Tests
+		case roleExercise:
+			q.Tests = meta.Tests
+			q.Prompt = exercisePrompt(cell.Text(), meta)
+		}
+	}
+
+	if key != nil {
+		answers := map[string][]string{}
+		for _, cell := range key.Cells {
+			if meta, ok := questionMetaOf(cell); ok && meta.Role == roleAnswer {
+				text := cell.Text()
+				if cell.CellType == "code" {
+					text = "```go\n" + text + "\n```"
+				}
+				answers[meta.ID] = append(answers[meta.ID], text)
+			}
+		}
+		for i := range questions {
+			questions[i].Answer = strings.Join(answers[questions[i].ID], "\n\n")
+		}
+	}
+	return questions
+}
+
+// exercisePrompt 練習 cell 中的題目：去掉「// 練習 N：標題」；已經作答時只保留開頭的註解
+func exercisePrompt(text string, meta QuestionMeta) string {
+	lines := strings.Split(text, "\n")
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "// "+meta.Heading() {
+		lines = lines[1:]
+	}
+	if contentHash(text) != meta.Template {
+		lines = leadingComments(lines)
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// leadingComments 開頭連續的 // 註解
+func leadingComments(lines []string) []string {
+	for i, line := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(line), "//") {
+			return lines[:i]
+		}
+	}
+	return lines
+}
+
+// extractHandwrittenQuestions 依標題取出手寫考題；題目 id 以章節（或檔名）與題號產生，例如 ch5-q3、go_exam-q7
+func extractHandwrittenQuestions(path string, nb *Notebook, table map[string]int) []BankQuestion {
+	chapter := chapterOfPath(path)
+	prefix := questionPrefix(path)
+	source := filepath.ToSlash(path)
+
+	var questions []BankQuestion
+	seen := map[string]bool{}
+	add := func(q BankQuestion) {
+		if !seen[q.ID] {
+			seen[q.ID] = true
+			questions = append(questions, q)
+		}
+	}
+
+	for i, cell := range nb.Cells {
+		text := strings.TrimSpace(cell.Text())
+		first, _, _ := strings.Cut(text, "\n")
+		switch {
+		case cell.CellType == "markdown" && questionHeadingRegex.MatchString(text):
+			// 標題前面可能還有「## 第一部分」之類的段落標題
+			lines := strings.Split(text, "\n")
+			for j, line := range lines {
+				if match := questionHeadingRegex.FindStringSubmatch(line); match != nil {
+					body := strings.TrimSpace(strings.Join(lines[j+1:], "\n"))
+					add(BankQuestion{
+						ID: prefix + "-q" + match[1], Kind: kindQuestion, Chapter: chapter, Title: match[2],
+						Prompt: strings.TrimSpace(strings.TrimSuffix(body, "---")), Source: source, Cell: answerCellAfter(nb, i),
+					})
+					break
+				}
+			}
+		case cell.CellType == "markdown" && examHeadingRegex.MatchString(first):
+			// 題目是下一個標題之前的程式
+			var code []string
+			last := -1
+			for j := i + 1; j < len(nb.Cells); j++ {
+				next := nb.Cells[j]
+				if next.CellType == "markdown" && strings.HasPrefix(strings.TrimSpace(next.Text()), "#") {
+					break
+				}
+				if next.CellType == "code" && strings.TrimSpace(next.Text()) != "" {
+					code = append(code, strings.TrimSpace(next.Text()))
+					last = j
+				}
+			}
+			if len(code) == 0 {
+				continue
+			}
+			number := examHeadingRegex.FindStringSubmatch(first)[1]
+			program := strings.Join(code, "\n\n")
+			add(BankQuestion{
+				ID: prefix + "-q" + number, Kind: kindQuestion, Chapter: chapter,
+				Prompt: "這段程式會印出什麼？有什麼問題？\n\n```go\n" + program + "\n```", Source: source, Cell: last,
+			})
+		case cell.CellType == "code":
+			lines := strings.Split(cell.Text(), "\n")
+			for j, line := range lines {
+				match := exerciseCommentRegex.FindStringSubmatch(line)
+				if match == nil {
+					continue
+				}
+				add(BankQuestion{
+					ID: prefix + "-ex" + match[1], Kind: kindExercise, Chapter: chapter, Title: match[2],
+					Prompt: strings.Join(leadingComments(lines[j+1:]), "\n"), Source: source, Cell: i,
+				})
+			}
+		}
+	}
+
+	// tags 取自題目中的程式與作答 cell；路徑看不出章節時也用來推測章節
+	for i := range questions {
+		q := &questions[i]
+		var programs []string
+		for _, cell := range splitAnswer(q.Prompt) {
+			if cell.CellType == "code" {
+				programs = append(programs, cell.Text())
+			}
+		}
+		if q.Cell >= 0 && nb.Cells[q.Cell].CellType == "code" {
+			programs = append(programs, nb.Cells[q.Cell].Text())
+		}
+		inferred, tags := inferChapter(programs, table)
+		q.Tags = tags
+		if q.Chapter == 0 {
+			q.Chapter = inferred
+		}
+	}
+	return questions
+}
+
+// questionPrefix 手寫考題 id 的開頭：章節（例如 ch5），看不出章節時為檔名（例如 go_exam）
+func questionPrefix(path string) string {
+	if chapter := chapterOfPath(path); chapter > 0 {
+		return fmt.Sprintf("ch%d", chapter)
+	}
+	return strings.TrimSuffix(filepath.Base(path), ".ipynb")
+}
+
+// reviewAnswers 從改卷講解取出每題的解答，格式與 ANSWER 區塊相同；同一題講解多次時取最後一次。
+// review 產生的講解讀取 review-N-<id>-solution cells，手寫的講解讀取「題目 N」段落中「正確答案：」之後的內容
+func reviewAnswers(review *Notebook, prefix string) map[string]string {
+	answers := map[string]string{}
+	current := ""
+	var solution []string
+	save := func() {
+		text := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(strings.Join(solution, "\n")), "---"))
+		if current != "" && text != "" && !strings.HasPrefix(text, "TODO") {
+			answers[current] = text
+		}
+		current, solution = "", nil
+	}
+
+	generated := map[string][]string{}
+	for _, cell := range review.Cells {
+		if match := reviewCellIDRegex.FindStringSubmatch(cell.ID); match != nil {
+			save()
+			if match[2] == "" {
+				delete(generated, match[1])
+			} else if cell.CellType == "code" {
+				generated[match[1]] = append(generated[match[1]], "```go\n"+cell.Text()+"\n```")
+			} else {
+				generated[match[1]] = append(generated[match[1]], cell.Text())
+			}
+			continue
+		}
+
+		if cell.CellType == "code" {
+			if solution != nil {
+				solution = append(solution, "```go\n"+cell.Text()+"\n```", "")
+			}
+			continue
+		}
+		for _, line := range strings.Split(cell.Text(), "\n") {
+			trimmed := strings.TrimSpace(line)
+			switch {
+			case reviewHeadingRegex.MatchString(trimmed):
+				save()
+				current = prefix + "-q" + reviewHeadingRegex.FindStringSubmatch(trimmed)[1]
+			case solution == nil && current != "" && reviewSolutionRegex.MatchString(trimmed):
+				solution = []string{}
+			case markdownHeadingRegex.MatchString(trimmed):
+				save()
+			case solution != nil:
+				solution = append(solution, line)
+			}
+		}
+		if solution != nil {
+			solution = append(solution, "")
+		}
+	}
+	save()
+
+	for id, cells := range generated {
+		answers[id] = strings.Join(cells, "\n\n")
+	}
+	return answers
+}
+
+// answerCellAfter 題目標題之後、下一題之前的第一個 code cell；沒有時回傳 -1
+func answerCellAfter(nb *Notebook, heading int) int {
+	for j := heading + 1; j < len(nb.Cells); j++ {
+		cell := nb.Cells[j]
+		if cell.CellType == "code" {
+			return j
+		}
+		if questionHeadingRegex.MatchString(cell.Text()) {
+			break
+		}
+	}
+	return -1
+}
+
+// inferChapter 以程式用到的語法與套件推測題目屬於哪一章：先備知識對照表中最後介紹的章；用到的語法作為 tags
+func inferChapter(programs []string, table map[string]int) (int, []string) {
+	chapter := 0
+	var tags []string
+	var uses []prereqUse
+	for _, program := range programs {
+		uses = append(uses, goConstructs(program)...)
+	}
+	for _, use := range uses {
+		introduced := table[use.Name]
+		if introduced <= 0 {
+			continue
+		}
+		if introduced > chapter {
+			chapter = introduced
+		}
+		if !contains(tags, use.Name) {
+			tags = append(tags, use.Name)
+		}
+	}
+	sort.Strings(tags)
+	return chapter, tags
+}
+
+// BuildBank 讀取考題 notebook 與旁邊的解答，建立題庫；不同檔案中有相同的題目 id 時回傳錯誤
+func BuildBank(paths []string, table map[string]int) (*QuestionBank, error) {
+	bank := &QuestionBank{Chapters: map[int][]string{}, Tags: map[string][]string{}}
+	sources := map[string]string{}
+	for _, path := range paths {
+		nb, err := ReadNotebook(path)
+		if err != nil {
+			return nil, err
+		}
+		key, err := ReadNotebook(answerKeyPath(path))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		tests, err := ReadTestFile(testsPath(path))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		// 沒有解答 notebook 時改從改卷講解取出解答
+		var answers map[string]string
+		if key == nil {
+			review, err := ReadNotebook(reviewPath(path))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+			if review != nil {
+				answers = reviewAnswers(review, questionPrefix(path))
+			}
+		}
+
+		for _, q := range ExtractQuestions(path, nb, key, table) {
+			if q.Tests == "" {
+				q.Tests = tests[q.ID]
+			}
+			if q.Answer == "" {
+				q.Answer = answers[q.ID]
+			}
+			if previous, ok := sources[q.ID]; ok {
+				return nil, fmt.Errorf("duplicate question id %s in %s and %s", q.ID, previous, path)
+			}
+			sources[q.ID] = path
+			bank.Questions = append(bank.Questions, q)
+			if q.Chapter > 0 {
+				bank.Chapters[q.Chapter] = append(bank.Chapters[q.Chapter], q.ID)
+			}
+			for _, tag := range q.Tags {
+				bank.Tags[tag] = append(bank.Tags[tag], q.ID)
+			}
+		}
+	}
+	return bank, nil
+}
+
+// ReadQuestionBank 讀取 bank build 寫入的題庫
+func ReadQuestionBank(path string) (*QuestionBank, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var bank QuestionBank
+	if err := json.Unmarshal(data, &bank); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &bank, nil
+}
+
+// examFilter 組卷的條件；From、To 為 0 表示不限章節，Count 為 0 表示全部
+type examFilter struct {
+	From, To int
+	Tags     []string
+	Count    int
+}
+
+func (f examFilter) match(q BankQuestion) bool {
+	if (f.From > 0 || f.To > 0) && q.Chapter == 0 {
+		return false
+	}
+	if (f.From > 0 && q.Chapter < f.From) || (f.To > 0 && q.Chapter > f.To) {
+		return false
+	}
+	if len(f.Tags) == 0 {
+		return true
+	}
+	for _, tag := range f.Tags {
+		if contains(q.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseChapterRange 解析章節範圍，例如 5、3-7、8-（第八章之後）
+func parseChapterRange(value string) (from, to int, err error) {
+	if value == "" {
+		return 0, 0, nil
+	}
+	first, last, isRange := strings.Cut(value, "-")
+	if from, err = strconv.Atoi(strings.TrimSpace(first)); err != nil || from <= 0 {
+		return 0, 0, fmt.Errorf("invalid chapter range %q", value)
+	}
+	if !isRange {
+		return from, from, nil
+	}
+	if strings.TrimSpace(last) == "" {
+		return from, 0, nil
+	}
+	if to, err = strconv.Atoi(strings.TrimSpace(last)); err != nil || to < from {
+		return 0, 0, fmt.Errorf("invalid chapter range %q", value)
+	}
+	return from, to, nil
+}
+
+// recentlyCorrect 由作答紀錄（grade 寫入的 .grades.json）找出 since 之後最近一次作答通過的題目
+// 只讀取 grade 的結果；改卷講解中人工給的分數沒有日期，不算作答紀錄
+func recentlyCorrect(root string, since time.Time) (map[string]bool, error) {
+	cfg, err := LoadConfig(root)
+	if err != nil {
+		return nil, err
+	}
+	type attempt struct {
+		at     time.Time
+		passed bool
+	}
+	latest := map[string]attempt{}
+	err = cfg.WalkFiles(root, func(path string) error {
+		if !strings.HasSuffix(path, ".grades.json") {
+			return nil
+		}
+		report, err := ReadGradeReport(path)
+		if err != nil {
+			return err
+		}
+		for _, result := range report.Results {
+			if result.Status == gradeUnanswered {
+				continue
+			}
+			if previous, ok := latest[result.ID]; !ok || report.GradedAt.After(previous.at) {
+				latest[result.ID] = attempt{at: report.GradedAt, passed: result.Passed()}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	correct := map[string]bool{}
+	for id, a := range latest {
+		if a.passed && !a.at.Before(since) {
+			correct[id] = true
+		}
+	}
+	return correct, nil
+}
+
+// SelectQuestions 以 seed 決定的順序從符合條件的題目中抽題，優先抽沒有在 avoid 中的題目；
+// 回傳的題目依題庫順序排列，reused 為題數不夠時從 avoid 中補上的題數
+func SelectQuestions(bank *QuestionBank, filter examFilter, avoid map[string]bool, seed int64) (selected []BankQuestion, reused int) {
+	var fresh, recent []int
+	for i, q := range bank.Questions {
+		switch {
+		case !filter.match(q):
+		case avoid[q.ID]:
+			recent = append(recent, i)
+		default:
+			fresh = append(fresh, i)
+		}
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	rng.Shuffle(len(fresh), func(i, j int) { fresh[i], fresh[j] = fresh[j], fresh[i] })
+	rng.Shuffle(len(recent), func(i, j int) { recent[i], recent[j] = recent[j], recent[i] })
+
+	picked := append(fresh, recent...)
+	if filter.Count > 0 && filter.Count < len(picked) {
+		picked = picked[:filter.Count]
+	}
+	if len(picked) > len(fresh) {
+		reused = len(picked) - len(fresh)
+	}
+	sort.Ints(picked)
+	for _, i := range picked {
+		selected = append(selected, bank.Questions[i])
+	}
+	return selected, reused
+}
+
+// ExamNotebook 將抽出的題目排成考題與解答 notebook，版面與 EXERCISE、QUESTION 標記產生的考題相同：
+// 練習在前、題目在後，各自重新編號；題目 id 不變，grade 的結果可以對回題庫。沒有任何解答時 key 為 nil
+func ExamNotebook(questions []BankQuestion, title, description string) (exam, key *Notebook) {
+	exam = NewNotebook()
+	exam.AddMarkdownCell("exam", "# "+title+"\n\n"+description)
+
+	var answers []Cell
+	numbers := map[string]int{}
+	for _, kind := range []string{kindExercise, kindQuestion} {
+		for _, q := range questions {
+			if q.Kind != kind {
+				continue
+			}
+			numbers[kind]++
+			if numbers[kind] == 1 {
+				part := "題目"
+				if kind == kindExercise {
+					part = "練習"
+				}
+				exam.AddMarkdownCell(partCellID(part), "## "+part)
+			}
+			meta := QuestionMeta{
+				ID: q.ID, Kind: q.Kind, Chapter: q.Chapter, Number: numbers[kind], Title: q.Title,
+				Difficulty: q.Difficulty, Tags: q.Tags, Tests: q.Tests,
+			}
+			exam.Cells = append(exam.Cells, interviewCells(meta, q.Prompt)...)
+			if q.Answer != "" {
+				answers = append(answers, answerKeyCells(meta, q.Prompt, q.Answer)...)
+			}
+		}
+	}
+	if len(answers) > 0 {
+		key = newAnswerKey(title+" - 解答", answers)
+	}
+	return exam, key
+}
+
+// runBank bank 子命令：建立跨章節的題庫（build），或從題庫隨機組成考卷（exam）
+func runBank(args []string) error {
+	if len(args) > 0 && args[0] == "exam" {
+		return runBankExam(args[1:])
+	}
+	if len(args) > 0 && args[0] == "build" {
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet("bank build", flag.ContinueOnError)
+	output := fs.String("o", defaultBankPath, "題庫 JSON 檔")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s bank build [-o %s] [file-or-dir...]\n", os.Args[0], defaultBankPath)
+		fmt.Fprintf(os.Stderr, "       %s bank exam [flags]（見 bank exam -h）\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "收錄 chN_interview_questions.ipynb 與 考題/ 中的題目與解答\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	inputs := fs.Args()
+	if len(inputs) == 0 {
+		inputs = []string{"."}
+	}
+
+	paths, err := collectQuestionNotebooks(inputs)
+	if err != nil {
+		return err
+	}
+	cfg, err := LoadConfig(inputs[0])
+	if err != nil {
+		return err
+	}
+	bank, err := BuildBank(paths, prerequisiteTable(cfg.Prerequisites))
+	if err != nil {
+		return err
+	}
+	if len(bank.Questions) == 0 {
+		return fmt.Errorf("no questions found in %s", strings.Join(inputs, ", "))
+	}
+
+	data, err := json.MarshalIndent(bank, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(*output, append(data, '\n'), false); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+	answered := 0
+	for _, q := range bank.Questions {
+		if q.Answer != "" {
+			answered++
+		}
+	}
+	fmt.Printf("📊 %d 個 notebook，%d 題（%d 題有解答），%d 章，%d 個 tags\n", len(paths), len(bank.Questions), answered, len(bank.Chapters), len(bank.Tags))
+	fmt.Printf("✅ 題庫: %s\n", *output)
+	return nil
+}
+
+// runBankExam bank exam：依章節範圍、tag 與題數抽題，產生考題與解答 notebook
+func runBankExam(args []string) error {
+	fs := flag.NewFlagSet("bank exam", flag.ContinueOnError)
+	bankPath := fs.String("bank", defaultBankPath, "題庫 JSON 檔")
+	chapters := fs.String("chapters", "", "章節範圍，例如 5、3-7、8-")
+	tags := fs.String("tags", "", "只抽有這些 tag 的題目（以逗號分隔，符合任一個即可）")
+	count := fs.Int("n", 10, "題數（0 表示全部）")
+	seed := fs.Int64("seed", 0, "亂數種子，同樣的種子與條件產生同樣的考卷（預設隨機產生並印出）")
+	history := fs.String("history", ".", "作答紀錄的目錄，讀取其中 grade 寫入的 .grades.json")
+	recent := fs.Duration("recent", 30*24*time.Hour, "避開這段時間內答對的題目（0 表示不避開）")
+	output := fs.String("o", "", "考題 notebook（預設為 exam_<seed>_questions.ipynb）")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s bank exam [--bank file] [--chapters 3-5] [--tags a,b] [-n 10] [--seed N] [-o exam_questions.ipynb]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	filter := examFilter{Tags: splitList(*tags), Count: *count}
+	var err error
+	if filter.From, filter.To, err = parseChapterRange(*chapters); err != nil {
+		return err
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()%1000000 + 1
+	}
+	if *output == "" {
+		*output = fmt.Sprintf("exam_%d_questions.ipynb", *seed)
+	}
+
+	bank, err := ReadQuestionBank(*bankPath)
+	if err != nil {
+		return err
+	}
+	avoid := map[string]bool{}
+	if *recent > 0 {
+		if avoid, err = recentlyCorrect(*history, time.Now().Add(-*recent)); err != nil {
+			return err
+		}
+	}
+
+	selected, reused := SelectQuestions(bank, filter, avoid, *seed)
+	if len(selected) == 0 {
+		return fmt.Errorf("no questions match the filter")
+	}
+	if reused > 0 {
+		fmt.Printf("ℹ️  符合條件的題目不夠，補上 %d 題最近答對的題目\n", reused)
+	}
+
+	conditions := []string{fmt.Sprintf("種子 %d", *seed), fmt.Sprintf("%d 題", len(selected))}
+	if *chapters != "" {
+		conditions = append(conditions, "章節 "+*chapters)
+	}
+	if *tags != "" {
+		conditions = append(conditions, "tags "+*tags)
+	}
+	exam, key := ExamNotebook(selected, "模擬考", "組卷條件："+strings.Join(conditions, "，")+"。請在每個程式碼區塊中寫下你的答案。")
+
+	cfg, err := LoadConfig(*output)
+	if err != nil {
+		return err
+	}
+	for _, nb := range []*Notebook{exam, key} {
+		if nb == nil {
+			continue
+		}
+		if err := nb.SetKernel(cfg.Kernel); err != nil {
+			return err
+		}
+	}
+	data, err := exam.ToJSON()
+	if err != nil {
+		return fmt.Errorf("failed to convert to JSON: %w", err)
+	}
+	if err := writeFileAtomic(*output, data, false); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+	fmt.Printf("🎲 種子 %d：%d 題 -> %s\n", *seed, len(selected), *output)
+
+	if key != nil {
+		data, err := key.ToJSON()
+		if err != nil {
+			return fmt.Errorf("failed to convert answer key to JSON: %w", err)
+		}
+		keyPath := answerKeyPath(*output)
+		if err := writeFileAtomic(keyPath, data, false); err != nil {
+			return fmt.Errorf("failed to write answer key: %w", err)
+		}
+		fmt.Printf("🔑 解答: %s\n", keyPath)
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExtractQuestions_Tagged(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "ch5", "ch5_interview_questions_source.md")
+	output := filepath.Join(dir, "ch5", "ch5_interview_questions.ipynb")
+	writeTestFile(t, source, strings.Replace(interviewSource, "<!-- END_EXERCISE -->", "<!-- TEST -->\n"+gradeTests+"\n<!-- END_TEST -->\n<!-- END_EXERCISE -->", 1))
+	if err := convert(source, output); err != nil {
+		t.Fatal(err)
+	}
+
+	bank, err := BuildBank([]string{output}, defaultPrerequisites)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, q := range bank.Questions {
+		got = append(got, fmt.Sprintf("%s %s %d %q", q.ID, q.Kind, q.Chapter, q.Prompt))
+	}
+	assertStrings(t, "questions", got, []string{
+		`ch5-ex1 exercise 5 "// 請寫一個函式 sum(nums ...int) int"`,
+		`closure-counter question 5 "**問題：** 請寫一個回傳計數器的函式。"`,
+		`ch5-q2 question 5 "多個 defer 以什麼順序執行？"`,
+	})
+	if bank.Questions[0].Tests != gradeTests || bank.Questions[0].Answer != "```go\nfunc sum(nums ...int) int { return 0 }\n```" {
+		t.Errorf("Expected tests and answer on the exercise: %+v", bank.Questions[0])
+	}
+	if !strings.HasPrefix(bank.Questions[1].Answer, "每次呼叫都共用同一個變數：\n\n```go\n") {
+		t.Errorf("Unexpected answer %q", bank.Questions[1].Answer)
+	}
+	assertStrings(t, "chapter 5", bank.Chapters[5], []string{"ch5-ex1", "closure-counter", "ch5-q2"})
+	assertStrings(t, "closure", bank.Tags["closure"], []string{"closure-counter"})
+
+	// 已經作答的練習只保留題目註解
+	nb, _ := ReadNotebook(output)
+	nb.Cells[2].Source = splitLines("// 練習 1：可變參數\n// 請寫一個函式 sum(nums ...int) int\nfunc sum(nums ...int) int { return 6 }")
+	questions := ExtractQuestions(output, nb, nil, defaultPrerequisites)
+	if questions[0].Prompt != "// 請寫一個函式 sum(nums ...int) int" || questions[0].Answer != "" {
+		t.Errorf("Unexpected exercise %+v", questions[0])
+	}
+}
+
+func TestExtractQuestions_Handwritten(t *testing.T) {
+	nb := NewNotebook()
+	nb.AddMarkdownCell("", "# 第三章：複合型態 - 面試考題")
+	nb.AddMarkdownCell("", "## 第二部分：切片 - 25分\n\n### 題目 2-1：切片基礎操作 (5分)\n建立一個整數切片。")
+	nb.AddCodeCell("", "// 請在這裡寫下你的答案")
+	nb.AddMarkdownCell("", "## 題目 3: 可變參數函式\n\n**題目**: 寫一個函式 `findMax`。\n\n```go\nfmt.Println(findMax(map[string]int{}))\n```\n\n---")
+	nb.AddCodeCell("", "package main\n\n// 練習1: 定義自定義型態\n// 請定義一個基於 int 的型態 StudentID\ntype StudentID struct{}")
+
+	var got []string
+	for _, q := range ExtractQuestions("ch3/ch3_interview_questions.ipynb", nb, nil, defaultPrerequisites) {
+		got = append(got, fmt.Sprintf("%s %s %d %s %q %v", q.ID, q.Kind, q.Chapter, q.Title, q.Prompt, q.Tags))
+	}
+	// tags 取自題目中的程式與作答 cell，章節仍以路徑為準
+	assertStrings(t, "questions", got, []string{
+		`ch3-q2-1 question 3 切片基礎操作 (5分) "建立一個整數切片。" []`,
+		"ch3-q3 question 3 可變參數函式 \"**題目**: 寫一個函式 `findMax`。\\n\\n```go\\nfmt.Println(findMax(map[string]int{}))\\n```\" [map struct]",
+		`ch3-ex1 exercise 3 定義自定義型態 "// 請定義一個基於 int 的型態 StudentID" [struct]`,
+	})
+
+	// 考題/go_exam.ipynb：只有題號的標題，題目是後面的程式，章節由用到的語法推測
+	exam := NewNotebook()
+	exam.AddMarkdownCell("", "## 1")
+	exam.AddCodeCell("", "package main\n\nfunc main() {\n\tch := make(chan int, 1)\n\tgo func() { ch <- 1 }()\n\t<-ch\n}")
+	exam.AddMarkdownCell("", "## 2\n\n我的筆記")
+	questions := ExtractQuestions(filepath.Join("考題", "go_exam.ipynb"), exam, nil, defaultPrerequisites)
+	if len(questions) != 1 {
+		t.Fatalf("Expected one question, got %+v", questions)
+	}
+	q := questions[0]
+	if q.ID != "go_exam-q1" || q.Chapter != 10 || !strings.Contains(q.Prompt, "```go\npackage main") {
+		t.Errorf("Unexpected exam question %+v", q)
+	}
+	assertStrings(t, "tags", q.Tags, []string{"channel", "closure", "goroutine"})
+}
+
+func TestReviewAnswers(t *testing.T) {
+	review := NewNotebook()
+	review.AddMarkdownCell("", "# 第三章：複合型態 - 考卷批改與講解\n\n## 總體評分：75/100")
+	review.AddMarkdownCell("", "### 題目 1-1：陣列基礎 (得分：3/3) ✅\n\n**你的答案：**\n```go\nvar a [5]int\n```\n\n---")
+	review.AddMarkdownCell("", "### ❌ 題目 3-3：高效字串建構 (得分：0/6) ❌\n\n**問題：** 未完成此題。\n\n**正確答案：**\n```go\nvar b strings.Builder\n```\n\n---")
+	review.AddMarkdownCell("", "## 📊 總結\n\n多練習 strings.Builder")
+	// review 產生的講解：第二次改卷的解答取代第一次
+	review.AddMarkdownCell("review-1-closure-counter", "## 題目 1：閉包 - 評分：TODO/10\n\n### 正確答案：")
+	review.AddCodeCell("review-1-closure-counter-solution", "func counter() {}")
+	review.AddMarkdownCell("review-2-closure-counter", "## 題目 1：閉包 - 評分：TODO/10\n\n### 正確答案：")
+	review.AddMarkdownCell("review-2-closure-counter-solution", "每次呼叫都共用同一個變數")
+	review.AddCodeCell("review-2-closure-counter-solution-2", "func counter() func() int { return nil }")
+
+	answers := reviewAnswers(review, "ch3")
+	if len(answers) != 2 {
+		t.Errorf("Expected two answers, got %v", answers)
+	}
+	if got := answers["ch3-q3-3"]; got != "```go\nvar b strings.Builder\n```" {
+		t.Errorf("Unexpected handwritten answer %q", got)
+	}
+	if got := answers["closure-counter"]; got != "每次呼叫都共用同一個變數\n\n```go\nfunc counter() func() int { return nil }\n```" {
+		t.Errorf("Unexpected generated answer %q", got)
+	}
+}
+
+func TestParseChapterRange(t *testing.T) {
+	tests := map[string][2]int{"": {0, 0}, "5": {5, 5}, "3-7": {3, 7}, "8-": {8, 0}}
+	for value, want := range tests {
+		from, to, err := parseChapterRange(value)
+		if err != nil || from != want[0] || to != want[1] {
+			t.Errorf("parseChapterRange(%q) = %d, %d, %v", value, from, to, err)
+		}
+	}
+	for _, value := range []string{"x", "0", "7-3"} {
+		if _, _, err := parseChapterRange(value); err == nil {
+			t.Errorf("parseChapterRange(%q) should fail", value)
+		}
+	}
+}
+
+func TestSelectQuestions(t *testing.T) {
+	bank := &QuestionBank{}
+	for i := 1; i <= 20; i++ {
+		q := BankQuestion{ID: fmt.Sprintf("q%d", i), Kind: kindQuestion, Chapter: i%5 + 1}
+		if i%2 == 0 {
+			q.Tags = []string{"even"}
+		}
+		bank.Questions = append(bank.Questions, q)
+	}
+	ids := func(questions []BankQuestion) []string {
+		var ids []string
+		for _, q := range questions {
+			ids = append(ids, q.ID)
+		}
+		return ids
+	}
+
+	filter := examFilter{From: 2, To: 4, Tags: []string{"even"}, Count: 3}
+	first, _ := SelectQuestions(bank, filter, nil, 7)
+	second, _ := SelectQuestions(bank, filter, nil, 7)
+	assertStrings(t, "same seed", ids(second), ids(first))
+	for _, q := range first {
+		if !filter.match(q) {
+			t.Errorf("%s does not match the filter", q.ID)
+		}
+	}
+
+	// 符合條件的題目為 q2 q6 q8 q12 q16 q18；避開其中四題後只剩兩題，第三題從最近答對的題目補上
+	avoid := map[string]bool{"q2": true, "q6": true, "q8": true, "q18": true}
+	selected, reused := SelectQuestions(bank, filter, avoid, 7)
+	got := strings.Join(ids(selected), " ")
+	if len(selected) != 3 || reused != 1 || !strings.Contains(got, "q12") || !strings.Contains(got, "q16") {
+		t.Errorf("Expected q12, q16 and one reused question, got %s (reused %d)", got, reused)
+	}
+	if all, _ := SelectQuestions(bank, examFilter{}, nil, 1); len(all) != 20 {
+		t.Errorf("Count 0 should select every question, got %d", len(all))
+	}
+}
+
+func TestRecentlyCorrect(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	write := func(name string, at time.Time, results ...GradeResult) {
+		data, _ := json.Marshal(GradeReport{GradedAt: at, Results: results})
+		writeTestFile(t, filepath.Join(dir, name), string(data))
+	}
+	write("ch5/ch5_interview_questions.grades.json", now.Add(-48*time.Hour),
+		GradeResult{ID: "a", Status: runPassed}, GradeResult{ID: "b", Status: runPassed}, GradeResult{ID: "c", Status: gradeFailed})
+	write("exam_1_questions.grades.json", now.Add(-time.Hour),
+		GradeResult{ID: "b", Status: gradeFailed}, GradeResult{ID: "c", Status: runPassed}, GradeResult{ID: "d", Status: gradeUnanswered})
+	write("exam_0_questions.grades.json", now.Add(-90*24*time.Hour), GradeResult{ID: "e", Status: runPassed})
+
+	correct, err := recentlyCorrect(dir, now.Add(-30*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// b 最近一次答錯；e 已經超過 30 天
+	for id, want := range map[string]bool{"a": true, "b": false, "c": true, "d": false, "e": false} {
+		if correct[id] != want {
+			t.Errorf("recentlyCorrect[%s] = %v, want %v", id, correct[id], want)
+		}
+	}
+}
+
+func TestRunBankExam(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "ch5", "ch5_interview_questions_source.md")
+	writeTestFile(t, source, strings.Replace(interviewSource, "<!-- END_EXERCISE -->", "<!-- TEST -->\n"+gradeTests+"\n<!-- END_TEST -->\n<!-- END_EXERCISE -->", 1))
+	if err := convert(source, filepath.Join(dir, "ch5", "ch5_interview_questions.ipynb")); err != nil {
+		t.Fatal(err)
+	}
+	bankPath := filepath.Join(dir, "bank.json")
+	if err := run([]string{"bank", "build", "-o", bankPath, dir}); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "exam_questions.ipynb")
+	if err := run([]string{"bank", "exam", "--bank", bankPath, "--history", dir, "--chapters", "5", "-n", "2", "--seed", "3", "-o", output}); err != nil {
+		t.Fatal(err)
+	}
+	exam, err := ReadNotebook(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(exam.Cells[0].Text(), "種子 3，2 題，章節 5") {
+		t.Errorf("Expected the conditions in the title cell, got %q", exam.Cells[0].Text())
+	}
+	numbered := 0
+	for _, cell := range exam.Cells {
+		meta, ok := questionMetaOf(cell)
+		if ok && (meta.Role == roleExercise || meta.Role == roleAnswerSlot) {
+			numbered++
+			if meta.Number != 1 || meta.Chapter != 5 {
+				t.Errorf("Expected renumbered questions from ch5, got %+v", meta)
+			}
+		}
+	}
+	if numbered != 2 {
+		t.Errorf("Expected 2 questions, got %d", numbered)
+	}
+	if _, err := ReadNotebook(answerKeyPath(output)); err != nil {
+		t.Errorf("Expected an answer key: %v", err)
+	}
+
+	if err := run([]string{"bank", "exam", "--bank", bankPath, "--chapters", "9-"}); err == nil {
+		t.Error("Expected an error when no question matches")
+	}
+}
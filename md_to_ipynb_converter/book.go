@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hank/learning-go/ch9/converter/outline"
+)
+
+// chapterPathRegex 由路徑判斷章節，例如 ch10/ch10_concurrency_part1.ipynb
+var chapterPathRegex = regexp.MustCompile(`(?:^|[/\\])ch(\d+)(?:[/\\_]|$)`)
+
+// chapterOfPath 由檔案路徑判斷所屬章節；無法判斷時回傳 0
+func chapterOfPath(path string) int {
+	match := chapterPathRegex.FindStringSubmatch(filepath.ToSlash(path))
+	if match == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(match[1])
+	return n
+}
+
+// sortByChapter 依章節順序排序，同一章依角色（筆記、考題、解答、改卷講解）再依路徑排序；
+// 沒有章節的檔案排在最後
+func sortByChapter(paths []string) {
+	sort.SliceStable(paths, func(i, j int) bool {
+		ci, cj := chapterOfPath(paths[i]), chapterOfPath(paths[j])
+		if ci != cj {
+			if ci == 0 || cj == 0 {
+				return cj == 0
+			}
+			return ci < cj
+		}
+		if ri, rj := notebookRole(paths[i]), notebookRole(paths[j]); ri != rj {
+			return ri < rj
+		}
+		return paths[i] < paths[j]
+	})
+}
+
+// notebookRole 檔案在同一章中的順序：筆記 0、考題 1、解答 2、改卷講解 3
+//
+// 來源檔（*_source.md）與產生的 notebook 使用相同的規則。
+func notebookRole(path string) int {
+	name := filepath.Base(path)
+	stem := strings.TrimSuffix(strings.TrimSuffix(name, filepath.Ext(name)), "_source")
+	switch {
+	case strings.HasSuffix(stem, "_questions"):
+		return 1
+	case strings.HasSuffix(stem, "_answer_key"):
+		return 2
+	case strings.HasSuffix(stem, "_answers_review"):
+		return 3
+	}
+	return 0
+}
+
+// runOutline outline 子命令：以 JSON 輸出全書目錄，供其他工具使用
+func runOutline(args []string) error {
+	fs := flag.NewFlagSet("outline", flag.ContinueOnError)
+	chapter := fs.Int("chapter", 0, "只輸出指定的章")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s outline [--chapter N] [%s]\n", os.Args[0], outline.FileName)
+		fmt.Fprintf(os.Stderr, "省略路徑時從目前目錄往上尋找 %s\n", outline.FileName)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	path := fs.Arg(0)
+	if path == "" {
+		found, err := findFileUpward(".", outline.FileName)
+		if err != nil {
+			return err
+		}
+		if found == "" {
+			return fmt.Errorf("%s not found", outline.FileName)
+		}
+		path = found
+	}
+
+	book, err := outline.ParseFile(path)
+	if err != nil {
+		return err
+	}
+
+	var value any = book
+	if *chapter != 0 {
+		found, ok := book.Chapter(*chapter)
+		if !ok {
+			return fmt.Errorf("chapter %d not found in %s", *chapter, path)
+		}
+		value = found
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
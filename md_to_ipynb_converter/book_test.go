@@ -0,0 +1,50 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChapterOfPath(t *testing.T) {
+	tests := map[string]int{
+		"ch10/ch10_concurrency_part1.ipynb": 10,
+		"/repo/ch2/notes.ipynb":             2,
+		"ch3_composite_type.ipynb":          3,
+		"考題/go_exam.ipynb":                  0,
+		"chapter1/x.ipynb":                  0,
+	}
+	for path, want := range tests {
+		if got := chapterOfPath(path); got != want {
+			t.Errorf("chapterOfPath(%q) = %d, want %d", path, got, want)
+		}
+	}
+}
+
+func TestSortByChapter(t *testing.T) {
+	paths := []string{"考題/go_exam.ipynb", "ch10/ch10_a.ipynb", "ch2/ch2_b.ipynb", "ch2/ch2_a.ipynb"}
+	sortByChapter(paths)
+	want := []string{"ch2/ch2_a.ipynb", "ch2/ch2_b.ipynb", "ch10/ch10_a.ipynb", "考題/go_exam.ipynb"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("got %v, want %v", paths, want)
+	}
+
+	// 同一章：筆記、考題、解答、改卷講解，而不是依檔名排序
+	paths = []string{
+		"ch5/ch5_interview_answers_review.ipynb",
+		"ch5/ch5_interview_questions.ipynb",
+		"ch5/ch5_interview_answer_key.ipynb",
+		"ch5/ch5_pointers.ipynb",
+		"ch5/ch5_functions.ipynb",
+	}
+	sortByChapter(paths)
+	want = []string{
+		"ch5/ch5_functions.ipynb",
+		"ch5/ch5_pointers.ipynb",
+		"ch5/ch5_interview_questions.ipynb",
+		"ch5/ch5_interview_answer_key.ipynb",
+		"ch5/ch5_interview_answers_review.ipynb",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("got %v, want %v", paths, want)
+	}
+}
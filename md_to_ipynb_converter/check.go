@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// CellDiff 描述 committed notebook 與重新產生的結果之間，單一 cell 的差異
+type CellDiff struct {
+	Kind     byte // '+' 新增、'-' 刪除、'~' 修改、'>' 移動
+	Index    int  // 在重新產生的 notebook 中的位置（刪除時為原 notebook 中的位置）
+	CellType string
+	Old      string
+	New      string
+	// OldQuestion、NewQuestion 題目 metadata 中影響批改的欄位，見 questionSummary
+	OldQuestion string
+	NewQuestion string
+}
+
+// String 輸出一行摘要，例如 `~ cell 4 [code] func main() {`
+func (d CellDiff) String() string {
+	text := d.New
+	if d.Kind == '-' {
+		text = d.Old
+	}
+	summary := fmt.Sprintf("%c cell %d [%s] %s", d.Kind, d.Index, d.CellType, firstLine(text))
+	if d.OldQuestion != d.NewQuestion {
+		summary += "（題目 metadata）"
+	}
+	return summary
+}
+
+// DiffNotebooks 以語意比較兩個 notebook 的 cells
+//
+// 比較 cell 類型與內容，以及題目 metadata 中的 TEST 區塊與作答範本；
+// outputs、execution_count、其他 metadata、ID 以及行尾空白、CRLF、結尾空行等格式差異都會被忽略。
+func DiffNotebooks(committed, generated *Notebook) []CellDiff {
+	oldCells := normalizeCells(committed.Cells)
+	newCells := normalizeCells(generated.Cells)
+	matches := matchCells(oldCells, newCells)
+
+	var diffs []CellDiff
+	var matchedOrder []int
+	matched := map[int]bool{}
+	for i, cell := range newCells {
+		oldIndex := matches[i]
+		if oldIndex < 0 {
+			diffs = append(diffs, CellDiff{Kind: '+', Index: i, CellType: cell.CellType, New: cell.Text()})
+			continue
+		}
+		matched[oldIndex] = true
+		matchedOrder = append(matchedOrder, oldIndex)
+		oldQuestion, newQuestion := questionSummary(oldCells[oldIndex]), questionSummary(cell)
+		if oldCells[oldIndex].Text() != cell.Text() || oldQuestion != newQuestion {
+			diffs = append(diffs, CellDiff{Kind: '~', Index: i, CellType: cell.CellType, Old: oldCells[oldIndex].Text(), New: cell.Text(),
+				OldQuestion: oldQuestion, NewQuestion: newQuestion})
+		}
+	}
+
+	stable := longestIncreasing(matchedOrder)
+	for i, cell := range newCells {
+		if oldIndex := matches[i]; oldIndex >= 0 && !stable[oldIndex] {
+			diffs = append(diffs, CellDiff{Kind: '>', Index: i, CellType: cell.CellType, Old: oldCells[oldIndex].Text(), New: cell.Text()})
+		}
+	}
+
+	for j, cell := range oldCells {
+		if !matched[j] {
+			diffs = append(diffs, CellDiff{Kind: '-', Index: j, CellType: cell.CellType, Old: cell.Text()})
+		}
+	}
+
+	return diffs
+}
+
+// OverwriteLosses 統計寫入 generated 時，既有 notebook 中會被覆蓋的執行輸出與 metadata
+//
+// DiffNotebooks 只看內容；沒有 --update 時重新產生的 cells 不帶輸出，
+// 內容相同的 cell 也會失去 outputs。cell 以 ID 對應，找不到時退回同一位置。
+func OverwriteLosses(existing, generated *Notebook) (outputs, metadata int) {
+	byID := map[string]*Cell{}
+	for i := range generated.Cells {
+		byID[generated.Cells[i].ID] = &generated.Cells[i]
+	}
+
+	for i, cell := range existing.Cells {
+		next, ok := byID[cell.ID]
+		if !ok && i < len(generated.Cells) {
+			next, ok = &generated.Cells[i], true
+		}
+		if len(cell.Outputs) > 0 && (!ok || len(next.Outputs) == 0) {
+			outputs++
+		}
+		if ok && !sameJSON(cell.Metadata, next.Metadata) {
+			metadata++
+		}
+	}
+	return outputs, metadata
+}
+
+// sameJSON 比較兩個值序列化後是否相同；先轉成一般的 map 再比較，
+// struct 的欄位順序與 map 的 key 順序不同也視為相同
+func sameJSON(a, b any) bool {
+	normalize := func(v any) ([]byte, error) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		var generic any
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return nil, err
+		}
+		return json.Marshal(generic)
+	}
+	dataA, errA := normalize(a)
+	dataB, errB := normalize(b)
+	return errA == nil && errB == nil && bytes.Equal(dataA, dataB)
+}
+
+// normalizeCells 去除不影響語意的格式差異，並忽略既有 ID
+func normalizeCells(cells []Cell) []Cell {
+	normalized := make([]Cell, 0, len(cells))
+	for _, cell := range cells {
+		text := normalizeText(cell.Text())
+		if text == "" {
+			continue
+		}
+		normalized = append(normalized, Cell{CellType: cell.CellType, Metadata: cell.Metadata, Source: splitLines(text)})
+	}
+	return normalized
+}
+
+// questionSummary 題目 metadata 中影響批改的欄位：作答範本的雜湊與 TEST 區塊。
+// 只改了 TEST 時 cell 內容不變，仍要讓 --check 發現 notebook 過期
+func questionSummary(cell Cell) string {
+	meta, ok := questionMetaOf(cell)
+	if !ok || (meta.Template == "" && meta.Tests == "") {
+		return ""
+	}
+	return fmt.Sprintf("template: %s\ntests:\n%s", meta.Template, normalizeText(meta.Tests))
+}
+
+// normalizeText 統一換行符號、去除行尾空白與結尾空行
+func normalizeText(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// firstLine 回傳第一個非空白行，用於摘要顯示
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
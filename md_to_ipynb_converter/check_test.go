@@ -0,0 +1,167 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffNotebooks_IgnoresNoise(t *testing.T) {
+	committed := NewNotebook()
+	committed.AddMarkdownCell("abc", "# Title  \r\n\r\n")
+	committed.AddCodeCell("def", "var x = 1\n")
+	committed.Cells[1].Outputs = []any{"1"}
+	committed.Cells[1].Metadata = CellMetadata{"tags": []any{"demo"}}
+
+	generated := NewNotebook()
+	generated.AddMarkdownCell("cell-0", "# Title")
+	generated.AddCodeCell("cell-1", "var x = 1")
+
+	if diffs := DiffNotebooks(committed, generated); len(diffs) != 0 {
+		t.Errorf("Expected no differences, got %v", diffs)
+	}
+}
+
+func TestOverwriteLosses(t *testing.T) {
+	existing := NewNotebook()
+	existing.AddMarkdownCell("cell-0", "# Title")
+	existing.AddCodeCell("cell-1", "var x = 1")
+	existing.Cells[1].Outputs = []any{"1"}
+	existing.AddCodeCell("old", "fmt.Println(x)")
+	existing.Cells[2].Outputs = []any{"1"}
+	existing.Cells[2].Metadata = CellMetadata{"tags": []any{"demo"}}
+
+	generated := NewNotebook()
+	generated.AddMarkdownCell("cell-0", "# Title")
+	generated.AddCodeCell("cell-1", "var x = 1")
+	generated.AddCodeCell("cell-2", "fmt.Println(x)")
+	generated.Cells[2].Metadata = CellMetadata{"tags": []string{"demo"}}
+
+	// DiffNotebooks 認為沒有差異，但兩個 cells 的輸出會被清除
+	if diffs := DiffNotebooks(existing, generated); len(diffs) != 0 {
+		t.Fatalf("Expected no cell differences, got %v", diffs)
+	}
+	if outputs, metadata := OverwriteLosses(existing, generated); outputs != 2 || metadata != 0 {
+		t.Errorf("Expected 2 lost outputs and no metadata change, got %d, %d", outputs, metadata)
+	}
+
+	// 欄位順序不同不算變更
+	existing.Cells[0].Metadata = CellMetadata{"question": map[string]any{"role": "prompt", "number": 1, "kind": "question", "id": "q1"}}
+	generated.Cells[0].Metadata = CellMetadata{"question": QuestionMeta{ID: "q1", Kind: "question", Number: 1, Role: "prompt"}}
+	if _, metadata := OverwriteLosses(existing, generated); metadata != 0 {
+		t.Errorf("Field order should not count as a change, got %d", metadata)
+	}
+
+	generated.Cells[0].Metadata = CellMetadata{"slideshow": map[string]any{"slide_type": "slide"}}
+	if _, metadata := OverwriteLosses(existing, generated); metadata != 1 {
+		t.Errorf("Expected one metadata change, got %d", metadata)
+	}
+}
+
+func TestDiffNotebooks_ReportsCellChanges(t *testing.T) {
+	committed := NewNotebook()
+	committed.AddMarkdownCell("a", "## 第一節 goroutine")
+	committed.AddCodeCell("b", "go worker(ch)\nfmt.Println(\"done\")")
+	committed.AddMarkdownCell("c", "## 已刪除的小節內容")
+
+	generated := NewNotebook()
+	generated.AddMarkdownCell("cell-0", "## 第一節 goroutine")
+	generated.AddCodeCell("cell-1", "go worker(ch)\nfmt.Println(\"finished\")")
+	generated.AddCodeCell("cell-2", "select {}")
+
+	diffs := DiffNotebooks(committed, generated)
+
+	kinds := map[byte]int{}
+	for _, diff := range diffs {
+		kinds[diff.Kind]++
+	}
+	if kinds['~'] != 1 || kinds['+'] != 1 || kinds['-'] != 1 {
+		t.Errorf("Expected one edit, insert and removal, got %v", diffs)
+	}
+	if got := diffs[0].String(); got != "~ cell 1 [code] go worker(ch)" {
+		t.Errorf("Unexpected summary: %s", got)
+	}
+}
+
+func TestIntegration_CheckMode(t *testing.T) {
+	testDir := t.TempDir()
+	inputPath := filepath.Join(testDir, "test_source.md")
+	outputPath := filepath.Join(testDir, "test.ipynb")
+
+	input := "<!-- MARKDOWN_CELL -->\n# Title\n<!-- END_MARKDOWN_CELL -->"
+	if err := os.WriteFile(inputPath, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := convert(inputPath, outputPath); err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	if err := convertWithOptions(inputPath, outputPath, convertOptions{Check: true}); err != nil {
+		t.Errorf("Fresh notebook should pass check: %v", err)
+	}
+
+	if err := os.WriteFile(inputPath, []byte(input+"\n<!-- MARKDOWN_CELL -->\n## New\n<!-- END_MARKDOWN_CELL -->"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.ReadFile(outputPath)
+	if err := convertWithOptions(inputPath, outputPath, convertOptions{Check: true}); err == nil {
+		t.Error("Stale notebook should fail check")
+	}
+	after, _ := os.ReadFile(outputPath)
+	if string(before) != string(after) {
+		t.Error("Check mode must not write the notebook")
+	}
+}
+
+func TestInstallPreCommitHook(t *testing.T) {
+	dir := t.TempDir()
+	if err := exec.Command("git", "init", "-q", dir).Run(); err != nil {
+		t.Skipf("git not available: %v", err)
+	}
+
+	path, err := installPreCommitHook(dir, false)
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Hook was not written: %v", err)
+	}
+	if info.Mode()&0100 == 0 {
+		t.Error("Hook should be executable")
+	}
+
+	if _, err := installPreCommitHook(dir, false); err == nil {
+		t.Error("Expected error when hook already exists")
+	}
+	if _, err := installPreCommitHook(dir, true); err != nil {
+		t.Errorf("--force should overwrite: %v", err)
+	}
+
+	// staged 的來源檔沒有對應的 notebook 時 hook 要失敗；沒有來源檔的手寫 notebook 不檢查
+	hook := func(files ...string) (string, error) {
+		for _, file := range files {
+			writeTestFile(t, filepath.Join(dir, file), "{}")
+		}
+		if out, err := exec.Command("git", append([]string{"-C", dir, "add"}, files...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git add failed: %v\n%s", err, out)
+		}
+		cmd := exec.Command("sh", path)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "MD2IPYNB=true")
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+	if out, err := hook("ch1/ch1_note.ipynb"); err != nil {
+		t.Errorf("A notebook without a source should pass: %v\n%s", err, out)
+	}
+	if out, err := hook("ch2/ch2_note_source.md"); err == nil || !strings.Contains(out, "ch2/ch2_note.ipynb 不在 index 中") {
+		t.Errorf("Expected a missing notebook to fail the hook, got %v\n%s", err, out)
+	}
+	if out, err := hook("ch2/ch2_note.ipynb"); err != nil {
+		t.Errorf("Staging the notebook should pass: %v\n%s", err, out)
+	}
+}
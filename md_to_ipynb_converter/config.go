@@ -0,0 +1,361 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// configFileName 設定檔名稱；從輸入檔所在目錄一路往上尋找
+const configFileName = ".md2ipynb.json"
+
+// Config 轉換器的設定
+//
+// 優先順序（後者覆蓋前者）：內建預設值 < .md2ipynb.json < 命令列參數 < 檔案開頭的 front matter
+type Config struct {
+	// Kernel kernel 設定檔名稱，見 kernelProfiles
+	Kernel string `json:"kernel"`
+	// IDStrategy cell ID 的產生方式：sequential（cell-0, cell-1, ...）或 hash（依內容產生）
+	IDStrategy string `json:"id_strategy"`
+	// Dialect Markdown 的 cell 語法：markers（<!-- CODE_CELL --> 標記）或 dialects 中的方言
+	Dialect string `json:"dialect"`
+	// Strict 嚴格模式：標記外有內容、缺少結束標記或 code fence 沒有關閉時回報錯誤
+	Strict bool `json:"strict"`
+	// CellDefaults 依 cell 類型（markdown / code）套用的預設標記屬性
+	CellDefaults map[string]map[string]string `json:"cell_defaults"`
+	// SourcePattern 與 OutputPattern 定義來源檔與輸出檔的命名對應，例如 *_source.md -> *.ipynb
+	SourcePattern string `json:"source_pattern"`
+	OutputPattern string `json:"output_pattern"`
+	// Exclude 批次轉換時略過的目錄（目錄名稱或相對路徑，可使用萬用字元）
+	Exclude []string `json:"exclude"`
+	// ExecTimeout 執行 code cell 時每個 cell 的時間上限
+	ExecTimeout Duration `json:"exec_timeout"`
+	// TOC 在標題之後插入（或更新）連到各標題的目錄 cell
+	TOC bool `json:"toc"`
+	// TOCDepth 目錄列出的最深標題層級
+	TOCDepth int `json:"toc_depth"`
+	// Prerequisites 覆蓋 prereq 的內建對照表：語法或套件 -> 介紹它的章，0 表示不檢查
+	Prerequisites map[string]int `json:"prerequisites"`
+
+	// Path 載入的設定檔路徑；沒有找到設定檔時為空字串
+	Path string `json:"-"`
+}
+
+// Duration 在 JSON 中以 "30s" 這類字串表示的時間長度
+type Duration time.Duration
+
+// UnmarshalJSON 解析 "30s"、"1m" 等格式
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("duration must be a string like \"30s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(text)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON 輸出成 "30s" 格式
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// DefaultConfig 回傳內建的預設設定
+func DefaultConfig() Config {
+	return Config{
+		Kernel:        "gophernotes",
+		IDStrategy:    "sequential",
+		Dialect:       "markers",
+		SourcePattern: "*_source.md",
+		OutputPattern: "*.ipynb",
+		Exclude:       []string{".git", ".ipynb_checkpoints"},
+		ExecTimeout:   Duration(30 * time.Second),
+		TOCDepth:      defaultTOCDepth,
+	}
+}
+
+// LoadConfig 從 inputPath 所在目錄往上尋找設定檔，找不到時回傳預設設定
+func LoadConfig(inputPath string) (Config, error) {
+	path, err := findFileUpward(inputPath, configFileName)
+	if err != nil || path == "" {
+		return DefaultConfig(), err
+	}
+	return LoadConfigFile(path)
+}
+
+// LoadConfigFile 讀取指定的設定檔，未設定的欄位使用預設值
+func LoadConfigFile(path string) (Config, error) {
+	cfg := DefaultConfig()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read config: %w", err)
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	cfg.Path = path
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// findFileUpward 從 inputPath 所在目錄往上尋找名為 name 的檔案，找不到時回傳空字串
+func findFileUpward(inputPath, name string) (string, error) {
+	abs, err := filepath.Abs(inputPath)
+	if err != nil {
+		return "", err
+	}
+
+	dir := abs
+	if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+		dir = filepath.Dir(abs)
+	}
+
+	for {
+		candidate := filepath.Join(dir, name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// Validate 檢查設定值是否合法
+func (c Config) Validate() error {
+	if _, ok := kernelProfiles[c.Kernel]; !ok {
+		return fmt.Errorf("unknown kernel %q", c.Kernel)
+	}
+	if c.IDStrategy != "sequential" && c.IDStrategy != "hash" {
+		return fmt.Errorf("unknown id_strategy %q (want sequential or hash)", c.IDStrategy)
+	}
+	if _, ok := dialects[c.Dialect]; !ok && c.Dialect != "markers" {
+		return fmt.Errorf("unknown dialect %q", c.Dialect)
+	}
+	if strings.Count(c.SourcePattern, "*") != 1 || strings.Count(c.OutputPattern, "*") != 1 {
+		return fmt.Errorf("source_pattern and output_pattern must contain exactly one *")
+	}
+	if c.ExecTimeout < 0 {
+		return fmt.Errorf("exec_timeout must not be negative")
+	}
+	if c.TOCDepth < 1 || c.TOCDepth > 6 {
+		return fmt.Errorf("toc_depth must be between 1 and 6")
+	}
+	for name, chapter := range c.Prerequisites {
+		if chapter < 0 {
+			return fmt.Errorf("prerequisites: chapter for %q must not be negative", name)
+		}
+	}
+	return nil
+}
+
+// Set 以字串設定單一欄位，用於命令列參數與 front matter
+func (c *Config) Set(key, value string) error {
+	switch key {
+	case "kernel":
+		c.Kernel = value
+	case "id_strategy":
+		c.IDStrategy = value
+	case "dialect":
+		c.Dialect = value
+	case "strict":
+		strict, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("strict: %w", err)
+		}
+		c.Strict = strict
+	case "source_pattern":
+		c.SourcePattern = value
+	case "output_pattern":
+		c.OutputPattern = value
+	case "exclude":
+		c.Exclude = splitList(value)
+	case "exec_timeout":
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("exec_timeout: %w", err)
+		}
+		c.ExecTimeout = Duration(timeout)
+	case "toc":
+		toc, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("toc: %w", err)
+		}
+		c.TOC = toc
+	case "toc_depth":
+		depth, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("toc_depth: %w", err)
+		}
+		c.TOCDepth = depth
+	default:
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	return c.Validate()
+}
+
+// isSetting 判斷 key 是否為 Set 可以設定的欄位
+func isSetting(key string) bool {
+	switch key {
+	case "kernel", "id_strategy", "dialect", "strict", "source_pattern", "output_pattern", "exclude", "exec_timeout", "toc", "toc_depth":
+		return true
+	}
+	return false
+}
+
+// Apply 依序套用多個設定
+func (c *Config) Apply(settings map[string]string) error {
+	for key, value := range settings {
+		if err := c.Set(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OutputPathFor 依命名規則回傳來源檔對應的輸出路徑；不符合 SourcePattern 時 ok 為 false
+func (c Config) OutputPathFor(sourcePath string) (string, bool) {
+	dir, name := filepath.Split(sourcePath)
+	prefix, suffix, _ := strings.Cut(c.SourcePattern, "*")
+	if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) || len(name) < len(prefix)+len(suffix) {
+		return "", false
+	}
+	stem := name[len(prefix) : len(name)-len(suffix)]
+	return filepath.Join(dir, strings.Replace(c.OutputPattern, "*", stem, 1)), true
+}
+
+// SourcePathFor 與 OutputPathFor 相反：回傳輸出檔對應的來源檔路徑；不符合 OutputPattern 時 ok 為 false
+func (c Config) SourcePathFor(outputPath string) (string, bool) {
+	dir, name := filepath.Split(outputPath)
+	prefix, suffix, _ := strings.Cut(c.OutputPattern, "*")
+	if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) || len(name) < len(prefix)+len(suffix) {
+		return "", false
+	}
+	stem := name[len(prefix) : len(name)-len(suffix)]
+	return filepath.Join(dir, strings.Replace(c.SourcePattern, "*", stem, 1)), true
+}
+
+// IsExcluded 判斷目錄（相對於掃描起點的路徑）是否要略過
+func (c Config) IsExcluded(relDir string) bool {
+	relDir = filepath.ToSlash(relDir)
+	base := filepath.Base(relDir)
+	for _, pattern := range c.Exclude {
+		if ok, _ := filepath.Match(pattern, base); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, relDir); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// WalkFiles 遞迴走訪 root 底下的檔案，略過 exclude 中的目錄
+func (c Config) WalkFiles(root string, fn func(path string) error) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			rel, _ := filepath.Rel(root, path)
+			if rel != "." && c.IsExcluded(rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		return fn(path)
+	})
+}
+
+// collectFiles 展開輸入路徑：檔案直接收錄；目錄以該目錄的設定遞迴走訪，
+// 再由 pick 從找到的檔案中挑選。結果依章節排序
+func collectFiles(inputs []string, pick func(cfg Config, files []string) []string) ([]string, error) {
+	var paths []string
+	for _, input := range inputs {
+		info, err := os.Stat(input)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			paths = append(paths, input)
+			continue
+		}
+
+		cfg, err := LoadConfig(input)
+		if err != nil {
+			return nil, err
+		}
+		var files []string
+		err = cfg.WalkFiles(input, func(path string) error {
+			files = append(files, path)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, pick(cfg, files)...)
+	}
+
+	sortByChapter(paths)
+	return paths, nil
+}
+
+// splitList 分割以逗號分隔的清單並去除空白
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseFrontMatter 解析檔案開頭以 --- 包住的 `key: value` 設定
+// 回傳設定與 front matter 佔用的行數；沒有 front matter 時行數為 0
+//
+// MyST、Quarto 的 front matter 常有巢狀設定，縮排的行、清單項目與沒有值的 key 會被略過。
+func parseFrontMatter(lines []string) (map[string]string, int, error) {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return nil, 0, nil
+	}
+
+	settings := map[string]string{}
+	for i := 1; i < len(lines); i++ {
+		raw := lines[i]
+		line := strings.TrimSpace(raw)
+		if line == "---" {
+			return settings, i + 1, nil
+		}
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "- ") || line != strings.TrimLeft(raw, " \t") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, 0, fmt.Errorf("line %d: invalid front matter %q", i+1, line)
+		}
+		if value = strings.Trim(strings.TrimSpace(value), `"'`); value != "" {
+			settings[strings.TrimSpace(key)] = value
+		}
+	}
+
+	return nil, 0, fmt.Errorf("front matter is not closed with ---")
+}
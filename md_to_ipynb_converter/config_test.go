@@ -0,0 +1,161 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, configFileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadConfig_WalksUp(t *testing.T) {
+	root := t.TempDir()
+	chapter := filepath.Join(root, "ch10")
+	if err := os.Mkdir(chapter, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, root, `{"kernel": "gonb", "exec_timeout": "5s"}`)
+
+	cfg, err := LoadConfig(filepath.Join(chapter, "ch10_source.md"))
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	if cfg.Kernel != "gonb" {
+		t.Errorf("Expected kernel gonb, got %s", cfg.Kernel)
+	}
+	if time.Duration(cfg.ExecTimeout) != 5*time.Second {
+		t.Errorf("Expected 5s timeout, got %v", time.Duration(cfg.ExecTimeout))
+	}
+	if cfg.IDStrategy != "sequential" {
+		t.Errorf("Unset fields should keep defaults, got %s", cfg.IDStrategy)
+	}
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, `{"kernel": "python3"}`)
+
+	if _, err := LoadConfig(filepath.Join(dir, "x.md")); err == nil {
+		t.Error("Expected error for unknown kernel")
+	}
+}
+
+func TestConfig_OutputPathFor(t *testing.T) {
+	cfg := DefaultConfig()
+
+	got, ok := cfg.OutputPathFor(filepath.Join("ch10", "ch10_concurrency_part1_source.md"))
+	if !ok || got != filepath.Join("ch10", "ch10_concurrency_part1.ipynb") {
+		t.Errorf("OutputPathFor = %q, %v", got, ok)
+	}
+
+	if _, ok := cfg.OutputPathFor("README.md"); ok {
+		t.Error("README.md should not match source pattern")
+	}
+
+	got, ok = cfg.SourcePathFor(filepath.Join("ch5", "ch5_interview_answers_review.ipynb"))
+	if !ok || got != filepath.Join("ch5", "ch5_interview_answers_review_source.md") {
+		t.Errorf("SourcePathFor = %q, %v", got, ok)
+	}
+}
+
+func TestConfig_IsExcluded(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Exclude = []string{"ref", ".ipynb_checkpoints", "ch1/drafts"}
+
+	tests := map[string]bool{
+		"ref":                    true,
+		"ch1/.ipynb_checkpoints": true,
+		"ch1/drafts":             true,
+		"ch1":                    false,
+	}
+	for dir, want := range tests {
+		if got := cfg.IsExcluded(dir); got != want {
+			t.Errorf("IsExcluded(%q) = %v, want %v", dir, got, want)
+		}
+	}
+}
+
+func TestParseFrontMatter(t *testing.T) {
+	lines := []string{"---", "kernel: gonb", "# comment", `id_strategy: "hash"`, "---", "<!-- MARKDOWN_CELL -->"}
+
+	settings, n, err := parseFrontMatter(lines)
+	if err != nil {
+		t.Fatalf("parseFrontMatter failed: %v", err)
+	}
+	if n != 5 {
+		t.Errorf("Expected 5 lines, got %d", n)
+	}
+	if settings["kernel"] != "gonb" || settings["id_strategy"] != "hash" {
+		t.Errorf("Unexpected settings: %v", settings)
+	}
+}
+
+func TestIntegration_ConfigPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, `{"kernel": "gonb", "id_strategy": "hash"}`)
+	inputPath := filepath.Join(dir, "test_source.md")
+	outputPath := filepath.Join(dir, "test.ipynb")
+
+	input := "---\nid_strategy: sequential\n---\n<!-- MARKDOWN_CELL -->\n# Title\n<!-- END_MARKDOWN_CELL -->"
+	if err := os.WriteFile(inputPath, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 命令列覆蓋設定檔的 kernel；front matter 覆蓋設定檔的 id_strategy
+	opts := convertOptions{Settings: map[string]string{"kernel": "gophernotes", "id_strategy": "hash"}}
+	if err := convertWithOptions(inputPath, outputPath, opts); err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	nb, err := ReadNotebook(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nb.Metadata.Kernelspec.Name != "gophernotes" {
+		t.Errorf("CLI should override config kernel, got %s", nb.Metadata.Kernelspec.Name)
+	}
+	if nb.Cells[0].ID != "cell-0" {
+		t.Errorf("Front matter should override id_strategy, got %s", nb.Cells[0].ID)
+	}
+}
+
+func TestIntegration_ConvertDir(t *testing.T) {
+	root := t.TempDir()
+	writeConfig(t, root, `{"exclude": ["drafts"]}`)
+
+	input := "<!-- MARKDOWN_CELL -->\n# Title\n<!-- END_MARKDOWN_CELL -->"
+	for _, path := range []string{"ch1/ch1_note_source.md", "drafts/ch2_source.md", "ch1/notes.md"} {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(input), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := convertDir(root, convertOptions{}); err != nil {
+		t.Fatalf("convertDir failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "ch1", "ch1_note.ipynb")); err != nil {
+		t.Error("ch1_note.ipynb should be generated")
+	}
+	if _, err := os.Stat(filepath.Join(root, "drafts", "ch2.ipynb")); !os.IsNotExist(err) {
+		t.Error("Excluded directory should be skipped")
+	}
+	entries, _ := os.ReadDir(filepath.Join(root, "ch1"))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "notes") && strings.HasSuffix(entry.Name(), ".ipynb") {
+			t.Error("Files not matching source_pattern should be skipped")
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/hank/learning-go/ch9/converter/outline"
+)
+
+// defaultCoverageThreshold 標題相似度至少要到這個值才視為同一節
+const defaultCoverageThreshold = 0.5
+
+// coverageEntry 目錄中的一個節（Level 2）或小節（Level 3）
+type coverageEntry struct {
+	Title string
+	Level int
+	// HasChildren 節底下有小節時，程式碼由各小節的 Section 提供
+	HasChildren bool
+}
+
+// coverageHeading notebook 中的一個 ## 或 ### 標題
+type coverageHeading struct {
+	Level    int
+	Text     string
+	Notebook string
+	// HasCode 標題之後、下一個標題之前有 code cell
+	HasCode bool
+}
+
+// coverageReport 一章的比對結果
+type coverageReport struct {
+	Chapter   outline.Chapter
+	Notebooks []string
+	Entries   []coverageEntry
+	Headings  []coverageHeading
+	// Matches 每個目錄項目對應的標題索引；沒有對應時為 -1
+	Matches []int
+	// OutOfOrder 順序與目錄不同的目錄項目索引
+	OutOfOrder []int
+	// Extra 沒有對應到目錄的標題索引
+	Extra []int
+	// NoCode 缺少 code cell 的目錄項目索引
+	NoCode []int
+}
+
+// Covered 找到對應標題的目錄項目數
+func (r *coverageReport) Covered() int {
+	n := 0
+	for _, match := range r.Matches {
+		if match >= 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// Complete 沒有缺少、順序錯誤或缺少程式碼的項目
+func (r *coverageReport) Complete() bool {
+	return r.Covered() == len(r.Entries) && len(r.OutOfOrder) == 0 && len(r.NoCode) == 0
+}
+
+// chapterEntries 依目錄順序攤平一章的節與小節
+func chapterEntries(chapter outline.Chapter) []coverageEntry {
+	var entries []coverageEntry
+	for _, section := range chapter.Sections {
+		entries = append(entries, coverageEntry{Title: section.Title, Level: 2, HasChildren: len(section.Subsections) > 0})
+		for _, subsection := range section.Subsections {
+			entries = append(entries, coverageEntry{Title: subsection.Title, Level: 3})
+		}
+	}
+	return entries
+}
+
+// coverageHeadings 依序收集 notebook 的 ## 與 ### 標題，並記錄每個標題之後是否有 code cell
+func coverageHeadings(nb *Notebook, name string) []coverageHeading {
+	var headings []coverageHeading
+	for _, cell := range nb.Cells {
+		if cell.CellType == "code" {
+			if len(headings) > 0 && strings.TrimSpace(cell.Text()) != "" {
+				headings[len(headings)-1].HasCode = true
+			}
+			continue
+		}
+		if cell.CellType != "markdown" || cell.ID == tocCellID {
+			continue
+		}
+		renderer := &MarkdownRenderer{}
+		renderer.Render(cell.Text())
+		for _, heading := range renderer.Headings {
+			if heading.Level == 2 || heading.Level == 3 {
+				headings = append(headings, coverageHeading{Level: heading.Level, Text: heading.Text, Notebook: name})
+			}
+		}
+	}
+	return headings
+}
+
+// buildCoverage 以模糊比對將目錄項目對應到標題：相似度由高到低配對，每個標題最多對應一個項目
+func buildCoverage(chapter outline.Chapter, headings []coverageHeading, threshold float64) *coverageReport {
+	report := &coverageReport{
+		Chapter:  chapter,
+		Entries:  chapterEntries(chapter),
+		Headings: headings,
+	}
+
+	type candidate struct {
+		entry, heading int
+		score          float64
+	}
+	var candidates []candidate
+	for i, entry := range report.Entries {
+		for j, heading := range headings {
+			score := titleSimilarity(entry.Title, heading.Text)
+			if entry.Level != heading.Level {
+				score -= 0.05 // 同分時優先配對層級相同的標題
+			}
+			if score >= threshold {
+				candidates = append(candidates, candidate{i, j, score})
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(a, b int) bool { return candidates[a].score > candidates[b].score })
+
+	report.Matches = make([]int, len(report.Entries))
+	for i := range report.Matches {
+		report.Matches[i] = -1
+	}
+	usedHeading := make([]int, len(headings))
+	for j := range usedHeading {
+		usedHeading[j] = -1
+	}
+	for _, c := range candidates {
+		if report.Matches[c.entry] >= 0 || usedHeading[c.heading] >= 0 {
+			continue
+		}
+		report.Matches[c.entry] = c.heading
+		usedHeading[c.heading] = c.entry
+	}
+
+	report.OutOfOrder = outOfOrder(report.Matches)
+
+	for i, entry := range report.Entries {
+		if match := report.Matches[i]; match >= 0 && !entry.HasChildren && !headings[match].HasCode {
+			report.NoCode = append(report.NoCode, i)
+		}
+	}
+
+	// ### 標題若位於目錄沒有列出小節的節、或本身就多出來的 ## 之下，只是內容的細分，不另外列出
+	freeSubsections := false
+	for j, heading := range headings {
+		entry := usedHeading[j]
+		if heading.Level == 2 {
+			freeSubsections = entry < 0 || !report.Entries[entry].HasChildren
+		}
+		if entry >= 0 || (heading.Level == 3 && freeSubsections) {
+			continue
+		}
+		report.Extra = append(report.Extra, j)
+	}
+	return report
+}
+
+// outOfOrder 回傳不在最長遞增子序列中的已配對項目：移動這些項目即可讓順序與目錄一致
+func outOfOrder(matches []int) []int {
+	var matched []int
+	for _, match := range matches {
+		if match >= 0 {
+			matched = append(matched, match)
+		}
+	}
+	// 每個標題最多對應一個項目，matches 中的值不會重複
+	inOrder := longestIncreasing(matched)
+
+	var result []int
+	for i, match := range matches {
+		if match >= 0 && !inOrder[match] {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// titleNumberRegex 標題開頭的編號，例如「1.」、「2.3」、「一、」、「第一節」
+var titleNumberRegex = regexp.MustCompile(`^\s*(?:\d+(?:\.\d+)*\.?|[一二三四五六七八九十]+、|第[一二三四五六七八九十\d]+[節章])\s*`)
+
+// normalizeTitle 去掉編號、標點與空白，全形英數轉半形並轉小寫，讓中文標題可以比對
+func normalizeTitle(title string) []rune {
+	title = titleNumberRegex.ReplaceAllString(title, "")
+	var runes []rune
+	for _, r := range title {
+		if r >= '！' && r <= '～' {
+			r -= '！' - '!'
+		}
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			runes = append(runes, unicode.ToLower(r))
+		}
+	}
+	return runes
+}
+
+// titleSimilarity 回傳 0 到 1 的相似度：正規化後以字元二元組的 Dice 係數（similarity）計算，
+// 一方包含另一方時至少 0.8
+func titleSimilarity(a, b string) float64 {
+	ra, rb := normalizeTitle(a), normalizeTitle(b)
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+	sa, sb := string(ra), string(rb)
+
+	score := similarity(sa, sb)
+	if (strings.Contains(sa, sb) && len(rb) >= 2) || (strings.Contains(sb, sa) && len(ra) >= 2) {
+		score = max(score, 0.8)
+	}
+	return score
+}
+
+// isNoteNotebook 章節筆記；面試考題與改卷講解有自己的結構，不與目錄比對
+func isNoteNotebook(path string) bool {
+	return !strings.Contains(filepath.Base(path), "_interview_")
+}
+
+// runCoverage coverage 子命令：比對各章筆記的標題與目錄.md 的節與小節
+func runCoverage(args []string) error {
+	fs := flag.NewFlagSet("coverage", flag.ContinueOnError)
+	tocPath := fs.String("toc", "", "全書目錄（預設從第一個路徑往上尋找 "+outline.FileName+"）")
+	chapterNumber := fs.Int("chapter", 0, "只檢查指定的章")
+	threshold := fs.Float64("threshold", defaultCoverageThreshold, "標題相似度門檻（0 到 1）")
+	check := fs.Bool("check", false, "有缺少、順序錯誤或缺少程式碼的節時以錯誤結束")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s coverage [--toc 目錄.md] [--chapter N] [--threshold 0.5] [--check] [notebook.ipynb|dir...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "省略路徑時檢查目錄.md 所在的整個專案\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *threshold <= 0 || *threshold > 1 {
+		return fmt.Errorf("invalid threshold %v: must be in (0, 1]", *threshold)
+	}
+
+	inputs := fs.Args()
+	start := "."
+	if len(inputs) > 0 {
+		start = inputs[0]
+	}
+	if *tocPath == "" {
+		found, err := findFileUpward(start, outline.FileName)
+		if err != nil {
+			return err
+		}
+		if found == "" {
+			return fmt.Errorf("%s not found; use --toc", outline.FileName)
+		}
+		*tocPath = found
+	}
+	book, err := outline.ParseFile(*tocPath)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		inputs = []string{filepath.Dir(*tocPath)}
+	}
+
+	paths, err := collectNotebooks(inputs)
+	if err != nil {
+		return err
+	}
+	sortByChapter(paths)
+	byChapter := make(map[int][]string)
+	for _, path := range paths {
+		if number := chapterOfPath(path); number > 0 && isNoteNotebook(path) {
+			byChapter[number] = append(byChapter[number], path)
+		}
+	}
+
+	var incomplete []string
+	checked := 0
+	for _, chapter := range book.Numbered() {
+		if *chapterNumber != 0 && chapter.Number != *chapterNumber {
+			continue
+		}
+		notebooks := byChapter[chapter.Number]
+		if len(notebooks) == 0 {
+			if *chapterNumber != 0 {
+				return fmt.Errorf("no notebooks found for chapter %d", chapter.Number)
+			}
+			continue
+		}
+
+		var headings []coverageHeading
+		for _, path := range notebooks {
+			nb, err := ReadNotebook(path)
+			if err != nil {
+				return err
+			}
+			headings = append(headings, coverageHeadings(nb, filepath.Base(path))...)
+		}
+		report := buildCoverage(chapter, headings, *threshold)
+		report.Notebooks = notebooks
+		printCoverage(report)
+		checked++
+		if !report.Complete() {
+			incomplete = append(incomplete, chapter.Label())
+		}
+	}
+	if checked == 0 {
+		if *chapterNumber != 0 {
+			return fmt.Errorf("chapter %d not found in %s", *chapterNumber, *tocPath)
+		}
+		fmt.Println("ℹ️  沒有找到章節筆記")
+	}
+
+	if *check && len(incomplete) > 0 {
+		return fmt.Errorf("outline coverage incomplete: %s", strings.Join(incomplete, ", "))
+	}
+	return nil
+}
+
+// printCoverage 輸出一章的比對結果
+func printCoverage(r *coverageReport) {
+	total := len(r.Entries)
+	percent := 100
+	if total > 0 {
+		percent = r.Covered() * 100 / total
+	}
+	fmt.Printf("📊 %s：涵蓋 %d/%d 節 (%d%%)\n", r.Chapter.Label(), r.Covered(), total, percent)
+
+	for i, entry := range r.Entries {
+		if r.Matches[i] < 0 {
+			fmt.Printf("  ❌ 缺少%s: %s\n", entryKind(entry), entry.Title)
+		}
+	}
+	for _, i := range r.OutOfOrder {
+		heading := r.Headings[r.Matches[i]]
+		fmt.Printf("  🔄 順序與目錄不同: %s（%s）\n", heading.Text, heading.Notebook)
+	}
+	for _, i := range r.NoCode {
+		heading := r.Headings[r.Matches[i]]
+		fmt.Printf("  📝 沒有 code cell: %s（%s）\n", heading.Text, heading.Notebook)
+	}
+	for _, j := range r.Extra {
+		heading := r.Headings[j]
+		fmt.Printf("  ℹ️  目錄沒有的標題: %s %s（%s）\n", strings.Repeat("#", heading.Level), heading.Text, heading.Notebook)
+	}
+	if r.Complete() && len(r.Extra) == 0 {
+		fmt.Println("  ✅ 與目錄一致")
+	}
+}
+
+func entryKind(entry coverageEntry) string {
+	if entry.Level == 3 {
+		return "小節"
+	}
+	return "節"
+}
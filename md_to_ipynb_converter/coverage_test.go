@@ -0,0 +1,120 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hank/learning-go/ch9/converter/outline"
+)
+
+func TestTitleSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		min  float64
+		max  float64
+	}{
+		{"何時該使用並行", "何時該使用並行", 1, 1},
+		{"讀取、寫入與緩衝", "1. 讀取，寫入與緩衝", 1, 1},
+		{"ＷａｉｔＧｒｏｕｐ", "waitgroup", 1, 1},
+		{"使用 WaitGroup", "WaitGroup", 0.8, 0.9},
+		{"何時該用 mutex 來取代 channel", "何時該用 mutex 取代 channel？", 0.8, 1},
+		{"channel", "select", 0, 0.2},
+		{"關閉 channel", "總結", 0, 0},
+	}
+	for _, tt := range tests {
+		if got := titleSimilarity(tt.a, tt.b); got < tt.min || got > tt.max {
+			t.Errorf("titleSimilarity(%q, %q) = %.2f, want between %.2f and %.2f", tt.a, tt.b, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestBuildCoverage(t *testing.T) {
+	chapter := outline.Chapter{Number: 10, Numeral: "十", Title: "Go 的並行", Sections: []outline.Section{
+		{Title: "goroutine"},
+		{Title: "channel", Subsections: []outline.Subsection{{Title: "讀取、寫入與緩衝"}, {Title: "關閉 channel"}}},
+		{Title: "select"},
+		{Title: "總結"},
+	}}
+	nb := &Notebook{Cells: []Cell{
+		{CellType: "markdown", Source: splitLines("# 第十章 Go 的並行")},
+		{CellType: "markdown", Source: splitLines("## select 敘述")},
+		{CellType: "code", Source: splitLines("select {}")},
+		{CellType: "markdown", Source: splitLines("## Goroutine")},
+		{CellType: "code", Source: splitLines("go f()")},
+		{CellType: "markdown", Source: splitLines("### 細節")},
+		{CellType: "markdown", Source: splitLines("## Channel\n\n### 讀取、寫入與緩衝")},
+		{CellType: "code", Source: splitLines("")},
+		{CellType: "markdown", Source: splitLines("## 額外的節\n\n### 額外節底下的小節")},
+	}}
+
+	report := buildCoverage(chapter, coverageHeadings(nb, "ch10.ipynb"), defaultCoverageThreshold)
+
+	text := func(indexes []int, headings bool) []string {
+		var out []string
+		for _, i := range indexes {
+			if headings {
+				out = append(out, report.Headings[i].Text)
+			} else {
+				out = append(out, report.Headings[report.Matches[i]].Text)
+			}
+		}
+		return out
+	}
+
+	var missing []string
+	for i, entry := range report.Entries {
+		if report.Matches[i] < 0 {
+			missing = append(missing, entry.Title)
+		}
+	}
+	assertStrings(t, "missing", missing, []string{"關閉 channel", "總結"})
+	assertStrings(t, "out of order", text(report.OutOfOrder, false), []string{"select 敘述"})
+	// 讀取、寫入與緩衝後面只有空的 code cell；channel 有小節所以不需要自己的程式碼
+	assertStrings(t, "no code", text(report.NoCode, false), []string{"讀取、寫入與緩衝"})
+	// ### 細節 是 goroutine 的細分、額外節底下的小節跟著 ## 一起列出，都不算多出來的標題
+	assertStrings(t, "extra", text(report.Extra, true), []string{"額外的節"})
+
+	if report.Covered() != 4 || report.Complete() {
+		t.Errorf("Expected 4 covered and incomplete, got %d, %v", report.Covered(), report.Complete())
+	}
+}
+
+func TestOutOfOrder(t *testing.T) {
+	// 第 2 個項目被移到最前面；-1 是沒有配對的項目
+	got := outOfOrder([]int{1, 2, 0, -1, 3})
+	if len(got) != 1 || got[0] != 2 {
+		t.Errorf("outOfOrder = %v, want [2]", got)
+	}
+}
+
+func TestRunCoverage_ScaffoldedChapterIsComplete(t *testing.T) {
+	root := t.TempDir()
+	toc := filepath.Join(root, outline.FileName)
+	writeTestFile(t, toc, "## 第三章 複合型態 ..... 35\n- 陣列 ..... 35\n- slice ..... 37\n  - len ..... 39\n  - append ..... 41\n- map ..... 55\n")
+
+	if err := run([]string{"scaffold", "chapter", "3", "--toc", toc}); err != nil {
+		t.Fatalf("scaffold failed: %v", err)
+	}
+	if err := run([]string{"coverage", "--check", "--toc", toc, root}); err != nil {
+		t.Errorf("Scaffolded chapter should cover the outline: %v", err)
+	}
+
+	writeTestFile(t, toc, "## 第三章 複合型態 ..... 35\n- 陣列 ..... 35\n- 結構 ..... 60\n")
+	if err := run([]string{"coverage", "--check", "--toc", toc, "--chapter", "3", root}); err == nil {
+		t.Error("Expected an error for a missing section")
+	}
+}
+
+func assertStrings(t *testing.T, name string, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s = %q, want %q", name, got, want)
+		return
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("%s = %q, want %q", name, got, want)
+			return
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Dialect 其他 notebook 撰寫工具在 Markdown 中標示 code cell 的語法
+//
+// 與 <!-- CODE_CELL --> 標記不同，這類語法中 code cell 以外的文字都是 markdown cell。
+// 新增方言只要實作這個介面並加入 dialects。
+type Dialect interface {
+	// OpenCodeCell 判斷 line 是否為 code cell 的開頭 fence，是的話回傳對應的結束 fence
+	OpenCodeCell(line string) (closing string, ok bool)
+	// CellOption 解析 code cell 開頭的選項行，回傳對應的標記屬性（見 applyAttributes）
+	CellOption(line string) (key, value string, ok bool)
+	// CellBreak 判斷 line 是否為 markdown cell 之間的分隔線
+	CellBreak(line string) bool
+}
+
+// dialects 支援的方言；設定檔的 dialect 為 markers（預設）時不使用任何方言
+var dialects = map[string]Dialect{
+	"myst":   mystDialect{},
+	"quarto": quartoDialect{},
+}
+
+// mystDialect MyST / Jupyter Book 的 ```{code-cell} go 語法
+//
+//	```{code-cell} go
+//	:tags: [hide-input]
+//	fmt.Println("hello")
+//	```
+type mystDialect struct{}
+
+var (
+	mystOpenRegex   = regexp.MustCompile("^(`{3,}|~{3,})\\{code-cell\\}(?:\\s+(\\w+))?\\s*$")
+	mystOptionRegex = regexp.MustCompile(`^:([\w.-]+):\s*(.*)$`)
+)
+
+func (mystDialect) OpenCodeCell(line string) (string, bool) {
+	match := mystOpenRegex.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil || (match[2] != "" && match[2] != "go") {
+		return "", false
+	}
+	return match[1], true
+}
+
+func (mystDialect) CellOption(line string) (string, string, bool) {
+	match := mystOptionRegex.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return "", "", false
+	}
+	return mapCellOption(match[1], match[2])
+}
+
+func (mystDialect) CellBreak(line string) bool {
+	// +++ 之後可以接 JSON metadata，這裡只用來分隔 cell
+	return strings.HasPrefix(strings.TrimSpace(line), "+++")
+}
+
+// quartoDialect Quarto / R Markdown 的 ```{go} 語法，選項寫在 #| 或 //| 開頭的行
+//
+//	```{go}
+//	#| label: setup
+//	#| echo: false
+//	fmt.Println("hello")
+//	```
+type quartoDialect struct{}
+
+var (
+	quartoOpenRegex   = regexp.MustCompile("^(`{3,})\\{go(?:[\\s,][^}]*)?\\}\\s*$")
+	quartoOptionRegex = regexp.MustCompile(`^(?:#|//)\|\s*([\w.-]+)\s*:\s*(.*)$`)
+)
+
+func (quartoDialect) OpenCodeCell(line string) (string, bool) {
+	match := quartoOpenRegex.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+func (quartoDialect) CellOption(line string) (string, string, bool) {
+	match := quartoOptionRegex.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return "", "", false
+	}
+	return mapCellOption(match[1], match[2])
+}
+
+func (quartoDialect) CellBreak(string) bool {
+	return false
+}
+
+// mapCellOption 將方言的 cell 選項對應到標記屬性
+//
+// id / label 對應到 cell ID；echo: false 與 output: false 對應到 Jupyter Book 的
+// hide-input / remove-output tag；其餘選項原樣寫入 metadata。
+func mapCellOption(key, value string) (string, string, bool) {
+	value = strings.Trim(strings.TrimSpace(value), `"'`)
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		value = strings.Trim(value, "[]")
+	}
+
+	switch key {
+	case "id", "label":
+		return "id", value, true
+	case "echo":
+		if value == "false" {
+			return "tags", "hide-input", true
+		}
+	case "output":
+		if value == "false" {
+			return "tags", "remove-output", true
+		}
+	}
+	return key, value, true
+}
+
+// parseDialect 解析 MyST / Quarto 等方言，從 scanner 目前的位置開始
+func (p *Parser) parseDialect(notebook *Notebook, dialect Dialect) (*Notebook, error) {
+	var lines []string
+	attrs := map[string]string{}
+	closing := ""
+	inCode, inOptions := false, false
+	inFrontMatter := false
+
+	flushMarkdown := func(end int) error {
+		text := strings.Trim(strings.Join(lines, "\n"), "\n")
+		lines = nil
+		err := p.saveCell(notebook, MarkdownCell, text, nil, CellSpan{p.cellStart, end, false})
+		p.cellStart = end + 1
+		return err
+	}
+
+	for ; p.scanner.Scan(); p.lineNo++ {
+		line := p.scanner.Text()
+
+		// front matter：只套用轉換器認得的設定，其他工具的設定會被略過
+		if p.lineNo == 0 && strings.TrimSpace(line) == "---" {
+			inFrontMatter = true
+			lines = append(lines, line)
+			continue
+		}
+		if inFrontMatter {
+			lines = append(lines, line)
+			if strings.TrimSpace(line) == "---" {
+				inFrontMatter = false
+				if err := p.applyFrontMatter(lines); err != nil {
+					return nil, err
+				}
+				lines = nil
+				p.cellStart = p.lineNo + 1
+			}
+			continue
+		}
+
+		if inCode {
+			if strings.TrimSpace(line) == closing {
+				text := strings.Join(lines, "\n")
+				lines = nil
+				inCode = false
+				if err := p.saveCell(notebook, CodeCell, text, attrs, CellSpan{p.cellStart, p.lineNo, true}); err != nil {
+					return nil, err
+				}
+				p.cellStart = p.lineNo + 1
+				continue
+			}
+			if inOptions {
+				if key, value, ok := dialect.CellOption(line); ok {
+					addAttribute(attrs, key, value)
+					continue
+				}
+				inOptions = false
+			}
+			lines = append(lines, line)
+			continue
+		}
+
+		if fence, ok := dialect.OpenCodeCell(line); ok {
+			if err := flushMarkdown(p.lineNo - 1); err != nil {
+				return nil, err
+			}
+			p.cellStart = p.lineNo
+			closing, inCode, inOptions = fence, true, true
+			attrs = map[string]string{}
+			continue
+		}
+
+		if dialect.CellBreak(line) {
+			if err := flushMarkdown(p.lineNo - 1); err != nil {
+				return nil, err
+			}
+			p.cellStart = p.lineNo + 1
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	if inFrontMatter {
+		return nil, fmt.Errorf("front matter is not closed with ---")
+	}
+	if inCode {
+		return nil, fmt.Errorf("line %d: code cell is not closed with %s", p.cellStart+1, closing)
+	}
+	if err := flushMarkdown(p.lineNo - 1); err != nil {
+		return nil, err
+	}
+	if err := p.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanner error: %w", err)
+	}
+	if err := notebook.SetKernel(p.Config.Kernel); err != nil {
+		return nil, err
+	}
+
+	return notebook, nil
+}
+
+// addAttribute 加入屬性；tags 會與既有的 tags 合併
+func addAttribute(attrs map[string]string, key, value string) {
+	if key == "tags" && attrs["tags"] != "" {
+		value = attrs["tags"] + "," + value
+	}
+	attrs[key] = value
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParser_MystDialect(t *testing.T) {
+	input := "---\n" +
+		"dialect: myst\n" +
+		"kernelspec:\n" +
+		"  name: gonb\n" +
+		"---\n" +
+		"# 閉包\n" +
+		"\n" +
+		"說明文字\n" +
+		"\n" +
+		"```{code-cell} go\n" +
+		":id: closure\n" +
+		":tags: [hide-input, thebe-init]\n" +
+		"f := func() int { return 1 }\n" +
+		"```\n" +
+		"\n" +
+		"第二段\n" +
+		"+++\n" +
+		"第三段\n"
+
+	notebook, err := NewParser(strings.NewReader(input)).Parse()
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(notebook.Cells) != 4 {
+		t.Fatalf("Expected 4 cells, got %d", len(notebook.Cells))
+	}
+
+	expected := []struct{ cellType, text string }{
+		{"markdown", "# 閉包\n\n說明文字"},
+		{"code", "f := func() int { return 1 }"},
+		{"markdown", "第二段"},
+		{"markdown", "第三段"},
+	}
+	for i, want := range expected {
+		cell := notebook.Cells[i]
+		if cell.CellType != want.cellType || cell.Text() != want.text {
+			t.Errorf("Cell %d: expected %s %q, got %s %q", i, want.cellType, want.text, cell.CellType, cell.Text())
+		}
+	}
+
+	code := notebook.Cells[1]
+	if code.ID != "closure" {
+		t.Errorf("Expected ID closure, got %s", code.ID)
+	}
+	if tags := code.Metadata["tags"]; !reflect.DeepEqual(tags, []any{"hide-input", "thebe-init"}) {
+		t.Errorf("Unexpected tags: %v", tags)
+	}
+}
+
+func TestParser_QuartoDialect(t *testing.T) {
+	input := "Intro\n" +
+		"\n" +
+		"```{go}\n" +
+		"#| label: setup\n" +
+		"//| echo: false\n" +
+		"#| output: false\n" +
+		"#| slideshow.slide_type: fragment\n" +
+		"x := 1\n" +
+		"#| not an option once code started\n" +
+		"```\n" +
+		"\n" +
+		"```bash\n" +
+		"go run .\n" +
+		"```\n"
+
+	parser := NewParser(strings.NewReader(input))
+	parser.Config.Dialect = "quarto"
+	notebook, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(notebook.Cells) != 3 {
+		t.Fatalf("Expected 3 cells, got %d", len(notebook.Cells))
+	}
+
+	code := notebook.Cells[1]
+	if code.ID != "setup" {
+		t.Errorf("Expected ID setup, got %s", code.ID)
+	}
+	if got := code.Text(); got != "x := 1\n#| not an option once code started" {
+		t.Errorf("Options should be stripped from the code, got %q", got)
+	}
+	if tags := code.Metadata["tags"]; !reflect.DeepEqual(tags, []any{"hide-input", "remove-output"}) {
+		t.Errorf("Unexpected tags: %v", tags)
+	}
+	slideshow, _ := code.Metadata["slideshow"].(map[string]any)
+	if slideshow["slide_type"] != "fragment" {
+		t.Errorf("Expected nested slideshow metadata, got %v", code.Metadata)
+	}
+
+	// 非 {go} 的 code fence 屬於 markdown cell
+	if notebook.Cells[2].CellType != "markdown" || !strings.Contains(notebook.Cells[2].Text(), "go run .") {
+		t.Errorf("Plain code fence should stay in markdown, got %+v", notebook.Cells[2])
+	}
+}
+
+func TestParser_DialectErrors(t *testing.T) {
+	parser := NewParser(strings.NewReader("```{code-cell} go\nx := 1\n"))
+	parser.Config.Dialect = "myst"
+	if _, err := parser.Parse(); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Expected unclosed cell error with line number, got %v", err)
+	}
+
+	cfg := DefaultConfig()
+	if err := cfg.Set("dialect", "rmarkdown"); err == nil {
+		t.Error("Expected error for unknown dialect")
+	}
+}
+
+func TestNewFileParser_QuartoExtension(t *testing.T) {
+	parser := NewFileParser(strings.NewReader("```{go}\nx := 1\n```\n"), "ch5/closure.qmd", DefaultConfig())
+	notebook, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(notebook.Cells) != 1 || notebook.Cells[0].CellType != "code" {
+		t.Errorf("Expected one code cell, got %+v", notebook.Cells)
+	}
+}
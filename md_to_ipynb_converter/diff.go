@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// diffContext unified diff 每段變更前後保留的行數
+const diffContext = 3
+
+// maxDiffCells 兩段文字行數乘積超過此值時不做逐行比對，直接整段取代
+const maxDiffCells = 4_000_000
+
+// WriteUnifiedDiff 以 cell 為單位輸出 unified diff
+func WriteUnifiedDiff(w io.Writer, oldName, newName string, diffs []CellDiff) {
+	fmt.Fprintf(w, "--- %s\n+++ %s\n", oldName, newName)
+	for _, diff := range diffs {
+		fmt.Fprintf(w, "@@ %s @@\n", diff)
+		if diff.Kind == '>' {
+			continue
+		}
+		for _, line := range unifiedLines(diff.Old, diff.New) {
+			fmt.Fprintln(w, line)
+		}
+		if diff.OldQuestion != diff.NewQuestion {
+			for _, line := range unifiedLines(diff.OldQuestion, diff.NewQuestion) {
+				fmt.Fprintln(w, line)
+			}
+		}
+	}
+}
+
+// unifiedLines 回傳逐行比對的結果，每行以 ' '、'-'、'+' 開頭；
+// 離變更超過 diffContext 行的相同內容以 "..." 省略
+func unifiedLines(oldText, newText string) []string {
+	ops := diffLines(splitText(oldText), splitText(newText))
+
+	keep := make([]bool, len(ops))
+	for i, op := range ops {
+		if op[0] == ' ' {
+			continue
+		}
+		for j := i - diffContext; j <= i+diffContext; j++ {
+			if j >= 0 && j < len(ops) {
+				keep[j] = true
+			}
+		}
+	}
+
+	var lines []string
+	skipped := false
+	for i, op := range ops {
+		if !keep[i] {
+			skipped = true
+			continue
+		}
+		if skipped {
+			lines = append(lines, "...")
+			skipped = false
+		}
+		lines = append(lines, op)
+	}
+	return lines
+}
+
+// diffLines 以最長共同子序列比對兩組行
+func diffLines(a, b []string) []string {
+	if len(a)*len(b) > maxDiffCells {
+		var ops []string
+		for _, line := range a {
+			ops = append(ops, "-"+line)
+		}
+		for _, line := range b {
+			ops = append(ops, "+"+line)
+		}
+		return ops
+	}
+
+	// lcs[i][j] 為 a[i:] 與 b[j:] 的最長共同子序列長度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []string
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, " "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, "-"+a[i])
+			i++
+		default:
+			ops = append(ops, "+"+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, "-"+a[i])
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, "+"+b[j])
+	}
+	return ops
+}
+
+// splitText 將文字切成行（不含換行符）；空字串回傳空陣列
+func splitText(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedLines(t *testing.T) {
+	oldText := "a\nb\nc\nd\ne\nf\ng\nh\ni"
+	newText := "a\nb\nc\nd\ne\nf\nG\nh\ni"
+
+	got := strings.Join(unifiedLines(oldText, newText), "\n")
+	want := "...\n d\n e\n f\n-g\n+G\n h\n i"
+	if got != want {
+		t.Errorf("unifiedLines =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDiffLines_InsertAndDelete(t *testing.T) {
+	got := diffLines([]string{"x", "y"}, []string{"y", "z"})
+	want := []string{"-x", " y", "+z"}
+
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("diffLines = %v, want %v", got, want)
+	}
+}
+
+func TestWriteUnifiedDiff(t *testing.T) {
+	diffs := []CellDiff{
+		{Kind: '~', Index: 1, CellType: "code", Old: "fmt.Println(1)", New: "fmt.Println(2)"},
+		{Kind: '+', Index: 2, CellType: "markdown", New: "## 新小節"},
+	}
+
+	var buf bytes.Buffer
+	WriteUnifiedDiff(&buf, "a.ipynb", "b.ipynb", diffs)
+
+	want := `--- a.ipynb
++++ b.ipynb
+@@ ~ cell 1 [code] fmt.Println(2) @@
+-fmt.Println(1)
++fmt.Println(2)
+@@ + cell 2 [markdown] ## 新小節 @@
++## 新小節
+`
+	if buf.String() != want {
+		t.Errorf("WriteUnifiedDiff =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,388 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hank/learning-go/ch9/converter/outline"
+)
+
+//go:embed epub/style.css
+var epubStyle []byte
+
+// epubChapter 電子書中的一個 XHTML 檔（一個 notebook）
+type epubChapter struct {
+	File     string // 在 OEBPS/text/ 下的檔名
+	Title    string
+	Chapter  int
+	Body     string
+	Headings []Heading
+}
+
+// epubImage 打包進電子書的圖片
+type epubImage struct {
+	File      string // 在 OEBPS/images/ 下的檔名
+	MediaType string
+	Data      []byte
+}
+
+// epubBook 組成一本 EPUB 所需的內容
+type epubBook struct {
+	Title    string
+	Chapters []*epubChapter
+	Images   []*epubImage
+	// Nav 章節分組，與 HTML 網站的側邊欄相同
+	Nav      []siteNavChapter
+	Modified time.Time
+
+	imageFiles map[string]*epubImage // 內容雜湊 -> 圖片，相同的圖片只存一份
+}
+
+func runExportEPUB(args []string) error {
+	fs := flag.NewFlagSet("export epub", flag.ContinueOnError)
+	output := fs.String("o", "learning-go.epub", "輸出的 EPUB 檔")
+	tocPath := fs.String("toc", "", "全書目錄（預設從輸入檔往上尋找 "+outline.FileName+"）")
+	title := fs.String("title", "Learning Go", "書名")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s export epub [flags] input...\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "input 可以是 notebook、來源檔或目錄（遞迴尋找 .ipynb），依目錄.md 的章節順序排列\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := checkExportInputs(fs, false); err != nil {
+		return err
+	}
+
+	paths, err := collectNotebooks(fs.Args())
+	if err != nil {
+		return err
+	}
+	chapters, err := loadBookChapters(*tocPath, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	book := &epubBook{Title: *title, Modified: time.Now().UTC()}
+	usedFiles := map[string]bool{}
+	for _, path := range paths {
+		nb, err := LoadNotebookFile(path)
+		if err != nil {
+			return err
+		}
+		chapter, err := book.addNotebook(path, nb)
+		if err != nil {
+			return err
+		}
+		chapter.File = uniqueFileName(path, ".xhtml", usedFiles)
+	}
+	book.Nav = book.nav(chapters)
+
+	var buf bytes.Buffer
+	if err := book.Write(&buf); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(*output, buf.Bytes(), false); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	fmt.Printf("✅ 成功匯出: %d 個 notebooks -> %s\n", len(book.Chapters), *output)
+	if len(book.Images) > 0 {
+		fmt.Printf("📊 內含 %d 張圖片\n", len(book.Images))
+	}
+	return nil
+}
+
+// addNotebook 將 notebook 轉成 XHTML 章節；attachment、圖片輸出與本機圖片都會打包進電子書
+func (b *epubBook) addNotebook(path string, nb *Notebook) (*epubChapter, error) {
+	var imageErr error
+	addImage := func(mediaType string, data []byte) string {
+		return "../images/" + b.addImage(mediaType, data).File
+	}
+
+	body, headings := renderNotebookHTML(nb, htmlOptions{
+		XHTML: true,
+		Image: func(mediaType, data string) string {
+			content, err := decodeImage(mediaType, data)
+			if err != nil {
+				imageErr = err
+				return ""
+			}
+			return addImage(mediaType, content)
+		},
+		LocalImage: func(src string) string {
+			if strings.Contains(src, ":") || strings.HasPrefix(src, "/") {
+				return src // 網址或絕對路徑
+			}
+			file := filepath.Join(filepath.Dir(path), filepath.FromSlash(src))
+			data, err := os.ReadFile(file)
+			if err != nil {
+				imageErr = fmt.Errorf("failed to read image: %w", err)
+				return src
+			}
+			return addImage(mime.TypeByExtension(filepath.Ext(file)), data)
+		},
+	})
+	if imageErr != nil {
+		return nil, fmt.Errorf("%s: %w", path, imageErr)
+	}
+	if err := checkXHTML(body); err != nil {
+		return nil, fmt.Errorf("%s: invalid XHTML: %w", path, err)
+	}
+
+	chapter := &epubChapter{
+		Title:    strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		Chapter:  chapterOfPath(path),
+		Body:     body,
+		Headings: headings,
+	}
+	for _, heading := range headings {
+		if heading.Level == 1 {
+			chapter.Title = heading.Text
+			break
+		}
+	}
+	b.Chapters = append(b.Chapters, chapter)
+	return chapter, nil
+}
+
+// addImage 加入一張圖片，回傳對應的 epubImage；檔名取自內容雜湊
+func (b *epubBook) addImage(mediaType string, data []byte) *epubImage {
+	sum := sha1.Sum(data)
+	key := hex.EncodeToString(sum[:])
+	if image, ok := b.imageFiles[key]; ok {
+		return image
+	}
+	if b.imageFiles == nil {
+		b.imageFiles = map[string]*epubImage{}
+	}
+	if mediaType == "" {
+		mediaType = "application/octet-stream"
+	}
+	image := &epubImage{File: key[:12] + imageExtension(mediaType), MediaType: mediaType, Data: data}
+	b.imageFiles[key] = image
+	b.Images = append(b.Images, image)
+	return image
+}
+
+// nav 依全書目錄分組章節，沿用 HTML 網站側邊欄的規則
+func (b *epubBook) nav(chapters []outline.Chapter) []siteNavChapter {
+	pages := make([]*sitePage, len(b.Chapters))
+	for i, chapter := range b.Chapters {
+		pages[i] = &sitePage{File: chapter.File, Title: chapter.Title, Chapter: chapter.Chapter}
+	}
+	return siteNav(pages, chapters, nil)
+}
+
+// Write 輸出 EPUB 3（zip）：mimetype 必須是第一個檔案且不壓縮
+func (b *epubBook) Write(w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	mimetype, err := archive.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store, Modified: b.Modified})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mimetype, "application/epub+zip"); err != nil {
+		return err
+	}
+
+	opf, err := b.packageDocument()
+	if err != nil {
+		return err
+	}
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"META-INF/container.xml", []byte(epubContainer)},
+		{"OEBPS/content.opf", opf},
+		{"OEBPS/nav.xhtml", []byte(b.navDocument())},
+		{"OEBPS/style.css", epubStyle},
+	}
+	for _, chapter := range b.Chapters {
+		files = append(files, struct {
+			name string
+			data []byte
+		}{"OEBPS/text/" + chapter.File, []byte(epubXHTML(chapter.Title, "../style.css", chapter.Body))})
+	}
+	for _, image := range b.Images {
+		files = append(files, struct {
+			name string
+			data []byte
+		}{"OEBPS/images/" + image.File, image.Data})
+	}
+
+	for _, file := range files {
+		writer, err := archive.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: b.Modified})
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write(file.data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.name, err)
+		}
+	}
+	return archive.Close()
+}
+
+const epubContainer = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+// epubXHTML 完整的 XHTML 文件
+func epubXHTML(title, stylesheet, body string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="zh-Hant" lang="zh-Hant">
+<head>
+<meta charset="UTF-8"/>
+<title>` + html.EscapeString(title) + `</title>
+<link rel="stylesheet" type="text/css" href="` + stylesheet + `"/>
+</head>
+<body>
+` + body + `</body>
+</html>
+`
+}
+
+// navDocument EPUB 3 的導覽文件：章 -> notebook -> 二級標題
+func (b *epubBook) navDocument() string {
+	chapters := map[string]*epubChapter{}
+	for _, chapter := range b.Chapters {
+		chapters[chapter.File] = chapter
+	}
+
+	var nav strings.Builder
+	nav.WriteString(`<nav epub:type="toc" id="toc">` + "\n")
+	fmt.Fprintf(&nav, "<h1>%s</h1>\n<ol>\n", html.EscapeString(b.Title))
+	for _, group := range b.Nav {
+		if len(group.Pages) == 0 {
+			continue
+		}
+		// 導覽中的每一項都必須有連結，章名連到該章第一個 notebook
+		fmt.Fprintf(&nav, "<li><a href=\"text/%s\">%s</a>\n<ol>\n", html.EscapeString(group.Pages[0].File), html.EscapeString(group.Label))
+		for _, page := range group.Pages {
+			href := "text/" + page.File
+			fmt.Fprintf(&nav, "<li><a href=\"%s\">%s</a>", html.EscapeString(href), html.EscapeString(page.Title))
+			var sections []Heading
+			for _, heading := range chapters[page.File].Headings {
+				if heading.Level == 2 {
+					sections = append(sections, heading)
+				}
+			}
+			if len(sections) > 0 {
+				nav.WriteString("\n<ol>\n")
+				for _, heading := range sections {
+					fmt.Fprintf(&nav, "<li><a href=\"%s#%s\">%s</a></li>\n", html.EscapeString(href), html.EscapeString(heading.ID), html.EscapeString(heading.Text))
+				}
+				nav.WriteString("</ol>\n")
+			}
+			nav.WriteString("</li>\n")
+		}
+		nav.WriteString("</ol>\n</li>\n")
+	}
+	nav.WriteString("</ol>\n</nav>\n")
+	return epubXHTML(b.Title, "style.css", nav.String())
+}
+
+type opfPackage struct {
+	XMLName  xml.Name     `xml:"http://www.idpf.org/2007/opf package"`
+	Version  string       `xml:"version,attr"`
+	UniqueID string       `xml:"unique-identifier,attr"`
+	Lang     string       `xml:"xml:lang,attr"`
+	Metadata opfMetadata  `xml:"metadata"`
+	Manifest []opfItem    `xml:"manifest>item"`
+	Spine    []opfItemRef `xml:"spine>itemref"`
+}
+
+type opfMetadata struct {
+	DC         string        `xml:"xmlns:dc,attr"`
+	Identifier opfIdentifier `xml:"dc:identifier"`
+	Title      string        `xml:"dc:title"`
+	Language   string        `xml:"dc:language"`
+	Meta       []opfMeta     `xml:"meta"`
+}
+
+type opfIdentifier struct {
+	ID    string `xml:"id,attr"`
+	Value string `xml:",chardata"`
+}
+
+type opfMeta struct {
+	Property string `xml:"property,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type opfItem struct {
+	ID         string `xml:"id,attr"`
+	Href       string `xml:"href,attr"`
+	MediaType  string `xml:"media-type,attr"`
+	Properties string `xml:"properties,attr,omitempty"`
+}
+
+type opfItemRef struct {
+	IDRef string `xml:"idref,attr"`
+}
+
+// packageDocument 產生 content.opf；識別碼由書名與章節檔名計算，同一份內容每次匯出都相同
+func (b *epubBook) packageDocument() ([]byte, error) {
+	identity := sha1.New()
+	io.WriteString(identity, b.Title)
+	for _, chapter := range b.Chapters {
+		io.WriteString(identity, "\x00"+chapter.File)
+	}
+	sum := identity.Sum(nil)
+	sum[6] = sum[6]&0x0f | 0x50 // 第 5 版（以名稱雜湊產生）的 UUID
+	sum[8] = sum[8]&0x3f | 0x80
+	uuid := fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+
+	pkg := opfPackage{
+		Version:  "3.0",
+		UniqueID: "book-id",
+		Lang:     "zh-Hant",
+		Metadata: opfMetadata{
+			DC:         "http://purl.org/dc/elements/1.1/",
+			Identifier: opfIdentifier{ID: "book-id", Value: "urn:uuid:" + uuid},
+			Title:      b.Title,
+			Language:   "zh-Hant",
+			Meta:       []opfMeta{{Property: "dcterms:modified", Value: b.Modified.Format("2006-01-02T15:04:05Z")}},
+		},
+		Manifest: []opfItem{
+			{ID: "nav", Href: "nav.xhtml", MediaType: "application/xhtml+xml", Properties: "nav"},
+			{ID: "style", Href: "style.css", MediaType: "text/css"},
+		},
+		Spine: []opfItemRef{{IDRef: "nav"}},
+	}
+	for i, chapter := range b.Chapters {
+		id := fmt.Sprintf("chapter-%d", i+1)
+		pkg.Manifest = append(pkg.Manifest, opfItem{ID: id, Href: path.Join("text", chapter.File), MediaType: "application/xhtml+xml"})
+		pkg.Spine = append(pkg.Spine, opfItemRef{IDRef: id})
+	}
+	for i, image := range b.Images {
+		pkg.Manifest = append(pkg.Manifest, opfItem{ID: fmt.Sprintf("image-%d", i+1), Href: path.Join("images", image.File), MediaType: image.MediaType})
+	}
+
+	data, err := xml.MarshalIndent(pkg, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode content.opf: %w", err)
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hank/learning-go/ch9/converter/outline"
+)
+
+func TestExportEPUB(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, outline.FileName), "## 第一章 設定環境 .... 1\n## 第二章 基本型態 .... 17\n")
+	writeTestFile(t, filepath.Join(root, "ch1", "dot.png"), "png")
+
+	notebooks := map[string]string{
+		"ch2/ch2_types.ipynb": "# 基本型態\n\n## 常值\n\n<span style=\"color:red\">未結束 <span/>",
+		"ch1/ch1_note.ipynb":  "# 設定環境\n\n![圖](attachment:a.png) ![本機](dot.png)\n\n## GOPATH",
+	}
+	for path, markdown := range notebooks {
+		nb := NewNotebook()
+		nb.AddMarkdownCell("intro", markdown)
+		nb.Cells[0].Attachments = map[string]map[string]MultilineString{"a.png": {"image/png": {"aGVsbG8="}}}
+		nb.AddCodeCell("code", `fmt.Println("<hi>")`)
+		nb.Cells[1].Outputs = []any{map[string]any{"output_type": "display_data", "data": map[string]any{"image/png": "aGVsbG8="}}}
+		data, err := nb.ToJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeTestFile(t, filepath.Join(root, path), string(data))
+	}
+
+	output := filepath.Join(root, "book.epub")
+	if err := run([]string{"export", "epub", "-o", output, "--title", "Learning Go", root}); err != nil {
+		t.Fatalf("export epub failed: %v", err)
+	}
+
+	archive, err := zip.OpenReader(output)
+	if err != nil {
+		t.Fatalf("Output should be a zip file: %v", err)
+	}
+	defer archive.Close()
+
+	first := archive.File[0]
+	if first.Name != "mimetype" || first.Method != zip.Store {
+		t.Errorf("mimetype must be the first, uncompressed entry, got %s (method %d)", first.Name, first.Method)
+	}
+
+	files := map[string]string{}
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[file.Name] = string(data)
+
+		if strings.HasSuffix(file.Name, ".xhtml") || strings.HasSuffix(file.Name, ".opf") || strings.HasSuffix(file.Name, ".xml") {
+			decoder := xml.NewDecoder(strings.NewReader(string(data)))
+			for {
+				if _, err := decoder.Token(); err != nil {
+					if err != io.EOF {
+						t.Errorf("%s is not well-formed: %v", file.Name, err)
+					}
+					break
+				}
+			}
+		}
+	}
+
+	if files["mimetype"] != "application/epub+zip" {
+		t.Errorf("Unexpected mimetype %q", files["mimetype"])
+	}
+	for _, name := range []string{"META-INF/container.xml", "OEBPS/content.opf", "OEBPS/nav.xhtml", "OEBPS/style.css", "OEBPS/text/ch1_note.xhtml", "OEBPS/text/ch2_types.xhtml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("Missing %s", name)
+		}
+	}
+
+	// attachment 與圖片輸出內容相同，只存一份；本機圖片另存一份
+	var images []string
+	for name := range files {
+		if strings.HasPrefix(name, "OEBPS/images/") {
+			images = append(images, name)
+		}
+	}
+	if len(images) != 2 {
+		t.Errorf("Expected 2 images, got %v", images)
+	}
+
+	opf := files["OEBPS/content.opf"]
+	chapter1 := strings.Index(opf, `<itemref idref="chapter-1">`)
+	if !strings.Contains(opf, `href="text/ch1_note.xhtml"`) || chapter1 < 0 || !strings.Contains(opf, `media-type="image/png"`) {
+		t.Errorf("Unexpected content.opf:\n%s", opf)
+	}
+	if strings.Index(opf, "ch1_note.xhtml") > strings.Index(opf, "ch2_types.xhtml") {
+		t.Error("Chapters should follow the book order")
+	}
+
+	nav := files["OEBPS/nav.xhtml"]
+	for _, want := range []string{
+		`<nav epub:type="toc" id="toc">`,
+		`<li><a href="text/ch1_note.xhtml">第一章 設定環境</a>`,
+		`<li><a href="text/ch1_note.xhtml#GOPATH">GOPATH</a></li>`,
+		`<li><a href="text/ch2_types.xhtml#常值">常值</a></li>`,
+	} {
+		if !strings.Contains(nav, want) {
+			t.Errorf("nav.xhtml should contain %q:\n%s", want, nav)
+		}
+	}
+
+	chapter := files["OEBPS/text/ch1_note.xhtml"]
+	if !strings.Contains(chapter, `<img src="../images/`) || strings.Contains(chapter, "data:") {
+		t.Errorf("Images should reference packaged files:\n%s", chapter)
+	}
+	if !strings.Contains(files["OEBPS/text/ch2_types.xhtml"], "&lt;span/&gt;") {
+		t.Error("Unbalanced HTML should be escaped")
+	}
+}
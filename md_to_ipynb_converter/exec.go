@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// 執行 code cell 的結果
+const (
+	runPassed       = "passed"
+	runCompileError = "compile_error"
+	runRuntimeError = "runtime_error"
+	runTimeout      = "timeout"
+	runSkipped      = "skipped"
+)
+
+var (
+	// packageClauseRegex cell 中的 package 宣告；gonb 的 cell 可以省略
+	packageClauseRegex = regexp.MustCompile(`(?m)^package\s+(\w+)`)
+	// mainFuncRegex cell 中的 func main()
+	mainFuncRegex = regexp.MustCompile(`(?m)^func\s+main\s*\(\s*\)`)
+	// buildConstraintRegex 建置限制；筆記常用 //go:build ignore 避免範例被 go build ./... 編譯
+	buildConstraintRegex = regexp.MustCompile(`(?m)^//\s*(?:go:build|\+build)\b.*$`)
+)
+
+// CellRun 執行一個 code cell 的結果
+type CellRun struct {
+	// Cell 在 notebook 中的索引
+	Cell   int    `json:"cell"`
+	Status string `json:"status"`
+	Stdout string `json:"stdout,omitempty"`
+	// Stderr 編譯錯誤或程式的錯誤輸出
+	Stderr string `json:"stderr,omitempty"`
+}
+
+// Failed 編譯失敗、執行失敗或逾時
+func (r CellRun) Failed() bool {
+	return r.Status != runPassed && r.Status != runSkipped
+}
+
+// goProgram 將 code cell 轉成可單獨編譯的 main 套件；沒有 func main 的片段 ok 為 false
+func goProgram(source string) (string, bool) {
+	if !mainFuncRegex.MatchString(source) {
+		return "", false
+	}
+	source = buildConstraintRegex.ReplaceAllString(source, "")
+	match := packageClauseRegex.FindStringSubmatch(source)
+	if match == nil {
+		return "package main\n\n" + source, true
+	}
+	return source, match[1] == "main"
+}
+
+// RunGoProgram 在暫存目錄中編譯並執行一個 Go 程式；timeout 只限制執行時間，不含編譯
+func RunGoProgram(ctx context.Context, source string, timeout time.Duration) CellRun {
+	program, ok := goProgram(source)
+	if !ok {
+		return CellRun{Status: runSkipped}
+	}
+
+	dir, err := os.MkdirTemp("", "md2ipynb-run-")
+	if err != nil {
+		return CellRun{Status: runCompileError, Stderr: err.Error()}
+	}
+	defer os.RemoveAll(dir)
+
+	if err := writeModule(dir, map[string]string{"main.go": program}); err != nil {
+		return CellRun{Status: runCompileError, Stderr: err.Error()}
+	}
+
+	binary := filepath.Join(dir, "cell")
+	if out, err := goCommand(ctx, dir, "build", "-o", binary, ".").CombinedOutput(); err != nil {
+		return CellRun{Status: runCompileError, Stderr: compilerMessages(string(out), dir)}
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, binary)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+
+	result := CellRun{Status: runPassed, Stdout: stdout.String(), Stderr: strings.ReplaceAll(stderr.String(), dir+string(filepath.Separator), "")}
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result.Status = runTimeout
+	case err != nil:
+		result.Status = runRuntimeError
+		if result.Stderr == "" {
+			result.Stderr = err.Error()
+		}
+	}
+	return result
+}
+
+// writeModule 在暫存目錄中建立一個只有 main 套件、沒有外部依賴的 module
+func writeModule(dir string, files map[string]string) error {
+	goMod := fmt.Sprintf("module cell\n\ngo %s\n", goVersion())
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0o644); err != nil {
+		return err
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// goCommand 在 dir 中執行 go 指令；固定使用本機工具鏈，不下載其他版本
+func goCommand(ctx context.Context, dir string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOTOOLCHAIN=local")
+	return cmd
+}
+
+// ExecuteNotebook 依序執行 notebook 中每個完整的 Go 程式 cell；每個 cell 各自編譯，互不影響
+func ExecuteNotebook(ctx context.Context, nb *Notebook, timeout time.Duration) []CellRun {
+	var runs []CellRun
+	for i, cell := range nb.Cells {
+		if cell.CellType != "code" || strings.TrimSpace(cell.Text()) == "" {
+			continue
+		}
+		run := RunGoProgram(ctx, cell.Text(), timeout)
+		run.Cell = i
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// compilerMessages 去掉 go build 輸出中的暫存目錄與 `# cell` 標頭，只留下錯誤訊息
+func compilerMessages(out, dir string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line == "# cell" {
+			continue
+		}
+		line = strings.ReplaceAll(line, dir+string(filepath.Separator), "")
+		line = strings.ReplaceAll(line, dir, ".")
+		lines = append(lines, strings.TrimPrefix(line, "./"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// goVersion go.mod 的 go 指令使用目前工具鏈的版本，例如 1.24
+func goVersion() string {
+	version := strings.TrimPrefix(runtime.Version(), "go")
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 || strings.ContainsAny(parts[1], " -") {
+		return "1.21"
+	}
+	return parts[0] + "." + parts[1]
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGoProgram(t *testing.T) {
+	tests := []struct {
+		name, source, want string
+		ok                 bool
+	}{
+		{"complete", "package main\n\nfunc main() {}", "package main\n\nfunc main() {}", true},
+		{"gonb cell without package", "func main() {}", "package main\n\nfunc main() {}", true},
+		{"build constraint", "//go:build ignore\npackage main\n\nfunc main() {}", "\npackage main\n\nfunc main() {}", true},
+		{"fragment", "x := 1\nfmt.Println(x)", "", false},
+		{"other package", "package demo\n\nfunc main() {}", "package demo\n\nfunc main() {}", false},
+	}
+	for _, tt := range tests {
+		got, ok := goProgram(tt.source)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("%s: goProgram = %q, %v; want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRunGoProgram(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not available")
+	}
+
+	tests := []struct {
+		name, source, status, output string
+	}{
+		{"passed", "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hi\")\n}", runPassed, "hi\n"},
+		{"compile error", "package main\n\nimport \"os\"\n\nfunc main() {}", runCompileError, `"os" imported and not used`},
+		{"runtime error", "package main\n\nfunc main() {\n\tpanic(\"boom\")\n}", runRuntimeError, "boom"},
+		{"timeout", "package main\n\nimport \"time\"\n\nfunc main() {\n\ttime.Sleep(time.Hour)\n}", runTimeout, ""},
+		{"fragment", "x := 1", runSkipped, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := RunGoProgram(context.Background(), tt.source, 2*time.Second)
+			if run.Status != tt.status {
+				t.Fatalf("Status = %s, want %s (stderr: %s)", run.Status, tt.status, run.Stderr)
+			}
+			if !strings.Contains(run.Stdout+run.Stderr, tt.output) {
+				t.Errorf("Output should contain %q, got stdout %q stderr %q", tt.output, run.Stdout, run.Stderr)
+			}
+			if strings.Contains(run.Stderr, "md2ipynb-run-") {
+				t.Errorf("Messages should not mention the temporary directory: %s", run.Stderr)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// exporters export 子命令支援的輸出格式
+var exporters = map[string]func(w io.Writer, nb *Notebook) error{
+	"percent": WritePercent,
+	"slides":  WriteSlides,
+}
+
+// siteExporters 自行解析參數的格式：輸入多個 notebook 或輸出到目錄
+var siteExporters = map[string]func(args []string) error{
+	"html":   runExportHTML,
+	"md":     runExportMarkdown,
+	"epub":   runExportEPUB,
+	"gotest": runExportGoTest,
+}
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := fs.String("o", "", "輸出檔（預設為 stdout）")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s export <format> [-o output] input\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "formats: %s\n", strings.Join(exporterNames(), ", "))
+		fmt.Fprintf(os.Stderr, "所有格式都以 -o 指定輸出，位置參數都是輸入；input 可以是 .ipynb、標記 Markdown 或 percent 格式的 .go\n")
+	}
+	if len(args) == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	if exportSite, ok := siteExporters[args[0]]; ok {
+		return exportSite(args[1:])
+	}
+
+	export, ok := exporters[args[0]]
+	if !ok {
+		fs.Usage()
+		return fmt.Errorf("unknown export format %q", args[0])
+	}
+	fs.Init("export "+args[0], flag.ContinueOnError)
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if err := checkExportInputs(fs, true); err != nil {
+		return err
+	}
+
+	nb, err := LoadNotebookFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return export(os.Stdout, nb)
+	}
+
+	var buf bytes.Buffer
+	if err := export(&buf, nb); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(*output, buf.Bytes(), false); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	fmt.Printf("✅ 成功匯出: %s -> %s\n", fs.Arg(0), *output)
+	return nil
+}
+
+// checkExportInputs 檢查 export 的位置參數：所有格式都以 -o 指定輸出，位置參數都是輸入。
+// 不存在的輸入多半是把輸出寫成了位置參數，直接回報而不是當成輸入；single 的格式只接受一個輸入
+func checkExportInputs(fs *flag.FlagSet, single bool) error {
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	for _, input := range fs.Args() {
+		if _, err := os.Stat(input); errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("input %s does not exist; use -o to set the output", input)
+		}
+	}
+	if single && fs.NArg() > 1 {
+		return fmt.Errorf("%s takes one input; use -o to set the output", fs.Name())
+	}
+	return nil
+}
+
+// LoadNotebookFile 讀取 .ipynb，或依設定檔解析標記 Markdown / percent 格式的來源檔
+func LoadNotebookFile(path string) (*Notebook, error) {
+	if strings.HasSuffix(path, ".ipynb") {
+		return ReadNotebook(path)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open input file: %w", err)
+	}
+	defer file.Close()
+
+	parser := NewFileParser(file, path, cfg)
+	nb, err := parser.Parse()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	UpdateTOC(nb, parser.Config.TOCDepth, parser.Config.TOC)
+	return nb, nil
+}
+
+func exporterNames() []string {
+	names := make([]string, 0, len(exporters)+len(siteExporters))
+	for name := range exporters {
+		names = append(names, name)
+	}
+	for name := range siteExporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/doc/comment"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func runFromGo(args []string) error {
+	fs := flag.NewFlagSet("from-go", flag.ContinueOnError)
+	update := fs.Bool("update", false, "合併進既有 notebook，保留未變更 cell 的 outputs")
+	backup := fs.Bool("backup", false, "覆寫前把既有檔案保存成 .bak")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s from-go [flags] input.go [output.ipynb]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	inputPath := fs.Arg(0)
+	outputPath := strings.TrimSuffix(inputPath, ".go") + ".ipynb"
+	if fs.NArg() == 2 {
+		outputPath = fs.Arg(1)
+	}
+
+	src, err := os.ReadFile(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to open input file: %w", err)
+	}
+	notebook, err := NotebookFromGo(filepath.Base(inputPath), src)
+	if err != nil {
+		return err
+	}
+
+	if *update {
+		existing, err := ReadNotebook(outputPath)
+		switch {
+		case err == nil:
+			var report UpdateReport
+			notebook, report = UpdateNotebook(existing, notebook)
+			printUpdateReport(report)
+		case !errors.Is(err, os.ErrNotExist):
+			return fmt.Errorf("failed to read existing notebook: %w", err)
+		}
+	}
+
+	jsonData, err := notebook.ToJSON()
+	if err != nil {
+		return fmt.Errorf("failed to convert to JSON: %w", err)
+	}
+	if err := writeFileAtomic(outputPath, jsonData, *backup); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	fmt.Printf("✅ 成功轉換: %s -> %s\n", inputPath, outputPath)
+	fmt.Printf("📊 共 %d 個 cells\n", len(notebook.Cells))
+	return nil
+}
+
+// NotebookFromGo 以 go/ast 將一個 Go 原始檔轉換成 gonb notebook
+//
+// import、type、const、var 等共用宣告依原順序放進開頭的 setup cell；
+// 每個函式（含 main）各自成為一個 code cell，前面的 markdown cell 是函式名稱與 doc comment。
+// gonb 會記住每個 cell 的宣告，main 一律放在最後，依序執行到最後一個 cell 就會執行程式。
+func NotebookFromGo(filename string, src []byte) (*Notebook, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+
+	// source 取出 [from, to) 在原始碼中的文字
+	source := func(from, to token.Pos) string {
+		return string(src[fset.Position(from).Offset:fset.Position(to).Offset])
+	}
+
+	notebook := NewNotebook()
+	if err := notebook.SetKernel("gonb"); err != nil {
+		return nil, err
+	}
+	used := map[string]bool{}
+	add := func(cellType, id, content string) {
+		// 同名的 init 函式可以有多個
+		base := id
+		for n := 2; used[id]; n++ {
+			id = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[id] = true
+		if cellType == "code" {
+			notebook.AddCodeCell(id, content)
+		} else {
+			notebook.AddMarkdownCell(id, content)
+		}
+		notebook.Cells[len(notebook.Cells)-1].explicitID = true
+	}
+
+	intro := "# " + filename
+	if file.Doc != nil {
+		intro += "\n\n" + docMarkdown(file.Doc.Text())
+	}
+	add("markdown", "intro", intro)
+
+	var setup []string
+	var funcs []*ast.FuncDecl
+	var mainFunc *ast.FuncDecl
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			start := decl.Pos()
+			if decl.Doc != nil {
+				start = decl.Doc.Pos()
+			}
+			setup = append(setup, source(start, decl.End()))
+		case *ast.FuncDecl:
+			if decl.Recv == nil && decl.Name.Name == "main" {
+				mainFunc = decl
+				continue
+			}
+			funcs = append(funcs, decl)
+		}
+	}
+	if len(setup) > 0 {
+		add("code", "setup", strings.Join(setup, "\n\n"))
+	}
+
+	if mainFunc != nil {
+		funcs = append(funcs, mainFunc)
+	}
+	for _, fn := range funcs {
+		name := fn.Name.Name
+		if fn.Recv != nil && len(fn.Recv.List) > 0 {
+			name = receiverName(fn.Recv.List[0].Type) + "." + name
+		}
+		id := strings.ReplaceAll(name, ".", "-")
+
+		heading := "## `" + name + "`"
+		if fn.Doc != nil {
+			heading += "\n\n" + docMarkdown(fn.Doc.Text())
+		}
+		add("markdown", id+"-doc", heading)
+		add("code", id, source(fn.Pos(), fn.End()))
+	}
+
+	return notebook, nil
+}
+
+// receiverName 回傳方法接收者的型別名稱，例如 (p *Person) 回傳 Person
+func receiverName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(expr.X)
+	case *ast.IndexExpr:
+		return receiverName(expr.X)
+	case *ast.IndexListExpr:
+		return receiverName(expr.X)
+	case *ast.Ident:
+		return expr.Name
+	}
+	return "recv"
+}
+
+// docMarkdown 以 go/doc/comment 將 doc comment 轉成 Markdown
+func docMarkdown(text string) string {
+	var p comment.Parser
+	var printer comment.Printer
+	printer.HeadingLevel = 3
+	return strings.TrimSpace(string(printer.Markdown(p.Parse(text))))
+}
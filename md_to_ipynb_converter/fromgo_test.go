@@ -0,0 +1,103 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNotebookFromGo(t *testing.T) {
+	src := `// Package main 示範指標。
+package main
+
+import "fmt"
+
+// Person 會放進 setup cell
+type Person struct {
+	Name string
+}
+
+func main() {
+	p := &Person{Name: "Hank"}
+	p.Rename("Ham")
+	show(p)
+}
+
+// Rename 修改名字。
+//
+// 接收者是指標，所以會改到原本的值。
+func (p *Person) Rename(name string) {
+	p.Name = name
+}
+
+func show(p *Person) {
+	fmt.Println(p.Name) // 輸出: Ham
+}
+`
+
+	notebook, err := NotebookFromGo("pointer.go", []byte(src))
+	if err != nil {
+		t.Fatalf("NotebookFromGo failed: %v", err)
+	}
+
+	expectedIDs := []string{"intro", "setup", "Person-Rename-doc", "Person-Rename", "show-doc", "show", "main-doc", "main"}
+	if len(notebook.Cells) != len(expectedIDs) {
+		t.Fatalf("Expected %d cells, got %d", len(expectedIDs), len(notebook.Cells))
+	}
+	for i, id := range expectedIDs {
+		if notebook.Cells[i].ID != id {
+			t.Errorf("Cell %d: expected ID %s, got %s", i, id, notebook.Cells[i].ID)
+		}
+	}
+
+	if got := notebook.Cells[0].Text(); got != "# pointer.go\n\nPackage main 示範指標。" {
+		t.Errorf("Unexpected intro: %q", got)
+	}
+
+	setup := notebook.Cells[1].Text()
+	if !strings.HasPrefix(setup, `import "fmt"`) || !strings.Contains(setup, "// Person 會放進 setup cell\ntype Person struct") {
+		t.Errorf("Setup cell should hold imports and types with their comments, got %q", setup)
+	}
+
+	doc := notebook.Cells[2].Text()
+	if !strings.HasPrefix(doc, "## `Person.Rename`") || !strings.Contains(doc, "接收者是指標") {
+		t.Errorf("Doc comment should become markdown, got %q", doc)
+	}
+	if got := notebook.Cells[3].Text(); !strings.HasPrefix(got, "func (p *Person) Rename") || strings.Contains(got, "// Rename") {
+		t.Errorf("Code cell should start at the declaration without its doc comment, got %q", got)
+	}
+	if got := notebook.Cells[5].Text(); !strings.Contains(got, "// 輸出: Ham") {
+		t.Errorf("Comments inside functions should be kept, got %q", got)
+	}
+
+	if notebook.Metadata.Kernelspec.Name != "gonb" {
+		t.Errorf("Expected gonb kernel, got %s", notebook.Metadata.Kernelspec.Name)
+	}
+}
+
+func TestNotebookFromGo_SyntaxError(t *testing.T) {
+	if _, err := NotebookFromGo("bad.go", []byte("package main\nfunc {")); err == nil {
+		t.Error("Expected error for invalid Go source")
+	}
+}
+
+func TestFromGo_RepoExample(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "test_closure.ipynb")
+	if err := run([]string{"from-go", "../ch5/test_closure.go", output}); err != nil {
+		t.Fatalf("from-go failed: %v", err)
+	}
+
+	notebook, err := ReadNotebook(output)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	var ids []string
+	for _, cell := range notebook.Cells {
+		if cell.CellType == "code" {
+			ids = append(ids, cell.ID)
+		}
+	}
+	if got := strings.Join(ids, ","); got != "setup,testArray,testStruct,main" {
+		t.Errorf("Unexpected code cells: %s", got)
+	}
+}
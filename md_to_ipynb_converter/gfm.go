@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// attachmentRefRegex 符合 Markdown cell 中的 attachment:xxx.png 參照（圖片語法或 <img src>）
+var attachmentRefRegex = regexp.MustCompile(`attachment:([^)\s"']+)`)
+
+// imageExtensions 圖片 MIME 類型對應的副檔名
+var imageExtensions = map[string]string{
+	"image/png":     ".png",
+	"image/jpeg":    ".jpg",
+	"image/gif":     ".gif",
+	"image/svg+xml": ".svg",
+}
+
+// imageExtension 回傳圖片的副檔名；未知的類型使用 .bin
+func imageExtension(mime string) string {
+	if ext, ok := imageExtensions[mime]; ok {
+		return ext
+	}
+	return ".bin"
+}
+
+// GFMExport 匯出的 GitHub-flavored Markdown 與需要另外寫出的圖片
+type GFMExport struct {
+	Markdown string
+	// Images 圖片在 Markdown 中的相對路徑 -> 內容
+	Images map[string][]byte
+}
+
+func runExportMarkdown(args []string) error {
+	fs := flag.NewFlagSet("export md", flag.ContinueOnError)
+	output := fs.String("o", "", "輸出的 Markdown 檔（預設為 stdout，圖片寫到目前目錄下）")
+	imageDir := fs.String("images", "images", "圖片目錄（相對於輸出檔所在的目錄）")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s export md [-o output.md] [--images dir] input\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := checkExportInputs(fs, true); err != nil {
+		return err
+	}
+
+	input := fs.Arg(0)
+	nb, err := LoadNotebookFile(input)
+	if err != nil {
+		return err
+	}
+
+	// 每本 notebook 的圖片放在各自的子目錄，同一目錄匯出多本也不會互相覆蓋
+	stem := strings.TrimSuffix(filepath.Base(input), filepath.Ext(input))
+	export, err := ExportGFM(nb, path.Join(filepath.ToSlash(*imageDir), stem))
+	if err != nil {
+		return err
+	}
+
+	baseDir := "."
+	if *output != "" {
+		baseDir = filepath.Dir(*output)
+	}
+	for _, name := range sortedKeys(export.Images) {
+		target := filepath.Join(baseDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return fmt.Errorf("failed to create image directory: %w", err)
+		}
+		if err := writeFileAtomic(target, export.Images[name], false); err != nil {
+			return fmt.Errorf("failed to write image: %w", err)
+		}
+	}
+
+	if *output == "" {
+		_, err := fmt.Fprint(os.Stdout, export.Markdown)
+		return err
+	}
+	if err := writeFileAtomic(*output, []byte(export.Markdown), false); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	fmt.Printf("✅ 成功匯出: %s -> %s\n", input, *output)
+	if len(export.Images) > 0 {
+		fmt.Printf("📊 %d 張圖片 -> %s\n", len(export.Images), filepath.Join(baseDir, filepath.FromSlash(path.Join(*imageDir, stem))))
+	}
+	return nil
+}
+
+// ExportGFM 將 notebook 轉成給人閱讀的 GitHub-flavored Markdown（不是轉換器的輸入格式）
+//
+// Markdown cell 原樣輸出；code cell 成為 ```go 區塊；stream、錯誤與純文字輸出成為 ```text 區塊；
+// attachment 與圖片輸出以 imageDir 下的相對路徑參照，內容放在 GFMExport.Images。
+func ExportGFM(nb *Notebook, imageDir string) (*GFMExport, error) {
+	export := &GFMExport{Images: map[string][]byte{}}
+	var blocks []string
+
+	for i, cell := range nb.Cells {
+		text := strings.TrimRight(cell.Text(), "\n")
+		switch cell.CellType {
+		case "markdown":
+			var err error
+			text = attachmentRefRegex.ReplaceAllStringFunc(text, func(ref string) string {
+				name := strings.TrimPrefix(ref, "attachment:")
+				mime, data, ok := attachmentData(cell, name)
+				if !ok {
+					return ref
+				}
+				file := path.Join(imageDir, path.Base(name))
+				if filepath.Ext(name) == "" {
+					file += imageExtension(mime)
+				}
+				content, decodeErr := decodeImage(mime, data)
+				if decodeErr != nil {
+					err = fmt.Errorf("cell %d: attachment %s: %w", i+1, name, decodeErr)
+					return ref
+				}
+				export.Images[file] = content
+				return file
+			})
+			if err != nil {
+				return nil, err
+			}
+			if text != "" {
+				blocks = append(blocks, text)
+			}
+
+		case "code":
+			if text != "" {
+				blocks = append(blocks, fenced("go", text))
+			}
+			for n, output := range CellOutputs(cell) {
+				block, err := gfmOutput(output, func(mime, data string) (string, error) {
+					content, err := decodeImage(mime, data)
+					if err != nil {
+						return "", fmt.Errorf("cell %d: output %d: %w", i+1, n+1, err)
+					}
+					file := path.Join(imageDir, fmt.Sprintf("%s-output-%d%s", cellLabel(cell, i), n+1, imageExtension(mime)))
+					export.Images[file] = content
+					return file, nil
+				})
+				if err != nil {
+					return nil, err
+				}
+				if block != "" {
+					blocks = append(blocks, block)
+				}
+			}
+
+		default:
+			if text != "" {
+				blocks = append(blocks, text)
+			}
+		}
+	}
+
+	if len(blocks) > 0 {
+		export.Markdown = strings.Join(blocks, "\n\n") + "\n"
+	}
+	return export, nil
+}
+
+// gfmOutput 轉換單一輸出；圖片交給 saveImage 並以其回傳的路徑參照
+func gfmOutput(output Output, saveImage func(mime, data string) (string, error)) (string, error) {
+	switch output.Type {
+	case "stream", "error":
+		text := strings.TrimRight(output.Text, "\n")
+		if text == "" {
+			return "", nil
+		}
+		return fenced("text", text), nil
+	}
+
+	preferred := append(append([]string{}, ImageMIMEs...), "text/markdown", "text/plain", "text/html")
+	mime := output.PreferredMIME(preferred...)
+	data := output.Data[mime]
+	switch {
+	case strings.HasPrefix(mime, "image/"):
+		file, err := saveImage(mime, data)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("![output](%s)", file), nil
+	case mime == "text/plain":
+		return fenced("text", strings.TrimRight(data, "\n")), nil
+	case mime != "":
+		// text/markdown 與 text/html 都能直接放進 GFM
+		return strings.TrimSpace(data), nil
+	}
+	return "", nil
+}
+
+// fenced 以 ``` 包住內容；內容本身含有 ``` 時使用更長的圍欄
+func fenced(lang, text string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + text + "\n" + fence
+}
+
+// decodeImage 取得圖片的位元組；nbformat 中 SVG 是純文字，其他圖片是 base64
+func decodeImage(mime, data string) ([]byte, error) {
+	if mime == "image/svg+xml" {
+		return []byte(data), nil
+	}
+	content, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(data), ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", mime, err)
+	}
+	return content, nil
+}
+
+// cellLabel 圖片檔名使用的 cell 名稱：cell ID，沒有時使用 cell 序號
+func cellLabel(cell Cell, index int) string {
+	if cell.ID != "" {
+		return cell.ID
+	}
+	return fmt.Sprintf("cell%d", index+1)
+}
+
+func sortedKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportGFM(t *testing.T) {
+	nb := NewNotebook()
+	nb.AddMarkdownCell("intro", "# 標題\n\n![圖](attachment:dot.png)")
+	nb.Cells[0].Attachments = map[string]map[string]MultilineString{"dot.png": {"image/png": {"aGVsbG8="}}}
+	nb.AddCodeCell("code", "fmt.Println(\"```\")\n")
+	nb.Cells[1].Outputs = []any{
+		map[string]any{"output_type": "stream", "name": "stderr", "text": []any{"\x1b[7m[[ Cell [1] Line 7 ]]\x1b[0m\n"}},
+		map[string]any{"output_type": "display_data", "data": map[string]any{"image/svg+xml": []any{"<svg/>"}, "text/plain": "<Figure>"}},
+		map[string]any{"output_type": "execute_result", "data": map[string]any{"text/plain": "42\n"}},
+	}
+
+	export, err := ExportGFM(nb, "images/note")
+	if err != nil {
+		t.Fatalf("ExportGFM failed: %v", err)
+	}
+
+	want := "# 標題\n" +
+		"\n" +
+		"![圖](images/note/dot.png)\n" +
+		"\n" +
+		"````go\n" +
+		"fmt.Println(\"```\")\n" +
+		"````\n" +
+		"\n" +
+		"```text\n" +
+		"[[ Cell [1] Line 7 ]]\n" +
+		"```\n" +
+		"\n" +
+		"![output](images/note/code-output-2.svg)\n" +
+		"\n" +
+		"```text\n" +
+		"42\n" +
+		"```\n"
+	if export.Markdown != want {
+		t.Errorf("Markdown mismatch:\ngot:\n%s\nwant:\n%s", export.Markdown, want)
+	}
+
+	if string(export.Images["images/note/dot.png"]) != "hello" {
+		t.Errorf("Attachment should be decoded, got %q", export.Images["images/note/dot.png"])
+	}
+	if string(export.Images["images/note/code-output-2.svg"]) != "<svg/>" {
+		t.Errorf("SVG should be written as text, got %q", export.Images["images/note/code-output-2.svg"])
+	}
+}
+
+func TestExportGFM_InvalidAttachment(t *testing.T) {
+	nb := NewNotebook()
+	nb.AddMarkdownCell("", "![圖](attachment:a.png)")
+	nb.Cells[0].Attachments = map[string]map[string]MultilineString{"a.png": {"image/png": {"不是 base64"}}}
+
+	if _, err := ExportGFM(nb, "images"); err == nil || !strings.Contains(err.Error(), "attachment a.png") {
+		t.Errorf("Expected attachment error, got %v", err)
+	}
+}
+
+func TestExportMarkdown_WritesImages(t *testing.T) {
+	dir := t.TempDir()
+	nb := NewNotebook()
+	nb.AddMarkdownCell("intro", "![圖](attachment:dot.png)")
+	nb.Cells[0].Attachments = map[string]map[string]MultilineString{"dot.png": {"image/png": {"aGVsbG8="}}}
+	data, err := nb.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := filepath.Join(dir, "note.ipynb")
+	writeTestFile(t, input, string(data))
+
+	output := filepath.Join(dir, "docs", "note.md")
+	if err := run([]string{"export", "md", "-o", output, input}); err != nil {
+		t.Fatalf("export md failed: %v", err)
+	}
+
+	if got := readFile(t, output); got != "![圖](images/note/dot.png)\n" {
+		t.Errorf("Unexpected markdown: %q", got)
+	}
+	if got := readFile(t, filepath.Join(dir, "docs", "images", "note", "dot.png")); got != "hello" {
+		t.Errorf("Unexpected image content: %q", got)
+	}
+}
+
+func TestExportMarkdown_GoExam(t *testing.T) {
+	path := filepath.Join("..", "考題", "go_exam.ipynb")
+	if _, err := os.Stat(path); err != nil {
+		t.Skip("go_exam.ipynb not found")
+	}
+	nb, err := ReadNotebook(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	export, err := ExportGFM(nb, "images")
+	if err != nil {
+		t.Fatalf("ExportGFM failed: %v", err)
+	}
+	if strings.Contains(export.Markdown, "\x1b[") {
+		t.Error("ANSI escape codes should be stripped")
+	}
+	if !strings.Contains(export.Markdown, "```go\npackage main") || !strings.Contains(export.Markdown, "```text\nfatal error: all goroutines are asleep") {
+		t.Errorf("Unexpected export:\n%s", export.Markdown)
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	// outputBlockRegex 獨立一行的 `// 輸出:`，之後每行 `// ...` 都是預期輸出；
+	// `// 無序輸出:` 或 `// 輸出（順序不定）:` 表示輸出順序不固定
+	outputBlockRegex = regexp.MustCompile(`^//\s*(無序)?輸出\s*(?:[（(]([^)）]*)[)）])?\s*[:：]\s*$`)
+	// outputInlineRegex 行尾的 `// 輸出: hello`，表示這一行印出的內容
+	outputInlineRegex = regexp.MustCompile(`^//\s*(無序)?輸出\s*(?:[（(]([^)）]*)[)）])?\s*[:：]\s*(.+)$`)
+)
+
+// GoTestPackage 一個 code cell 轉換成的套件：cell 原本的程式加上呼叫 main 的 Example
+type GoTestPackage struct {
+	// Dir 套件目錄名稱，由 cell ID 產生
+	Dir string
+	// Main cell 原本的程式（main.go），不改任何名稱
+	Main string
+	// Example example_test.go：func Example() 呼叫 main，`// 輸出:` 註解轉成 Output 區塊
+	Example string
+}
+
+// GoTestPackages 將 notebook 中每個含 func main 的 code cell 轉成獨立的套件
+//
+// 每個 cell 各自是一個 package main，宣告的名稱不變，嵌入欄位與 %T 的輸出都與 notebook 相同；
+// `// 輸出:` 註解轉成 `// Output:` 或 `// Unordered output:`，`go test` 就能驗證筆記中的輸出是否正確。
+// 無法單獨編譯的 cell（例如示範編譯錯誤、依賴其他 cell 的宣告）會被略過，原因列在 skipped。
+func GoTestPackages(nb *Notebook) (packages []GoTestPackage, skipped []string) {
+	usedDirs := map[string]bool{}
+	checker := &goTestChecker{importer: importer.ForCompiler(token.NewFileSet(), "source", nil)}
+
+	for i, cell := range nb.Cells {
+		if cell.CellType != "code" || strings.TrimSpace(cell.Text()) == "" {
+			continue
+		}
+
+		pkg, err := goTestPackage(cell.Text(), checker)
+		if err != nil {
+			label := fmt.Sprintf("cell %d", i)
+			if cell.ID != "" {
+				label += " (" + cell.ID + ")"
+			}
+			skipped = append(skipped, fmt.Sprintf("%s: %s", label, firstLine(err.Error())))
+			continue
+		}
+		if pkg == nil {
+			continue
+		}
+
+		pkg.Dir = exampleSuffix(cell.ID, i)
+		for n := 2; usedDirs[pkg.Dir]; n++ {
+			pkg.Dir = fmt.Sprintf("%s%d", exampleSuffix(cell.ID, i), n)
+		}
+		usedDirs[pkg.Dir] = true
+		packages = append(packages, *pkg)
+	}
+	return packages, skipped
+}
+
+// goTestPackage 將一個 code cell 轉成套件；沒有 func main 的 cell 回傳 nil
+func goTestPackage(source string, checker *goTestChecker) (*GoTestPackage, error) {
+	source = buildConstraintRegex.ReplaceAllString(source, "")
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", source, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if file.Scope.Lookup("main") == nil {
+		return nil, nil
+	}
+	if file.Name.Name != "main" {
+		return nil, fmt.Errorf("package %s is not main", file.Name.Name)
+	}
+	if err := checker.check(fset, file); err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString("// Code generated by md2ipynb export gotest; DO NOT EDIT.\n\npackage main\n\n")
+	b.WriteString("func Example() {\n\tmain()" + outputComment(file.Comments))
+	if !strings.HasSuffix(b.String(), "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString("}\n")
+	return &GoTestPackage{Main: strings.TrimSpace(source) + "\n", Example: b.String()}, nil
+}
+
+// WriteGoTestDir 將每個套件寫在 dir 下的子目錄；dir 沒有 go.mod 時建立一個，方便在 dir 中執行 go test ./...
+func WriteGoTestDir(dir string, packages []GoTestPackage) error {
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); errors.Is(err, os.ErrNotExist) {
+		goMod := fmt.Sprintf("module examples\n\ngo %s\n", goVersion())
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+		if err := writeFileAtomic(filepath.Join(dir, "go.mod"), []byte(goMod), false); err != nil {
+			return err
+		}
+	}
+	for _, pkg := range packages {
+		pkgDir := filepath.Join(dir, pkg.Dir)
+		if err := os.MkdirAll(pkgDir, 0o755); err != nil {
+			return err
+		}
+		if err := writeFileAtomic(filepath.Join(pkgDir, "main.go"), []byte(pkg.Main), false); err != nil {
+			return err
+		}
+		if err := writeFileAtomic(filepath.Join(pkgDir, "example_test.go"), []byte(pkg.Example), false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runExportGoTest export gotest：每個含 func main 的 code cell 輸出成一個可以 go test 的套件
+func runExportGoTest(args []string) error {
+	fs := flag.NewFlagSet("export gotest", flag.ContinueOnError)
+	outputDir := fs.String("o", "examples", "輸出目錄，每個 cell 一個子目錄")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s export gotest [-o dir] input\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "input 可以是 .ipynb、標記 Markdown 或 percent 格式的 .go；之後在輸出目錄執行 go test ./...\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := checkExportInputs(fs, true); err != nil {
+		return err
+	}
+
+	nb, err := LoadNotebookFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	packages, skipped := GoTestPackages(nb)
+	for _, reason := range skipped {
+		fmt.Printf("⚠️  略過 %s\n", reason)
+	}
+	if err := WriteGoTestDir(*outputDir, packages); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *outputDir, err)
+	}
+	fmt.Printf("✅ 成功匯出: %s -> %s（%d 個 Example）\n", fs.Arg(0), *outputDir, len(packages))
+	return nil
+}
+
+// outputComment 由 `// 輸出:` 註解產生 Output 區塊；沒有時回傳空字串
+//
+// 有獨立一行的 `// 輸出:` 區塊時只使用區塊（通常是完整輸出），否則依序收集行尾的 `// 輸出: ...`。
+func outputComment(groups []*ast.CommentGroup) string {
+	var blockLines, inlineLines []string
+	unordered := false
+
+	for _, group := range groups {
+		for i := 0; i < len(group.List); i++ {
+			text := group.List[i].Text
+			if match := outputBlockRegex.FindStringSubmatch(text); match != nil {
+				unordered = unordered || isUnordered(match[1], match[2])
+				for i+1 < len(group.List) && strings.HasPrefix(group.List[i+1].Text, "//") {
+					i++
+					blockLines = append(blockLines, strings.TrimPrefix(strings.TrimPrefix(group.List[i].Text, "//"), " "))
+				}
+				continue
+			}
+			if match := outputInlineRegex.FindStringSubmatch(text); match != nil {
+				unordered = unordered || isUnordered(match[1], match[2])
+				inlineLines = append(inlineLines, strings.TrimSpace(match[3]))
+			}
+		}
+	}
+
+	lines := inlineLines
+	if len(blockLines) > 0 {
+		lines = blockLines
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	header := "// Output:"
+	if unordered {
+		header = "// Unordered output:"
+	}
+	var b strings.Builder
+	b.WriteString("\n\t" + header)
+	for _, line := range lines {
+		b.WriteString(strings.TrimRight("\n\t// "+line, " "))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// isUnordered 判斷 `無序輸出` 或 `輸出（順序不定）` 這類寫法
+func isUnordered(prefix, note string) bool {
+	return prefix != "" || strings.Contains(note, "順序") || strings.Contains(note, "無序")
+}
+
+// exampleSuffix 由 cell ID 產生 Example_ 之後的名稱，必須以小寫字母開頭
+func exampleSuffix(id string, index int) string {
+	var b strings.Builder
+	for _, r := range id {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	suffix := b.String()
+	if suffix == "" {
+		suffix = strconv.Itoa(index)
+	}
+	first := []rune(suffix)[0]
+	if !unicode.IsLower(first) {
+		if unicode.IsUpper(first) {
+			return string(unicode.ToLower(first)) + suffix[len(string(first)):]
+		}
+		suffix = "cell" + suffix
+	}
+	return suffix
+}
+
+// goTestChecker 以 go/types 確認 cell 可以單獨編譯；importer 會快取已載入的套件
+type goTestChecker struct {
+	importer types.Importer
+}
+
+func (c *goTestChecker) check(fset *token.FileSet, file *ast.File) error {
+	conf := types.Config{Importer: c.importer}
+	_, err := conf.Check("main", fset, []*ast.File{file}, nil)
+	return err
+}
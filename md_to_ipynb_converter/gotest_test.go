@@ -0,0 +1,149 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteGoTest(t *testing.T) {
+	nb := NewNotebook()
+	nb.AddMarkdownCell("intro", "# 標題")
+	nb.AddCodeCell("divide", `package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrZero = errors.New("除數不能為零")
+
+func divide(a, b int) (int, error) {
+	if b == 0 {
+		return 0, ErrZero
+	}
+	return a / b, nil
+}
+
+func main() {
+	_, err := divide(1, 0)
+	fmt.Println("錯誤:", err) // 輸出: 錯誤: 除數不能為零
+}`)
+	nb.AddCodeCell("cell-2", `package main
+
+import "fmt"
+
+func divide(a, b int) int { return a / b }
+
+func main() {
+	for _, v := range map[string]int{"a": 1, "b": 2} {
+		fmt.Println(v)
+	}
+	// 輸出（順序不定）:
+	// 1
+	// 2
+}`)
+	nb.AddCodeCell("broken", `package main
+
+func main() {
+	x := 1
+}`)
+
+	nb.AddCodeCell("helpers", "package main\n\nfunc helper() {}")
+
+	packages, skipped := GoTestPackages(nb)
+	var dirs []string
+	for _, pkg := range packages {
+		dirs = append(dirs, pkg.Dir)
+	}
+	assertStrings(t, "dirs", dirs, []string{"divide", "cell2"})
+	if len(skipped) != 1 || !strings.HasPrefix(skipped[0], "cell 3 (broken): ") {
+		t.Errorf("Expected the broken cell to be skipped, got %v", skipped)
+	}
+
+	// cell 的程式原封不動，名稱不加後綴
+	if !strings.Contains(packages[0].Main, "func divide(a, b int) (int, error) {") || !strings.Contains(packages[1].Main, "func divide(a, b int) int") {
+		t.Errorf("Cells should keep their declarations:\n%s", packages[0].Main)
+	}
+	for _, want := range []string{"package main", "func Example() {\n\tmain()\n\t// Output:\n\t// 錯誤: 除數不能為零\n}"} {
+		if !strings.Contains(packages[0].Example, want) {
+			t.Errorf("Example should contain %q, got:\n%s", want, packages[0].Example)
+		}
+	}
+	if !strings.Contains(packages[1].Example, "// Unordered output:\n\t// 1\n\t// 2\n}") {
+		t.Errorf("Expected unordered output, got:\n%s", packages[1].Example)
+	}
+}
+
+func TestExampleSuffix(t *testing.T) {
+	tests := map[string]string{
+		"cell-3":  "cell3",
+		"Setup":   "setup",
+		"a1b2c3":  "a1b2c3",
+		"9f2e1d0": "cell9f2e1d0",
+		"":        "cell5",
+	}
+	for id, want := range tests {
+		if got := exampleSuffix(id, 5); got != want {
+			t.Errorf("exampleSuffix(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestExportGoTest_RunsUnderGoTest(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not available")
+	}
+
+	// 嵌入欄位與 %T 的輸出依賴宣告的名稱
+	nb := NewNotebook()
+	nb.AddCodeCell("embedded", `package main
+
+import "fmt"
+
+type Base struct{ ID int }
+
+type Derived struct {
+	Base
+	Name string
+}
+
+func main() {
+	d := Derived{Base: Base{ID: 1}, Name: "x"}
+	fmt.Println(d.Base.ID, d.ID) // 輸出: 1 1
+	fmt.Printf("%T %v\n", d, d) // 輸出: main.Derived {{1} x}
+}`)
+	nb.AddCodeCell("base", `package main
+
+import "fmt"
+
+type Base struct{ Name string }
+
+func main() {
+	fmt.Printf("%T\n", Base{}) // 輸出: main.Base
+}`)
+	dir := t.TempDir()
+	input := filepath.Join(dir, "types.ipynb")
+	data, _ := nb.ToJSON()
+	writeTestFile(t, input, string(data))
+
+	output := filepath.Join(dir, "examples")
+	for _, path := range []string{input, "example.ipynb"} {
+		if err := run([]string{"export", "gotest", "-o", output, path}); err != nil {
+			t.Fatalf("export %s failed: %v", path, err)
+		}
+	}
+
+	cmd := exec.Command("go", "test", "-vet=off", "-v", "./...")
+	cmd.Dir = output
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go test failed: %v\n%s", err, out)
+	}
+	if n := strings.Count(string(out), "--- PASS: Example"); n < 2 {
+		t.Errorf("Expected the examples to run, got %d:\n%s", n, out)
+	}
+}
@@ -0,0 +1,397 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 批改結果；編譯錯誤與逾時沿用執行 cell 的狀態
+const (
+	gradeFailed     = "failed"
+	gradeUnanswered = "unanswered"
+)
+
+// maxGradeMessage 結果中保留的錯誤訊息行數
+const maxGradeMessage = 20
+
+// questionNumberRegex 手寫考題 id 中的題號，例如 go_exam-q10、ch3-q2-1、ch3-ex1
+var questionNumberRegex = regexp.MustCompile(`-(?:q|ex)(\d+)`)
+
+// failedTestRegex go test -v 中失敗的測試，例如 --- FAIL: TestSum (0.00s)
+var failedTestRegex = regexp.MustCompile(`(?m)^\s*--- FAIL: (\S+)`)
+
+// GradeResult 一題的批改結果
+type GradeResult struct {
+	ID         string   `json:"id"`
+	Kind       string   `json:"kind"`
+	Chapter    int      `json:"chapter,omitempty"`
+	Number     int      `json:"number"`
+	Title      string   `json:"title,omitempty"`
+	Difficulty string   `json:"difficulty,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	// Cell 作答 cell 的索引
+	Cell   int    `json:"cell"`
+	Status string `json:"status"`
+	// FailedTests 沒有通過的測試函式
+	FailedTests []string `json:"failed_tests,omitempty"`
+	// Message 編譯錯誤或測試失敗的輸出
+	Message string `json:"message,omitempty"`
+	// AnswerHash 批改時作答 cell 內容的 hash，用來判斷之後答案是否改過
+	AnswerHash string `json:"answer_hash,omitempty"`
+}
+
+// Passed 所有測試都通過
+func (r GradeResult) Passed() bool {
+	return r.Status == runPassed
+}
+
+// GradeReport 一份考題 notebook 的批改結果
+type GradeReport struct {
+	Notebook string        `json:"notebook"`
+	GradedAt time.Time     `json:"graded_at"`
+	Results  []GradeResult `json:"results"`
+}
+
+// Count 各狀態的題數
+func (r GradeReport) Count(status string) int {
+	n := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// RunGoTests 將答案與隱藏測試放在同一個套件中編譯並執行 go test；timeout 只限制執行時間，不含編譯
+func RunGoTests(ctx context.Context, answer, tests string, timeout time.Duration) GradeResult {
+	answer = buildConstraintRegex.ReplaceAllString(answer, "")
+	pkg := "main"
+	if match := packageClauseRegex.FindStringSubmatch(answer); match != nil {
+		pkg = match[1]
+	} else {
+		answer = "package main\n\n" + answer
+	}
+	if !packageClauseRegex.MatchString(tests) {
+		tests = "package " + pkg + "\n\n" + tests
+	}
+
+	dir, err := os.MkdirTemp("", "md2ipynb-grade-")
+	if err != nil {
+		return GradeResult{Status: runCompileError, Message: err.Error()}
+	}
+	defer os.RemoveAll(dir)
+	if err := writeModule(dir, map[string]string{"answer.go": answer, "answer_test.go": tests}); err != nil {
+		return GradeResult{Status: runCompileError, Message: err.Error()}
+	}
+
+	binary := filepath.Join(dir, "answer.test")
+	if out, err := goCommand(ctx, dir, "test", "-c", "-o", binary, ".").CombinedOutput(); err != nil {
+		return GradeResult{Status: runCompileError, Message: compilerMessages(string(out), dir)}
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, binary, "-test.v", "-test.count=1")
+	cmd.Dir = dir
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err = cmd.Run()
+
+	result := GradeResult{Status: runPassed}
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result.Status = runTimeout
+	case err != nil:
+		result.Status = gradeFailed
+		for _, match := range failedTestRegex.FindAllStringSubmatch(output.String(), -1) {
+			result.FailedTests = append(result.FailedTests, match[1])
+		}
+		result.Message = testFailureMessage(strings.ReplaceAll(output.String(), dir+string(filepath.Separator), ""))
+	}
+	return result
+}
+
+// testFailureMessage 只保留失敗訊息：去掉通過的測試與 go test 的統計行
+func testFailureMessage(output string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "=== RUN") || strings.HasPrefix(trimmed, "--- PASS") || trimmed == "FAIL" || trimmed == "PASS" {
+			continue
+		}
+		lines = append(lines, line)
+		if len(lines) == maxGradeMessage {
+			lines = append(lines, "...")
+			break
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// GradeNotebook 批改 notebook 中每個有隱藏測試的練習與題目；沒有作答的 cell 記為 unanswered
+func GradeNotebook(ctx context.Context, nb *Notebook, timeout time.Duration) []GradeResult {
+	var results []GradeResult
+	for i, cell := range nb.Cells {
+		meta, ok := questionMetaOf(cell)
+		if !ok || cell.CellType != "code" || meta.Tests == "" || (meta.Role != roleExercise && meta.Role != roleAnswerSlot) {
+			continue
+		}
+
+		result := GradeResult{Status: gradeUnanswered}
+		if contentHash(cell.Text()) != meta.Template {
+			result = RunGoTests(ctx, cell.Text(), meta.Tests, timeout)
+		}
+		result.ID, result.Kind, result.Chapter, result.Number = meta.ID, meta.Kind, meta.Chapter, meta.Number
+		result.Title, result.Difficulty, result.Tags = meta.Title, meta.Difficulty, meta.Tags
+		result.Cell, result.AnswerHash = i, contentHash(cell.Text())
+		results = append(results, result)
+	}
+	return results
+}
+
+// testsPath 手寫考題的測試檔，例如 考題/go_exam.ipynb -> 考題/go_exam_tests.md
+func testsPath(notebookPath string) string {
+	return strings.TrimSuffix(notebookPath, ".ipynb") + "_tests.md"
+}
+
+// ReadTestFile 讀取測試檔中以題目 id 標示的 TEST 區塊：
+//
+//	<!-- TEST id="go_exam-q1" -->
+//	```go
+//	func TestMain(t *testing.T) { ... }
+//	```
+//	<!-- END_TEST -->
+//
+// 同一個 id 的多個區塊會合併；區塊以外的文字是說明，會被略過
+func ReadTestFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tests := map[string]string{}
+	id := ""
+	var block []string
+	for i, line := range strings.Split(string(data), "\n") {
+		marker, attrs, ok := parseMarker(line)
+		switch {
+		case ok && marker == "TEST":
+			if id != "" {
+				return nil, fmt.Errorf("%s:%d: TEST is missing its END marker", path, i+1)
+			}
+			if id = attrs["id"]; id == "" {
+				return nil, fmt.Errorf("%s:%d: TEST needs an id", path, i+1)
+			}
+			block = nil
+		case ok && marker == "END_TEST":
+			if id == "" {
+				return nil, fmt.Errorf("%s:%d: unexpected END_TEST", path, i+1)
+			}
+			code := goFenceContent(strings.Join(block, "\n"))
+			if tests[id] != "" {
+				code = tests[id] + "\n\n" + code
+			}
+			tests[id] = code
+			id = ""
+		case id != "":
+			block = append(block, line)
+		}
+	}
+	if id != "" {
+		return nil, fmt.Errorf("%s: TEST %s is missing its END marker", path, id)
+	}
+	return tests, nil
+}
+
+// AttachTests 將測試檔的 TEST 區塊寫入手寫考題作答 cell 的 metadata，之後就能以 GradeNotebook 批改。
+// 手寫考題沒有作答範本，作答 cell 一律視為已作答
+func AttachTests(path string, nb *Notebook, tests map[string]string, table map[string]int) error {
+	questions := map[string]BankQuestion{}
+	for _, q := range extractHandwrittenQuestions(path, nb, table) {
+		questions[q.ID] = q
+	}
+
+	ids := make([]string, 0, len(tests))
+	for id := range tests {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		q, ok := questions[id]
+		if !ok || q.Cell < 0 {
+			return fmt.Errorf("%s: no answer cell for question %s", path, id)
+		}
+		role := roleAnswerSlot
+		if q.Kind == kindExercise {
+			role = roleExercise
+		}
+		number := 0
+		if match := questionNumberRegex.FindStringSubmatch(id); match != nil {
+			number, _ = strconv.Atoi(match[1])
+		}
+		cell := &nb.Cells[q.Cell]
+		if cell.Metadata == nil {
+			cell.Metadata = CellMetadata{}
+		}
+		cell.Metadata["question"] = QuestionMeta{
+			ID: q.ID, Kind: q.Kind, Chapter: q.Chapter, Number: number, Title: q.Title,
+			Tags: q.Tags, Role: role, Template: contentHash(answerStub(cell.Text())), Tests: tests[id],
+		}
+	}
+	return nil
+}
+
+// answerStub 手寫作答 cell 沒有作答時的樣子：只留下 package、註解與空行。
+// 作答 cell 只有這些內容時與 stub 相同，批改時記為 unanswered
+func answerStub(text string) string {
+	var stub []string
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "//") || packageClauseRegex.MatchString(trimmed) {
+			stub = append(stub, line)
+		}
+	}
+	return strings.Join(stub, "\n")
+}
+
+// gradesPath 批改結果的預設路徑，例如 ch5_interview_questions.ipynb -> ch5_interview_questions.grades.json
+func gradesPath(notebookPath string) string {
+	return strings.TrimSuffix(notebookPath, ".ipynb") + ".grades.json"
+}
+
+// ReadGradeReport 讀取 grade 寫入的批改結果
+func ReadGradeReport(path string) (*GradeReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var report GradeReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// writeGradeTable 輸出每題的結果
+func writeGradeTable(w io.Writer, report GradeReport) {
+	icons := map[string]string{
+		runPassed:       "✅",
+		gradeFailed:     "❌",
+		runCompileError: "🛠️",
+		runTimeout:      "⏱️",
+		gradeUnanswered: "⬜",
+	}
+	for _, result := range report.Results {
+		meta := QuestionMeta{Kind: result.Kind, Number: result.Number, Title: result.Title}
+		fmt.Fprintf(w, "%s %-24s %s %s\n", icons[result.Status], result.ID, meta.Heading(), result.Status)
+		if len(result.FailedTests) > 0 {
+			fmt.Fprintf(w, "   失敗的測試: %s\n", strings.Join(result.FailedTests, ", "))
+		}
+		if result.Status == runCompileError || result.Status == runTimeout {
+			if message := firstLine(result.Message); message != "" {
+				fmt.Fprintf(w, "   %s\n", message)
+			}
+		}
+	}
+	answered := len(report.Results) - report.Count(gradeUnanswered)
+	fmt.Fprintf(w, "📊 通過 %d / %d 題（已作答 %d 題）\n", report.Count(runPassed), len(report.Results), answered)
+}
+
+// runGrade grade 子命令：以隱藏測試批改考題 notebook 中的答案
+func runGrade(args []string) error {
+	fs := flag.NewFlagSet("grade", flag.ContinueOnError)
+	output := fs.String("o", "", "批改結果的 JSON 檔（預設為 <notebook>.grades.json；只能批改一個 notebook）")
+	timeout := fs.Duration("timeout", 0, "每題測試的執行時間上限（預設為設定檔的 exec_timeout）")
+	testsFile := fs.String("tests", "", "手寫考題的測試檔（預設為 <notebook>_tests.md；只能批改一個 notebook）")
+	trust := fs.Bool("trust", false, "確認作答可信任：作答以目前的使用者身分執行，沒有沙箱")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s grade --trust [-o results.json] [--tests file] [--timeout d] questions.ipynb...\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "批改有 TEST 區塊的練習與題目，結果寫在 notebook 旁的 .grades.json\n")
+		fmt.Fprintf(os.Stderr, "作答以目前的使用者身分直接執行，可以讀寫檔案、連網與執行其他程式；只批改自己信任的作答\n")
+		fmt.Fprintf(os.Stderr, "手寫的考題（例如 考題/go_exam.ipynb）從旁邊的 <notebook>_tests.md 讀取測試\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if (*output != "" || *testsFile != "") && fs.NArg() > 1 {
+		return fmt.Errorf("-o and --tests can only be used with a single notebook")
+	}
+	if !*trust {
+		return fmt.Errorf("grade runs answers as the current user without a sandbox; pass --trust to grade answers you trust")
+	}
+
+	ctx := context.Background()
+	for _, path := range fs.Args() {
+		cfg, err := LoadConfig(path)
+		if err != nil {
+			return err
+		}
+		limit := *timeout
+		if limit == 0 {
+			limit = time.Duration(cfg.ExecTimeout)
+		}
+		nb, err := ReadNotebook(path)
+		if err != nil {
+			return err
+		}
+
+		// 手寫的考題：測試寫在旁邊的測試檔
+		testsFrom := *testsFile
+		if testsFrom == "" {
+			testsFrom = testsPath(path)
+		}
+		tests, err := ReadTestFile(testsFrom)
+		switch {
+		case err == nil:
+			if err := AttachTests(path, nb, tests, prerequisiteTable(cfg.Prerequisites)); err != nil {
+				return err
+			}
+		case *testsFile != "" || !errors.Is(err, os.ErrNotExist):
+			return err
+		}
+
+		report := GradeReport{Notebook: filepath.ToSlash(path), GradedAt: time.Now().UTC().Truncate(time.Second), Results: GradeNotebook(ctx, nb, limit)}
+		if len(report.Results) == 0 {
+			fmt.Printf("ℹ️  %s 沒有含 TEST 區塊的題目\n", path)
+			continue
+		}
+		fmt.Printf("📝 %s\n", path)
+		writeGradeTable(os.Stdout, report)
+
+		resultsPath := *output
+		if resultsPath == "" {
+			resultsPath = gradesPath(path)
+		}
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(resultsPath, append(data, '\n'), false); err != nil {
+			return fmt.Errorf("failed to write %s: %w", resultsPath, err)
+		}
+		fmt.Printf("✅ 批改結果: %s\n", resultsPath)
+	}
+	return nil
+}
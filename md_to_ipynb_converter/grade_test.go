@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const gradeTests = "import \"testing\"\n\nfunc TestDouble(t *testing.T) {\n\tif double(2) != 4 {\n\t\tt.Errorf(\"double(2) = %d\", double(2))\n\t}\n}"
+
+func TestParseInterview_Tests(t *testing.T) {
+	input := "<!-- QUESTION title=\"double\" -->\n寫出 double\n<!-- TEST -->\n```go\n" + gradeTests + "\n```\n<!-- END_TEST -->\n<!-- TEST -->\n```go\nfunc TestZero(t *testing.T) {}\n```\n<!-- END_TEST -->\n<!-- END_QUESTION -->"
+	nb, err := NewParser(strings.NewReader(input)).Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prompt, _ := questionMetaOf(nb.Cells[1])
+	slot, _ := questionMetaOf(nb.Cells[2])
+	if prompt.Tests != "" || strings.Contains(nb.Cells[1].Text(), "TestDouble") {
+		t.Error("Tests must stay hidden from the prompt")
+	}
+	if slot.Role != roleAnswerSlot || slot.Tests != gradeTests+"\n\nfunc TestZero(t *testing.T) {}" {
+		t.Errorf("Expected both TEST blocks on the answer cell, got %q", slot.Tests)
+	}
+}
+
+func TestCheck_StaleTests(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "ch5", "ch5_interview_questions_source.md")
+	output := filepath.Join(dir, "ch5", "ch5_interview_questions.ipynb")
+	withTests := func(tests string) string {
+		return strings.Replace(interviewSource, "<!-- END_EXERCISE -->", "<!-- TEST -->\n"+tests+"\n<!-- END_TEST -->\n<!-- END_EXERCISE -->", 1)
+	}
+	writeTestFile(t, source, withTests(gradeTests))
+	if err := convert(source, output); err != nil {
+		t.Fatal(err)
+	}
+
+	// 只改 TEST 區塊：考題的文字不變，但 notebook 中的測試已經過期
+	writeTestFile(t, source, withTests(strings.Replace(gradeTests, "double(2) != 4", "double(3) != 6", 1)))
+	if err := run([]string{"convert", "--check", source, output}); err == nil {
+		t.Error("Expected --check to catch stale TEST blocks")
+	}
+}
+
+func TestRunGoTests(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not available")
+	}
+
+	tests := []struct {
+		name, answer, status, message string
+	}{
+		{"passed", "func double(n int) int { return n * 2 }", runPassed, ""},
+		{"failed", "package main\n\nfunc double(n int) int { return n + 1 }", gradeFailed, "double(2) = 3"},
+		{"compile error", "func double(n int) string { return \"\" }", runCompileError, "mismatched types"},
+		{"timeout", "func double(n int) int {\n\tfor {\n\t}\n}", runTimeout, ""},
+	}
+	for _, tt := range tests {
+		result := RunGoTests(context.Background(), tt.answer, gradeTests, 2*time.Second)
+		if result.Status != tt.status || !strings.Contains(result.Message, tt.message) {
+			t.Errorf("%s: got %s %q", tt.name, result.Status, result.Message)
+		}
+		if tt.status == gradeFailed && strings.Join(result.FailedTests, ",") != "TestDouble" {
+			t.Errorf("%s: expected TestDouble to fail, got %v", tt.name, result.FailedTests)
+		}
+	}
+}
+
+func TestRunGrade(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not available")
+	}
+
+	dir := t.TempDir()
+	source := filepath.Join(dir, "ch5", "ch5_interview_questions_source.md")
+	output := filepath.Join(dir, "ch5", "ch5_interview_questions.ipynb")
+	writeTestFile(t, source, "<!-- QUESTION id=\"double\" -->\n寫出 double\n<!-- TEST -->\n"+gradeTests+"\n<!-- END_TEST -->\n<!-- END_QUESTION -->\n\n"+
+		"<!-- QUESTION id=\"skip\" -->\n還沒寫\n<!-- TEST -->\n"+gradeTests+"\n<!-- END_TEST -->\n<!-- END_QUESTION -->\n")
+	if err := convert(source, output); err != nil {
+		t.Fatal(err)
+	}
+
+	nb, err := ReadNotebook(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nb.Cells[2].Source = splitLines("// 答案\nfunc double(n int) int { return n * 2 }")
+	data, _ := nb.ToJSON()
+	writeTestFile(t, output, string(data))
+
+	if err := run([]string{"grade", "--trust", output}); err != nil {
+		t.Fatal(err)
+	}
+	report, err := ReadGradeReport(gradesPath(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Results) != 2 || !report.Results[0].Passed() || report.Results[0].Chapter != 5 || report.Results[1].Status != gradeUnanswered {
+		t.Errorf("Unexpected results: %+v", report.Results)
+	}
+}
+
+func TestReadTestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "go_exam_tests.md")
+	writeTestFile(t, path, "# 說明\n\n<!-- TEST id=\"go_exam-q1\" -->\n```go\n"+gradeTests+"\n```\n<!-- END_TEST -->\n"+
+		"<!-- TEST id=\"go_exam-q1\" -->\nfunc TestZero(t *testing.T) {}\n<!-- END_TEST -->\n")
+	tests, err := ReadTestFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tests["go_exam-q1"]; got != gradeTests+"\n\nfunc TestZero(t *testing.T) {}" {
+		t.Errorf("Expected both blocks without fences, got %q", got)
+	}
+
+	for name, content := range map[string]string{
+		"missing id":  "<!-- TEST -->\n<!-- END_TEST -->",
+		"unclosed":    "<!-- TEST id=\"a\" -->\nfunc TestA(t *testing.T) {}",
+		"stray end":   "<!-- END_TEST -->",
+		"nested test": "<!-- TEST id=\"a\" -->\n<!-- TEST id=\"b\" -->\n<!-- END_TEST -->",
+	} {
+		writeTestFile(t, path, content)
+		if _, err := ReadTestFile(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestRunGrade_HandwrittenTests(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not available")
+	}
+
+	// 考題/go_exam.ipynb：只有題號的標題，測試寫在旁邊的 go_exam_tests.md
+	dir := filepath.Join(t.TempDir(), "考題")
+	output := filepath.Join(dir, "go_exam.ipynb")
+	nb := NewNotebook()
+	nb.AddMarkdownCell("", "## 1")
+	nb.AddCodeCell("", "package main\n\nfunc double(n int) int { return n * 2 }\n\nfunc main() {}")
+	nb.AddMarkdownCell("", "## 2\n\n我的筆記")
+	nb.AddMarkdownCell("", "## 題目 3：double\n\n寫出 double")
+	nb.AddCodeCell("", "// 請在這裡寫下你的答案")
+	data, _ := nb.ToJSON()
+	writeTestFile(t, output, string(data))
+	writeTestFile(t, testsPath(output), "<!-- TEST id=\"go_exam-q1\" -->\n```go\n"+gradeTests+"\n```\n<!-- END_TEST -->\n"+
+		"<!-- TEST id=\"go_exam-q3\" -->\n"+gradeTests+"\n<!-- END_TEST -->\n")
+
+	if err := run([]string{"grade", output}); err == nil || !strings.Contains(err.Error(), "--trust") {
+		t.Errorf("Expected grade to require --trust, got %v", err)
+	}
+
+	if err := run([]string{"grade", "--trust", output}); err != nil {
+		t.Fatal(err)
+	}
+	report, err := ReadGradeReport(gradesPath(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 只有註解的作答 cell 與 stub 相同，記為未作答
+	if len(report.Results) != 2 || report.Results[0].ID != "go_exam-q1" || !report.Results[0].Passed() || report.Results[0].Number != 1 || report.Results[0].Cell != 1 ||
+		report.Results[1].ID != "go_exam-q3" || report.Results[1].Status != gradeUnanswered {
+		t.Errorf("Unexpected results: %+v", report.Results)
+	}
+
+	// 測試檔中的題目不存在
+	writeTestFile(t, testsPath(output), "<!-- TEST id=\"go_exam-q9\" -->\n"+gradeTests+"\n<!-- END_TEST -->\n")
+	if err := run([]string{"grade", "--trust", output}); err == nil || !strings.Contains(err.Error(), "go_exam-q9") {
+		t.Errorf("Expected an unknown question error, got %v", err)
+	}
+}
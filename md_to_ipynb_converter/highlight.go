@@ -0,0 +1,80 @@
+package main
+
+import (
+	"go/scanner"
+	"go/token"
+	"html"
+	"strings"
+)
+
+// goBuiltins 內建的型別與函式，以不同顏色顯示
+var goBuiltins = map[string]bool{
+	"any": true, "bool": true, "byte": true, "comparable": true, "complex64": true, "complex128": true,
+	"error": true, "float32": true, "float64": true, "int": true, "int8": true, "int16": true,
+	"int32": true, "int64": true, "rune": true, "string": true, "uint": true, "uint8": true,
+	"uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"true": true, "false": true, "iota": true, "nil": true,
+	"append": true, "cap": true, "clear": true, "close": true, "complex": true, "copy": true,
+	"delete": true, "imag": true, "len": true, "make": true, "max": true, "min": true, "new": true,
+	"panic": true, "print": true, "println": true, "real": true, "recover": true,
+}
+
+// HighlightGo 以 go/scanner 將 Go 程式碼轉成加上 <span class="..."> 的 HTML
+//
+// 使用的 class：kw（關鍵字）、str（字串與字元）、num（數字）、com（註解）、bi（內建識別字）。
+// 無法編譯的程式碼片段也能處理，原始的空白與排版會完整保留。
+func HighlightGo(code string) string {
+	src := []byte(code)
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+
+	var s scanner.Scanner
+	s.Init(file, src, func(token.Position, string) {}, scanner.ScanComments)
+
+	var b strings.Builder
+	last := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		// 自動插入的分號不在原始碼中
+		if tok == token.SEMICOLON && lit == "\n" {
+			continue
+		}
+
+		start := file.Offset(pos)
+		end := start + len(lit)
+		if lit == "" {
+			end = start + len(tok.String())
+		}
+		if start < last || end > len(src) {
+			continue
+		}
+
+		class := ""
+		switch {
+		case tok == token.COMMENT:
+			class = "com"
+		case tok == token.STRING || tok == token.CHAR:
+			class = "str"
+		case tok == token.INT || tok == token.FLOAT || tok == token.IMAG:
+			class = "num"
+		case tok.IsKeyword():
+			class = "kw"
+		case tok == token.IDENT && goBuiltins[lit]:
+			class = "bi"
+		}
+
+		b.WriteString(html.EscapeString(code[last:start]))
+		text := html.EscapeString(code[start:end])
+		if class != "" {
+			b.WriteString(`<span class="` + class + `">` + text + `</span>`)
+		} else {
+			b.WriteString(text)
+		}
+		last = end
+	}
+	b.WriteString(html.EscapeString(code[last:]))
+	return b.String()
+}
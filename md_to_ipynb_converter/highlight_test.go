@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestHighlightGo(t *testing.T) {
+	code := "func main() {\n\t// 註解\n\ts := \"<a>\" + string(rune(65))\n\tfmt.Println(s, 42, nil)\n}"
+	want := `<span class="kw">func</span> main() {` + "\n\t" +
+		`<span class="com">// 註解</span>` + "\n\t" +
+		`s := <span class="str">&#34;&lt;a&gt;&#34;</span> + <span class="bi">string</span>(<span class="bi">rune</span>(<span class="num">65</span>))` + "\n\t" +
+		`fmt.Println(s, <span class="num">42</span>, <span class="bi">nil</span>)` + "\n}"
+
+	if got := HighlightGo(code); got != want {
+		t.Errorf("HighlightGo mismatch:\ngot  %s\nwant %s", got, want)
+	}
+}
+
+func TestHighlightGo_InvalidCode(t *testing.T) {
+	// 筆記中的程式碼片段不一定能編譯，內容必須完整保留
+	code := "所以會印出 %% 3\n`未結束"
+	if got := HighlightGo(code); plainText(got) != code {
+		t.Errorf("Text should be preserved, got %q", plainText(got))
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	_ "embed"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+//go:embed hooks/pre-commit
+var preCommitHook []byte
+
+func runInstallHook(args []string) error {
+	fs := flag.NewFlagSet("install-hook", flag.ContinueOnError)
+	force := fs.Bool("force", false, "覆寫已存在的 pre-commit hook")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	path, err := installPreCommitHook(".", *force)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ 已安裝 pre-commit hook: %s\n", path)
+	return nil
+}
+
+// installPreCommitHook 將 pre-commit hook 寫入 dir 所在 git repository 的 hooks 目錄
+func installPreCommitHook(dir string, force bool) (string, error) {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate git hooks directory: %w", err)
+	}
+
+	hooksDir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(hooksDir) {
+		hooksDir = filepath.Join(dir, hooksDir)
+	}
+	path := filepath.Join(hooksDir, "pre-commit")
+
+	if _, err := os.Stat(path); err == nil && !force {
+		return "", fmt.Errorf("%s already exists (use --force to overwrite)", path)
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	if err := os.WriteFile(path, preCommitHook, 0755); err != nil {
+		return "", fmt.Errorf("failed to write hook: %w", err)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,463 @@
+package main
+
+import (
+	"embed"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hank/learning-go/ch9/converter/outline"
+)
+
+// siteAssets HTML 網站的版面、樣式與搜尋程式
+//
+//go:embed site/page.html site/style.css site/search.js
+var siteAssets embed.FS
+
+// sitePage 網站中的一頁（一個 notebook）
+type sitePage struct {
+	Source   string // 輸入檔路徑
+	File     string // 輸出的檔名，例如 ch10_concurrency_part1.html
+	Title    string
+	Chapter  int
+	Body     string
+	Headings []Heading
+	Text     string // 搜尋用的純文字
+}
+
+// siteNavChapter 側邊欄中的一章
+type siteNavChapter struct {
+	Label string
+	Pages []siteNavLink
+}
+
+type siteNavLink struct {
+	Title   string
+	File    string
+	Current bool
+}
+
+// sitePageData page.html 的資料
+type sitePageData struct {
+	SiteTitle  string
+	Title      string
+	Nav        []siteNavChapter
+	Prev, Next *sitePage
+	Body       template.HTML
+}
+
+func runExportHTML(args []string) error {
+	fs := flag.NewFlagSet("export html", flag.ContinueOnError)
+	outputDir := fs.String("o", "site", "輸出目錄")
+	tocPath := fs.String("toc", "", "全書目錄（預設從輸入檔往上尋找 "+outline.FileName+"）")
+	siteTitle := fs.String("title", "Learning Go", "網站標題")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s export html [flags] input...\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "input 可以是 notebook、來源檔或目錄（遞迴尋找 .ipynb）\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := checkExportInputs(fs, false); err != nil {
+		return err
+	}
+
+	paths, err := collectNotebooks(fs.Args())
+	if err != nil {
+		return err
+	}
+	chapters, err := loadBookChapters(*tocPath, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var pages []*sitePage
+	usedFiles := map[string]bool{}
+	for _, path := range paths {
+		nb, err := LoadNotebookFile(path)
+		if err != nil {
+			return err
+		}
+		page := newSitePage(path, nb)
+		page.File = uniqueFileName(path, ".html", usedFiles)
+		pages = append(pages, page)
+	}
+
+	if err := writeSite(*outputDir, *siteTitle, pages, chapters); err != nil {
+		return err
+	}
+	fmt.Printf("✅ 成功匯出: %d 個 notebooks -> %s\n", len(pages), filepath.Join(*outputDir, "index.html"))
+	return nil
+}
+
+// collectNotebooks 展開輸入路徑：目錄會遞迴尋找 .ipynb（略過設定檔 exclude 中的目錄），結果依章節排序
+func collectNotebooks(inputs []string) ([]string, error) {
+	return collectFiles(inputs, func(_ Config, files []string) []string {
+		var notebooks []string
+		for _, path := range files {
+			if strings.HasSuffix(path, ".ipynb") {
+				notebooks = append(notebooks, path)
+			}
+		}
+		return notebooks
+	})
+}
+
+// loadBookChapters 讀取全書目錄中有章號的章；沒有指定也找不到目錄時回傳 nil
+func loadBookChapters(tocPath, inputPath string) ([]outline.Chapter, error) {
+	if tocPath == "" {
+		found, err := findFileUpward(inputPath, outline.FileName)
+		if err != nil || found == "" {
+			return nil, err
+		}
+		tocPath = found
+	}
+	book, err := outline.ParseFile(tocPath)
+	if err != nil {
+		return nil, err
+	}
+	return book.Numbered(), nil
+}
+
+// uniqueFileName 以輸入檔的檔名產生輸出檔名；重複時加上目錄名稱
+func uniqueFileName(path, ext string, used map[string]bool) string {
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	name := base + ext
+	if used[name] {
+		dir := filepath.Base(filepath.Dir(path))
+		name = dir + "-" + base + ext
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s-%s-%d%s", dir, base, n, ext)
+		}
+	}
+	used[name] = true
+	return name
+}
+
+// newSitePage 轉換 notebook 並決定標題：第一個一級標題，沒有時使用檔名
+func newSitePage(path string, nb *Notebook) *sitePage {
+	body, headings := renderNotebookHTML(nb, htmlOptions{})
+	page := &sitePage{
+		Source:   path,
+		Title:    strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		Chapter:  chapterOfPath(path),
+		Body:     body,
+		Headings: headings,
+		Text:     notebookText(nb),
+	}
+	for _, heading := range headings {
+		if heading.Level == 1 {
+			page.Title = heading.Text
+			break
+		}
+	}
+	return page
+}
+
+// htmlOptions renderNotebookHTML 的選項
+type htmlOptions struct {
+	// XHTML 輸出合法的 XHTML（EPUB 使用），輸出中的 text/html 會改用純文字
+	XHTML bool
+	// Image 回傳 attachment 或圖片輸出（base64，SVG 為純文字）的網址；nil 時內嵌為 data URI
+	Image func(mime, data string) string
+	// LocalImage 轉換 Markdown 中其他圖片的網址；nil 時保持不變
+	LocalImage func(src string) string
+}
+
+// renderNotebookHTML 將 notebook 的 cells 轉成 HTML 片段，回傳內容與所有標題
+func renderNotebookHTML(nb *Notebook, opts htmlOptions) (string, []Heading) {
+	renderer := &MarkdownRenderer{XHTML: opts.XHTML}
+	var b strings.Builder
+	for _, cell := range nb.Cells {
+		b.WriteString(renderCellHTML(renderer, cell, opts))
+	}
+	return b.String(), renderer.Headings
+}
+
+// renderCellHTML 轉換單一 cell；標題會累積在 renderer.Headings
+func renderCellHTML(renderer *MarkdownRenderer, cell Cell, opts htmlOptions) string {
+	if opts.Image == nil {
+		opts.Image = dataURI
+	}
+	var b strings.Builder
+
+	switch cell.CellType {
+	case "markdown":
+		renderer.ResolveImage = func(src string) string {
+			name, ok := strings.CutPrefix(src, "attachment:")
+			if !ok {
+				if opts.LocalImage != nil {
+					return opts.LocalImage(src)
+				}
+				return src
+			}
+			if mime, data, ok := attachmentData(cell, name); ok {
+				return opts.Image(mime, data)
+			}
+			return src
+		}
+		headings := len(renderer.Headings)
+		body := renderer.Render(cell.Text())
+		if opts.XHTML && checkXHTML(body) != nil {
+			// 筆記中手寫的 HTML 不一定成對（例如 <span/>），改以文字顯示才是合法的 XHTML
+			renderer.Headings = renderer.Headings[:headings]
+			renderer.EscapeHTML = true
+			body = renderer.Render(cell.Text())
+			renderer.EscapeHTML = false
+		}
+		b.WriteString(`<div class="cell markdown">` + "\n")
+		b.WriteString(body)
+		b.WriteString("</div>\n")
+
+	case "code":
+		b.WriteString(`<div class="cell code">` + "\n")
+		b.WriteString(renderCodeBlock("go", cell.Text()))
+		if outputs := CellOutputs(cell); len(outputs) > 0 {
+			b.WriteString(`<div class="output">` + "\n")
+			for _, output := range outputs {
+				b.WriteString(renderOutputHTML(output, opts))
+			}
+			b.WriteString("</div>\n")
+		}
+		b.WriteString("</div>\n")
+	}
+
+	return b.String()
+}
+
+// renderOutputHTML 轉換單一輸出
+func renderOutputHTML(output Output, opts htmlOptions) string {
+	switch output.Type {
+	case "stream":
+		return fmt.Sprintf("<pre class=\"%s\">%s</pre>\n", html.EscapeString(output.Name), html.EscapeString(output.Text))
+	case "error":
+		return fmt.Sprintf("<pre class=\"error\">%s</pre>\n", html.EscapeString(output.Text))
+	}
+
+	preferred := append([]string{}, ImageMIMEs...)
+	if !opts.XHTML {
+		preferred = append([]string{"text/html"}, preferred...)
+	}
+	preferred = append(preferred, "text/markdown", "text/plain")
+
+	mime := output.PreferredMIME(preferred...)
+	data := output.Data[mime]
+	switch {
+	case mime == "text/html":
+		return data + "\n"
+	case mime == "text/markdown":
+		renderer := &MarkdownRenderer{XHTML: opts.XHTML}
+		return renderer.Render(data)
+	case mime == "text/plain":
+		return fmt.Sprintf("<pre>%s</pre>\n", html.EscapeString(data))
+	case strings.HasPrefix(mime, "image/"):
+		end := ">"
+		if opts.XHTML {
+			end = "/>"
+		}
+		return fmt.Sprintf("<img src=\"%s\" alt=\"output\"%s\n", html.EscapeString(opts.Image(mime, data)), end)
+	}
+	return ""
+}
+
+// checkXHTML 確認片段是格式正確的 XML；閱讀器遇到不合法的 XHTML 常會整章無法顯示
+func checkXHTML(fragment string) error {
+	decoder := xml.NewDecoder(strings.NewReader("<div>" + fragment + "</div>"))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// dataURI 將圖片內嵌為 data URI；nbformat 中 SVG 是純文字，要先轉成 base64
+func dataURI(mime, data string) string {
+	if mime == "image/svg+xml" {
+		data = base64.StdEncoding.EncodeToString([]byte(data))
+	}
+	return "data:" + mime + ";base64," + strings.Join(strings.Fields(data), "")
+}
+
+// notebookText 回傳 notebook 的純文字（搜尋索引使用）
+func notebookText(nb *Notebook) string {
+	var parts []string
+	for _, cell := range nb.Cells {
+		text := cell.Text()
+		if cell.CellType == "markdown" {
+			text = plainText(RenderMarkdown(text))
+		}
+		parts = append(parts, strings.Join(strings.Fields(text), " "))
+	}
+	return strings.Join(parts, " ")
+}
+
+// writeSite 輸出所有頁面、index.html、搜尋索引與共用檔案
+func writeSite(outputDir, siteTitle string, pages []*sitePage, chapters []outline.Chapter) error {
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", outputDir, err)
+	}
+
+	layout, err := template.ParseFS(siteAssets, "site/page.html")
+	if err != nil {
+		return err
+	}
+	render := func(file string, data sitePageData) error {
+		var b strings.Builder
+		if err := layout.Execute(&b, data); err != nil {
+			return fmt.Errorf("failed to render %s: %w", file, err)
+		}
+		return writeFileAtomic(filepath.Join(outputDir, file), []byte(b.String()), false)
+	}
+
+	for i, page := range pages {
+		data := sitePageData{
+			SiteTitle: siteTitle,
+			Title:     page.Title,
+			Nav:       siteNav(pages, chapters, page),
+			Body:      template.HTML(page.Body),
+		}
+		if i > 0 {
+			data.Prev = pages[i-1]
+		}
+		if i+1 < len(pages) {
+			data.Next = pages[i+1]
+		}
+		if err := render(page.File, data); err != nil {
+			return err
+		}
+	}
+
+	index := sitePageData{
+		SiteTitle: siteTitle,
+		Title:     "目錄",
+		Nav:       siteNav(pages, chapters, nil),
+		Body:      template.HTML(siteIndexBody(siteTitle, pages, chapters)),
+	}
+	if len(pages) > 0 {
+		index.Next = pages[0]
+	}
+	if err := render("index.html", index); err != nil {
+		return err
+	}
+
+	searchIndex, err := siteSearchIndex(pages)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(outputDir, "search-index.js"), searchIndex, false); err != nil {
+		return err
+	}
+
+	for _, asset := range []string{"style.css", "search.js"} {
+		data, err := siteAssets.ReadFile("site/" + asset)
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(filepath.Join(outputDir, asset), data, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// siteNav 依全書目錄建立側邊欄；目錄中沒有的章節與無法判斷章節的頁面放在最後
+func siteNav(pages []*sitePage, chapters []outline.Chapter, current *sitePage) []siteNavChapter {
+	link := func(page *sitePage) siteNavLink {
+		return siteNavLink{Title: page.Title, File: page.File, Current: page == current}
+	}
+
+	var nav []siteNavChapter
+	listed := map[int]bool{}
+	for _, chapter := range chapters {
+		entry := siteNavChapter{Label: chapter.Label()}
+		for _, page := range pages {
+			if page.Chapter == chapter.Number {
+				entry.Pages = append(entry.Pages, link(page))
+			}
+		}
+		listed[chapter.Number] = true
+		nav = append(nav, entry)
+	}
+
+	var others siteNavChapter
+	extra := map[int]int{} // 目錄中沒有的章節 -> nav 中的位置
+	for _, page := range pages {
+		switch {
+		case page.Chapter == 0:
+			others.Pages = append(others.Pages, link(page))
+		case !listed[page.Chapter]:
+			i, ok := extra[page.Chapter]
+			if !ok {
+				i = len(nav)
+				extra[page.Chapter] = i
+				nav = append(nav, siteNavChapter{Label: fmt.Sprintf("第 %d 章", page.Chapter)})
+			}
+			nav[i].Pages = append(nav[i].Pages, link(page))
+		}
+	}
+	if len(others.Pages) > 0 {
+		others.Label = "其他"
+		nav = append(nav, others)
+	}
+	return nav
+}
+
+// siteIndexBody 首頁內容：各章與其筆記的清單
+func siteIndexBody(siteTitle string, pages []*sitePage, chapters []outline.Chapter) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<h1>%s</h1>\n<ul>\n", html.EscapeString(siteTitle))
+	for _, chapter := range siteNav(pages, chapters, nil) {
+		if len(chapter.Pages) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "<li>%s\n<ul>\n", html.EscapeString(chapter.Label))
+		for _, page := range chapter.Pages {
+			fmt.Fprintf(&b, "<li><a href=\"%s\">%s</a></li>\n", html.EscapeString(page.File), html.EscapeString(page.Title))
+		}
+		b.WriteString("</ul>\n</li>\n")
+	}
+	b.WriteString("</ul>\n")
+	return b.String()
+}
+
+// siteSearchIndex 產生 search-index.js；以 <script> 載入，直接開啟本機檔案也能搜尋
+func siteSearchIndex(pages []*sitePage) ([]byte, error) {
+	type headingEntry struct {
+		Text string `json:"text"`
+		ID   string `json:"id"`
+	}
+	type pageEntry struct {
+		Title    string         `json:"title"`
+		URL      string         `json:"url"`
+		Headings []headingEntry `json:"headings"`
+		Text     string         `json:"text"`
+	}
+
+	entries := []pageEntry{}
+	for _, page := range pages {
+		entry := pageEntry{Title: page.Title, URL: page.File, Headings: []headingEntry{}, Text: page.Text}
+		for _, heading := range page.Headings {
+			entry.Headings = append(entry.Headings, headingEntry{Text: heading.Text, ID: heading.ID})
+		}
+		entries = append(entries, entry)
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build search index: %w", err)
+	}
+	return []byte("window.SEARCH_INDEX = " + string(data) + ";\n"), nil
+}
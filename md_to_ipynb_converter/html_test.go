@@ -0,0 +1,102 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hank/learning-go/ch9/converter/outline"
+)
+
+func TestRenderNotebookHTML(t *testing.T) {
+	nb := NewNotebook()
+	nb.AddMarkdownCell("intro", "# 標題\n\n![圖](attachment:dot.png)")
+	nb.Cells[0].Attachments = map[string]map[string]MultilineString{"dot.png": {"image/png": {"iVBORw0KGgo="}}}
+	nb.AddCodeCell("code", `fmt.Println("<hi>")`)
+	nb.Cells[1].Outputs = []any{
+		map[string]any{"output_type": "stream", "name": "stdout", "text": []any{"<hi>\n"}},
+		map[string]any{"output_type": "error", "ename": "ERROR", "evalue": "boom", "traceback": []any{"\x1b[31mboom\x1b[0m", "line 2"}},
+		map[string]any{"output_type": "display_data", "data": map[string]any{"text/html": "<b>html</b>", "text/plain": "plain"}},
+	}
+
+	body, headings := renderNotebookHTML(nb, htmlOptions{})
+	for _, want := range []string{
+		`<img src="data:image/png;base64,iVBORw0KGgo=" alt="圖">`,
+		`<span class="str">&#34;&lt;hi&gt;&#34;</span>`,
+		"<pre class=\"stdout\">&lt;hi&gt;\n</pre>",
+		"<pre class=\"error\">boom\nline 2</pre>",
+		"<b>html</b>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Body should contain %q, got:\n%s", want, body)
+		}
+	}
+	if len(headings) != 1 || headings[0].Text != "標題" {
+		t.Errorf("Unexpected headings: %+v", headings)
+	}
+
+	// XHTML 模式不直接輸出 text/html
+	xhtml, _ := renderNotebookHTML(nb, htmlOptions{XHTML: true})
+	if strings.Contains(xhtml, "<b>html</b>") || !strings.Contains(xhtml, "<pre>plain</pre>") {
+		t.Errorf("XHTML should fall back to text/plain, got:\n%s", xhtml)
+	}
+}
+
+func TestExportHTML_Site(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, outline.FileName), "## 第一章 設定環境 .... 1\n## 第二章 基本型態 .... 17\n## 第三章 複合型態 .... 35\n")
+	for _, path := range []string{"ch1/ch1_note.ipynb", "ch2/ch2_types.ipynb", "考題/go_exam.ipynb"} {
+		nb := NewNotebook()
+		nb.AddMarkdownCell("title", "# "+strings.TrimSuffix(filepath.Base(path), ".ipynb")+"\n\n## 何時該使用並行")
+		data, err := nb.ToJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeTestFile(t, filepath.Join(root, path), string(data))
+	}
+
+	output := filepath.Join(root, "site")
+	if err := run([]string{"export", "html", "-o", output, root}); err != nil {
+		t.Fatalf("export html failed: %v", err)
+	}
+
+	for _, name := range []string{"index.html", "ch1_note.html", "ch2_types.html", "go_exam.html", "style.css", "search.js", "search-index.js"} {
+		if _, err := os.Stat(filepath.Join(output, name)); err != nil {
+			t.Errorf("Missing %s: %v", name, err)
+		}
+	}
+
+	page := readFile(t, filepath.Join(output, "ch2_types.html"))
+	for _, want := range []string{
+		"第一章 設定環境",
+		`<li class="empty">`, // 第三章沒有筆記
+		`<a href="ch2_types.html" class="current" aria-current="page">ch2_types</a>`,
+		`<a class="prev" href="ch1_note.html">← ch1_note</a>`,
+		`<a class="next" href="go_exam.html">go_exam →</a>`,
+		"其他",
+		`<h2 id="何時該使用並行">`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Page should contain %q", want)
+		}
+	}
+	if strings.Contains(page, "http://") || strings.Contains(page, "https://") {
+		t.Error("Pages should not reference external resources")
+	}
+
+	index := readFile(t, filepath.Join(output, "search-index.js"))
+	if !strings.HasPrefix(index, "window.SEARCH_INDEX = [") || !strings.Contains(index, `"id":"何時該使用並行"`) {
+		t.Errorf("Unexpected search index: %s", index)
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,449 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 面試考題的種類，寫在 metadata["question"].kind
+const (
+	kindExercise = "exercise"
+	kindQuestion = "question"
+)
+
+// 面試考題的題目角色，寫在 metadata["question"].role
+const (
+	// roleExercise 練習：code cell 中以註解寫出的題目，直接在 cell 中作答
+	roleExercise = "exercise"
+	// rolePrompt 題目：markdown 的標題與內容
+	rolePrompt = "prompt"
+	// roleAnswerSlot 題目之後留給作答的空白 code cell
+	roleAnswerSlot = "answer_slot"
+	// roleAnswer 解答 notebook 中的解答
+	roleAnswer = "answer"
+)
+
+// difficulties 題目難度
+var difficulties = []string{"easy", "medium", "hard"}
+
+// h1Regex notebook 的 # 標題
+var h1Regex = regexp.MustCompile(`(?m)^#\s+(.+?)\s*$`)
+
+// QuestionMeta 練習或題目的 metadata，寫在 cell metadata 的 "question"
+type QuestionMeta struct {
+	ID string `json:"id"`
+	// Kind exercise（練習）或 question（題目）
+	Kind       string   `json:"kind"`
+	Chapter    int      `json:"chapter,omitempty"`
+	Number     int      `json:"number"`
+	Title      string   `json:"title,omitempty"`
+	Difficulty string   `json:"difficulty,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Role       string   `json:"role"`
+	// Template 轉換器產生的作答 cell 內容的雜湊；與目前內容不同表示已經作答，重新轉換時保留
+	Template string `json:"template,omitempty"`
+	// Tests TEST 區塊中的 Go 測試，只寫在作答的 cell，grade 以此批改
+	Tests string `json:"tests,omitempty"`
+}
+
+// Heading 題目或練習的標題，例如「題目 3：零值」
+func (m QuestionMeta) Heading() string {
+	label := "題目"
+	if m.Kind == kindExercise {
+		label = "練習"
+	}
+	if m.Title == "" {
+		return fmt.Sprintf("%s %d", label, m.Number)
+	}
+	return fmt.Sprintf("%s %d：%s", label, m.Number, m.Title)
+}
+
+// questionMetaOf 讀取 cell 的題目 metadata；從檔案讀入的 notebook 是 map，產生中的是 QuestionMeta
+func questionMetaOf(cell Cell) (QuestionMeta, bool) {
+	var meta QuestionMeta
+	value, ok := cell.Metadata["question"]
+	if !ok {
+		return meta, false
+	}
+	data, err := json.Marshal(value)
+	if err != nil || json.Unmarshal(data, &meta) != nil || meta.ID == "" {
+		return meta, false
+	}
+	return meta, true
+}
+
+// contentHash 作答 cell 內容的雜湊，比較時忽略前後空白
+func contentHash(content string) string {
+	sum := sha1.Sum([]byte(strings.TrimSpace(content)))
+	return hex.EncodeToString(sum[:4])
+}
+
+// interviewState 解析面試考題標記時的狀態
+type interviewState struct {
+	exercises int
+	questions int
+	// partsAdded 已經插入的「## 練習」「## 題目」
+	partsAdded map[string]bool
+
+	// block 目前在 QUESTION 或 EXERCISE 中的子區塊（ANSWER 或 TEST），blockStart 為其開始行
+	block      string
+	blockStart int
+	content    strings.Builder
+	// blocks 目前題目已讀到的子區塊內容
+	blocks map[string]string
+
+	// answerKey 解答 notebook 的 cells
+	answerKey []Cell
+}
+
+// subBlockMarker 處理題目中的子區塊標記（ANSWER、TEST）；不是子區塊標記時 handled 為 false
+func (p *Parser) subBlockMarker(marker string, currentType CellType) (handled bool, err error) {
+	state := &p.interview
+	lineNo := p.lineNo + 1
+	switch marker {
+	case "ANSWER", "TEST":
+		if currentType != QuestionCell && currentType != ExerciseCell {
+			return true, fmt.Errorf("line %d: %s must be inside QUESTION or EXERCISE", lineNo, marker)
+		}
+		if state.block != "" {
+			return true, fmt.Errorf("line %d: %s starting at line %d is missing its END marker", lineNo, state.block, state.blockStart+1)
+		}
+		state.block, state.blockStart = marker, p.lineNo
+		state.content.Reset()
+		return true, nil
+	case "END_ANSWER", "END_TEST":
+		name := strings.TrimPrefix(marker, "END_")
+		if state.block != name {
+			return true, fmt.Errorf("line %d: unexpected %s", lineNo, marker)
+		}
+		if state.blocks == nil {
+			state.blocks = map[string]string{}
+		}
+		// 可以有多個 TEST 區塊
+		if previous, ok := state.blocks[name]; ok {
+			state.blocks[name] = previous + "\n\n" + state.content.String()
+		} else {
+			state.blocks[name] = state.content.String()
+		}
+		state.block = ""
+		return true, nil
+	}
+	if state.block != "" {
+		return true, fmt.Errorf("line %d: %s starting at line %d is missing its END marker", lineNo, state.block, state.blockStart+1)
+	}
+	return false, nil
+}
+
+// appendBlockLine 累積子區塊內容；不在子區塊中時回傳 false
+func (p *Parser) appendBlockLine(line string) bool {
+	state := &p.interview
+	if state.block == "" {
+		return false
+	}
+	if state.content.Len() > 0 {
+		state.content.WriteString("\n")
+	}
+	state.content.WriteString(line)
+	return true
+}
+
+// saveInterview 將 EXERCISE 或 QUESTION 轉成「練習」「題目」的版面：
+// 練習是一個 code cell；題目是 markdown 的標題與內容，加上一個空白的作答 code cell。
+// ANSWER 區塊不會出現在考題中，而是放進解答 notebook；TEST 區塊寫在作答 cell 的 metadata，不會顯示
+func (p *Parser) saveInterview(notebook *Notebook, cellType CellType, content string, attrs map[string]string, span CellSpan) error {
+	state := &p.interview
+	blocks := state.blocks
+	state.blocks = nil
+
+	meta, err := p.questionMeta(cellType, attrs, span)
+	if err != nil {
+		return err
+	}
+	for _, id := range []string{meta.ID, meta.ID + "-answer"} {
+		if p.usedIDs[id] {
+			return fmt.Errorf("duplicate cell id: %s", id)
+		}
+	}
+
+	part := "題目"
+	if meta.Kind == kindExercise {
+		part = "練習"
+	}
+	if state.partsAdded == nil {
+		state.partsAdded = map[string]bool{}
+	}
+	if !state.partsAdded[part] {
+		state.partsAdded[part] = true
+		p.addInterviewCell(notebook, Cell{CellType: "markdown", ID: partCellID(part), Source: splitLines("## " + part)}, span)
+	}
+
+	if tests, ok := blocks["TEST"]; ok {
+		meta.Tests = goFenceContent(tests)
+	}
+	content = strings.Trim(content, "\n")
+	for _, cell := range interviewCells(meta, content) {
+		p.addInterviewCell(notebook, cell, span)
+	}
+	if answer, ok := blocks["ANSWER"]; ok {
+		state.answerKey = append(state.answerKey, answerKeyCells(meta, content, answer)...)
+	}
+	return nil
+}
+
+// interviewCells 練習或題目在考題 notebook 中的 cells：
+// 練習是一個 code cell；題目是 markdown 的標題與內容，加上一個空白的作答 code cell
+func interviewCells(meta QuestionMeta, content string) []Cell {
+	newCell := func(cellType, id, text string, meta QuestionMeta) Cell {
+		cell := Cell{CellType: cellType, ID: id, Source: splitLines(text), Metadata: CellMetadata{"question": meta}}
+		if cellType == "code" {
+			cell.Outputs = []any{}
+		}
+		return cell
+	}
+
+	if meta.Kind == kindExercise {
+		code := content
+		if meta.Title != "" {
+			code = "// " + meta.Heading() + "\n" + content
+		}
+		exercise := meta
+		exercise.Role = roleExercise
+		exercise.Template = contentHash(code)
+		return []Cell{newCell("code", meta.ID, code, exercise)}
+	}
+
+	promptMeta := meta
+	promptMeta.Role, promptMeta.Tests = rolePrompt, ""
+	slot := fmt.Sprintf("// 請在這裡回答題目%d\n", meta.Number)
+	slotMeta := meta
+	slotMeta.Role = roleAnswerSlot
+	slotMeta.Template = contentHash(slot)
+	return []Cell{
+		newCell("markdown", meta.ID, interviewPrompt(meta, content), promptMeta),
+		newCell("code", meta.ID+"-answer", slot, slotMeta),
+	}
+}
+
+// interviewPrompt 解答 notebook 中的題目：練習只有標題，題目是標題與內容
+func interviewPrompt(meta QuestionMeta, content string) string {
+	prompt := "### " + meta.Heading()
+	if meta.Kind == kindQuestion && content != "" {
+		prompt += "\n\n" + content
+	}
+	return prompt
+}
+
+// answerKeyCells 解答 notebook 中一題的 cells：題目，接著是 ANSWER 區塊拆成的解答
+func answerKeyCells(meta QuestionMeta, content, answer string) []Cell {
+	meta.Tests = ""
+	promptMeta := meta
+	promptMeta.Role = rolePrompt
+	cells := []Cell{{CellType: "markdown", ID: meta.ID, Metadata: CellMetadata{"question": promptMeta}, Source: splitLines(interviewPrompt(meta, content))}}
+	for i, cell := range splitAnswer(answer) {
+		cell.ID = meta.ID + "-solution"
+		if i > 0 {
+			cell.ID = fmt.Sprintf("%s-solution-%d", meta.ID, i+1)
+		}
+		answerMeta := meta
+		answerMeta.Role = roleAnswer
+		cell.Metadata = CellMetadata{"question": answerMeta}
+		cells = append(cells, cell)
+	}
+	return cells
+}
+
+// questionMeta 依標記屬性產生題目 metadata；沒有 id 時以章節與編號產生，例如 ch5-q3
+func (p *Parser) questionMeta(cellType CellType, attrs map[string]string, span CellSpan) (QuestionMeta, error) {
+	state := &p.interview
+	meta := QuestionMeta{Kind: kindQuestion, Chapter: p.chapter, Title: attrs["title"], Difficulty: attrs["difficulty"]}
+	prefix := "q"
+	if cellType == ExerciseCell {
+		state.exercises++
+		meta.Kind, meta.Number, prefix = kindExercise, state.exercises, "ex"
+	} else {
+		state.questions++
+		meta.Number = state.questions
+	}
+
+	if value, ok := attrs["chapter"]; ok {
+		chapter, err := strconv.Atoi(value)
+		if err != nil || chapter < 0 {
+			return meta, fmt.Errorf("line %d: invalid chapter %q", span.Start+1, value)
+		}
+		meta.Chapter = chapter
+	}
+	if meta.Difficulty != "" && !contains(difficulties, meta.Difficulty) {
+		return meta, fmt.Errorf("line %d: unknown difficulty %q (want %s)", span.Start+1, meta.Difficulty, strings.Join(difficulties, ", "))
+	}
+	meta.Tags = splitList(attrs["tags"])
+
+	meta.ID = attrs["id"]
+	if meta.ID == "" {
+		meta.ID = fmt.Sprintf("%s%d", prefix, meta.Number)
+		if meta.Chapter > 0 {
+			meta.ID = fmt.Sprintf("ch%d-%s", meta.Chapter, meta.ID)
+		}
+	}
+	return meta, nil
+}
+
+// addInterviewCell 加入練習或題目產生的 cell；ID 是明確指定的，--update 時以 ID 比對
+func (p *Parser) addInterviewCell(notebook *Notebook, cell Cell, span CellSpan) {
+	if cell.Metadata == nil {
+		cell.Metadata = CellMetadata{}
+	}
+	if cell.CellType == "code" {
+		cell.Outputs = []any{}
+	}
+	cell.explicitID = true
+	p.usedIDs[cell.ID] = true
+	p.cellID++
+	notebook.Cells = append(notebook.Cells, cell)
+	p.spans = append(p.spans, span)
+}
+
+// partCellID 「## 練習」「## 題目」cell 的 ID
+func partCellID(part string) string {
+	if part == "練習" {
+		return "exercises"
+	}
+	return "questions"
+}
+
+// splitAnswer 將 ANSWER 區塊拆成 cells：```go 區塊是 code cell，其餘文字是 markdown cell
+func splitAnswer(answer string) []Cell {
+	var cells []Cell
+	var current []string
+	inCode := false
+	flush := func(cellType string) {
+		text := strings.Trim(strings.Join(current, "\n"), "\n")
+		current = nil
+		if strings.TrimSpace(text) == "" {
+			return
+		}
+		cell := Cell{CellType: cellType, Source: splitLines(text)}
+		if cellType == "code" {
+			cell.Outputs = []any{}
+		}
+		cells = append(cells, cell)
+	}
+	for _, line := range strings.Split(answer, "\n") {
+		switch {
+		case !inCode && strings.TrimSpace(line) == "```go":
+			flush("markdown")
+			inCode = true
+		case inCode && strings.TrimSpace(line) == "```":
+			flush("code")
+			inCode = false
+		default:
+			current = append(current, line)
+		}
+	}
+	if inCode {
+		flush("code")
+	} else {
+		flush("markdown")
+	}
+	return cells
+}
+
+// goFenceContent 取出 ```go 區塊中的程式；沒有 code fence 時回傳原本的內容
+func goFenceContent(content string) string {
+	var code []string
+	for _, cell := range splitAnswer(content) {
+		if cell.CellType == "code" {
+			code = append(code, cell.Text())
+		}
+	}
+	if len(code) == 0 {
+		return strings.TrimSpace(content)
+	}
+	return strings.Join(code, "\n\n")
+}
+
+// AnswerKey 回傳由 ANSWER 區塊產生的解答 notebook；沒有任何解答時回傳 nil
+func (p *Parser) AnswerKey(questions *Notebook) (*Notebook, error) {
+	if len(p.interview.answerKey) == 0 {
+		return nil, nil
+	}
+
+	title := "解答"
+	for _, cell := range questions.Cells {
+		if match := h1Regex.FindStringSubmatch(cell.Text()); cell.CellType == "markdown" && match != nil {
+			title = match[1] + " - 解答"
+			break
+		}
+	}
+
+	key := newAnswerKey(title, p.interview.answerKey)
+	if err := key.SetKernel(p.Config.Kernel); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// newAnswerKey 產生解答 notebook：標題之後依序放入解答 cells，並在第一個練習與題目之前插入「## 練習」「## 題目」
+func newAnswerKey(title string, cells []Cell) *Notebook {
+	key := NewNotebook()
+	key.AddMarkdownCell("answer-key", "# "+title)
+	parts := map[string]bool{}
+	for _, cell := range cells {
+		meta, _ := questionMetaOf(cell)
+		part := "題目"
+		if meta.Kind == kindExercise {
+			part = "練習"
+		}
+		if !parts[part] {
+			parts[part] = true
+			key.AddMarkdownCell(partCellID(part), "## "+part)
+		}
+		key.Cells = append(key.Cells, cell)
+	}
+	return key
+}
+
+// answerKeyPath 解答 notebook 的路徑，例如 ch5_interview_questions.ipynb -> ch5_interview_answer_key.ipynb
+func answerKeyPath(questionsPath string) string {
+	stem := strings.TrimSuffix(questionsPath, ".ipynb")
+	return strings.TrimSuffix(stem, "_questions") + "_answer_key.ipynb"
+}
+
+// preserveAnswers 重新轉換考題時保留已經作答的 cell：
+// 練習與作答 cell 的內容與產生時的雜湊不同，表示已經寫了答案，沿用既有 notebook 中的內容與輸出
+func preserveAnswers(existing, generated *Notebook) int {
+	written := map[string]Cell{}
+	for _, cell := range existing.Cells {
+		meta, ok := questionMetaOf(cell)
+		if ok && (meta.Role == roleExercise || meta.Role == roleAnswerSlot) && contentHash(cell.Text()) != meta.Template {
+			written[cell.ID] = cell
+		}
+	}
+
+	preserved := 0
+	for i := range generated.Cells {
+		cell := &generated.Cells[i]
+		old, ok := written[cell.ID]
+		if !ok || cell.CellType != "code" {
+			continue
+		}
+		cell.Source = old.Source
+		cell.Outputs = old.Outputs
+		cell.ExecutionCount = old.ExecutionCount
+		preserved++
+	}
+	return preserved
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const interviewSource = `<!-- MARKDOWN_CELL -->
+# 第五章 函式 - 面試考題
+<!-- END_MARKDOWN_CELL -->
+
+<!-- EXERCISE title="可變參數" difficulty="easy" tags="variadic" -->
+` + "```go" + `
+// 請寫一個函式 sum(nums ...int) int
+` + "```" + `
+<!-- ANSWER -->
+` + "```go" + `
+func sum(nums ...int) int { return 0 }
+` + "```" + `
+<!-- END_ANSWER -->
+<!-- END_EXERCISE -->
+
+<!-- QUESTION title="閉包" id="closure-counter" difficulty="medium" tags="closure, func" -->
+**問題：** 請寫一個回傳計數器的函式。
+<!-- ANSWER -->
+每次呼叫都共用同一個變數：
+
+` + "```go" + `
+func counter() func() int
+` + "```" + `
+<!-- END_ANSWER -->
+<!-- END_QUESTION -->
+
+<!-- QUESTION title="defer 的順序" -->
+多個 defer 以什麼順序執行？
+<!-- END_QUESTION -->
+`
+
+func TestParseInterview(t *testing.T) {
+	parser := NewFileParser(strings.NewReader(interviewSource), "ch5/ch5_interview_questions_source.md", DefaultConfig())
+	parser.Config.Strict = true
+	nb, err := parser.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, cell := range nb.Cells {
+		meta, _ := questionMetaOf(cell)
+		got = append(got, fmt.Sprintf("%s %s %s %q", cell.CellType, cell.ID, meta.Role, firstLine(cell.Text())))
+	}
+	want := []string{
+		`markdown cell-0  "# 第五章 函式 - 面試考題"`,
+		`markdown exercises  "## 練習"`,
+		`code ch5-ex1 exercise "// 練習 1：可變參數"`,
+		`markdown questions  "## 題目"`,
+		`markdown closure-counter prompt "### 題目 1：閉包"`,
+		`code closure-counter-answer answer_slot "// 請在這裡回答題目1"`,
+		`markdown ch5-q2 prompt "### 題目 2：defer 的順序"`,
+		`code ch5-q2-answer answer_slot "// 請在這裡回答題目2"`,
+	}
+	assertStrings(t, "cells", got, want)
+	if len(parser.Spans()) != len(nb.Cells) {
+		t.Errorf("Expected a span for every cell, got %d", len(parser.Spans()))
+	}
+
+	meta, _ := questionMetaOf(nb.Cells[4])
+	if meta.Chapter != 5 || meta.Difficulty != "medium" || strings.Join(meta.Tags, ",") != "closure,func" || meta.Kind != kindQuestion {
+		t.Errorf("Unexpected question metadata: %+v", meta)
+	}
+	if strings.Contains(nb.Cells[4].Text(), "counter()") {
+		t.Error("ANSWER must not appear in the questions notebook")
+	}
+
+	key, err := parser.AnswerKey(nb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = nil
+	for _, cell := range key.Cells {
+		got = append(got, fmt.Sprintf("%s %s %q", cell.CellType, cell.ID, firstLine(cell.Text())))
+	}
+	want = []string{
+		`markdown answer-key "# 第五章 函式 - 面試考題 - 解答"`,
+		`markdown exercises "## 練習"`,
+		`markdown ch5-ex1 "### 練習 1：可變參數"`,
+		`code ch5-ex1-solution "func sum(nums ...int) int { return 0 }"`,
+		`markdown questions "## 題目"`,
+		`markdown closure-counter "### 題目 1：閉包"`,
+		`markdown closure-counter-solution "每次呼叫都共用同一個變數："`,
+		`code closure-counter-solution-2 "func counter() func() int"`,
+	}
+	assertStrings(t, "answer key", got, want)
+}
+
+func TestParseInterview_Errors(t *testing.T) {
+	tests := map[string]string{
+		"answer outside question": "<!-- MARKDOWN_CELL -->\n# x\n<!-- ANSWER -->\n<!-- END_ANSWER -->\n<!-- END_MARKDOWN_CELL -->",
+		"unclosed answer":         "<!-- QUESTION -->\nq\n<!-- ANSWER -->\na\n<!-- END_QUESTION -->",
+		"unknown difficulty":      "<!-- QUESTION difficulty=\"trivial\" -->\nq\n<!-- END_QUESTION -->",
+		"duplicate id":            "<!-- QUESTION id=\"a\" -->\nq\n<!-- END_QUESTION -->\n<!-- QUESTION id=\"a\" -->\nq\n<!-- END_QUESTION -->",
+	}
+	for name, input := range tests {
+		if _, err := NewParser(strings.NewReader(input)).Parse(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestConvertInterview_PreservesAnswers(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "ch5", "ch5_interview_questions_source.md")
+	output := filepath.Join(dir, "ch5", "ch5_interview_questions.ipynb")
+	writeTestFile(t, source, interviewSource)
+	if err := convert(source, output); err != nil {
+		t.Fatal(err)
+	}
+	if key := answerKeyPath(output); key != filepath.Join(dir, "ch5", "ch5_interview_answer_key.ipynb") || !strings.Contains(readFile(t, key), "closure-counter-solution") {
+		t.Fatalf("Expected the answer key next to the questions: %s", key)
+	}
+
+	// 作答之後修改題目並重新轉換：答案保留，未作答的 cell 跟著來源更新
+	nb, err := ReadNotebook(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nb.Cells[5].Source = splitLines("// 我的答案\nfunc counter() {}")
+	data, _ := nb.ToJSON()
+	writeTestFile(t, output, string(data))
+	writeTestFile(t, source, strings.Replace(strings.Replace(interviewSource, "請寫一個回傳計數器的函式", "請寫一個計數器", 1), "// 請寫一個函式 sum", "// 請實作 sum", 1))
+
+	if err := convert(source, output); err != nil {
+		t.Fatal(err)
+	}
+	nb, err = ReadNotebook(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := nb.Cells[5].Text(); got != "// 我的答案\nfunc counter() {}" {
+		t.Errorf("Written answer should be preserved, got %q", got)
+	}
+	if !strings.Contains(nb.Cells[2].Text(), "// 請實作 sum") || !strings.Contains(nb.Cells[4].Text(), "請寫一個計數器") {
+		t.Error("Unanswered cells should follow the source")
+	}
+	if err := run([]string{"convert", "--check", source, output}); err != nil {
+		t.Errorf("Answers should not make the notebook stale: %v", err)
+	}
+}
+
+func TestConvertInterview_CheckAnswerKey(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "ch5", "ch5_interview_questions_source.md")
+	output := filepath.Join(dir, "ch5", "ch5_interview_questions.ipynb")
+	writeTestFile(t, source, interviewSource)
+	if err := convert(source, output); err != nil {
+		t.Fatal(err)
+	}
+	if err := run([]string{"convert", "--check", source, output}); err != nil {
+		t.Fatalf("Fresh questions and answer key should pass: %v", err)
+	}
+
+	// 只改解答：考題不變，但解答 notebook 已經過期
+	keyPath := answerKeyPath(output)
+	before := readFile(t, keyPath)
+	writeTestFile(t, source, strings.Replace(interviewSource, "每次呼叫都共用同一個變數", "每次呼叫都共用外層的變數", 1))
+	if err := run([]string{"convert", "--check", source, output}); err == nil || !strings.Contains(err.Error(), "answer_key") {
+		t.Errorf("Expected a stale answer key, got %v", err)
+	}
+	if err := run([]string{"convert", "--dry-run", source, output}); err != nil {
+		t.Fatal(err)
+	}
+	if readFile(t, keyPath) != before {
+		t.Error("--dry-run must not write the answer key")
+	}
+}
@@ -0,0 +1,632 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// lint 規則，對應 .kiro/steering/product.md 的撰寫規範
+const (
+	// lintTitle 每個 notebook 以一個 # 標題開頭，之後不再出現 # 標題
+	lintTitle = "title"
+	// lintHeadingLevel 標題不跳級（## 之後不能直接是 ####）
+	lintHeadingLevel = "heading-level"
+	// lintSectionCode 每個 Section 是 markdown cell 加上 code cell
+	lintSectionCode = "section-code"
+	// lintCodeComments 每段範例程式都要有註解說明
+	lintCodeComments = "code-comments"
+	// lintPrintOutput 每個 fmt.Print* 都要以註解寫出輸出
+	lintPrintOutput = "print-output"
+	// lintPitfall 筆記要標示容易出錯的地方
+	lintPitfall = "pitfall"
+)
+
+var lintRules = []string{lintTitle, lintHeadingLevel, lintSectionCode, lintCodeComments, lintPrintOutput, lintPitfall}
+
+var (
+	// pitfallRegex 提醒讀者注意的寫法
+	pitfallRegex = regexp.MustCompile(`⚠️|注意|陷阱|容易出錯|常見錯誤|小心`)
+	// printFuncs 會輸出到標準輸出、需要寫出結果的 fmt 函式
+	printFuncs = map[string]bool{"Print": true, "Println": true, "Printf": true}
+)
+
+// LintIssue 一個違反撰寫規範的地方
+type LintIssue struct {
+	Rule string
+	// Cell cell 索引；-1 表示整個 notebook
+	Cell int
+	// Line cell 內容中的行（從 0 開始）
+	Line    int
+	Message string
+	Fix     *lintFix
+}
+
+// lintFix 自動修正：取代問題所在的那一行，或在 After 這個 cell 之後插入 code cell
+type lintFix struct {
+	Replace    string
+	InsertCode string
+	After      int
+}
+
+// LintNotebook 檢查 notebook 是否符合撰寫規範；note 為 true 時另外套用只針對章節筆記的規則
+func LintNotebook(nb *Notebook, note bool) []LintIssue {
+	issues := lintHeadings(nb)
+	if note {
+		issues = append(issues, lintSections(nb)...)
+		issues = append(issues, lintPitfalls(nb)...)
+	}
+	for i, cell := range nb.Cells {
+		if cell.CellType == "code" && strings.TrimSpace(cell.Text()) != "" {
+			issues = append(issues, lintCode(i, cell.Text())...)
+		}
+	}
+
+	sort.SliceStable(issues, func(a, b int) bool {
+		if issues[a].Cell != issues[b].Cell {
+			return issues[a].Cell < issues[b].Cell
+		}
+		return issues[a].Line < issues[b].Line
+	})
+	return issues
+}
+
+// lineHeading markdown cell 中的一個 ATX 標題
+type lineHeading struct {
+	Line  int
+	Level int
+	Text  string
+}
+
+// markdownHeadings 回傳 markdown 中的標題與所在行，略過 code fence 中的內容
+func markdownHeadings(text string) []lineHeading {
+	var headings []lineHeading
+	inFence := false
+	for i, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if match := atxHeadingRegex.FindStringSubmatch(line); match != nil {
+			headings = append(headings, lineHeading{Line: i, Level: len(match[1]), Text: match[2]})
+		}
+	}
+	return headings
+}
+
+func lintHeadings(nb *Notebook) []LintIssue {
+	var issues []LintIssue
+	titleSeen := false
+	prevLevel := 0
+	for i, cell := range nb.Cells {
+		if cell.CellType != "markdown" || cell.ID == tocCellID {
+			continue
+		}
+		lines := strings.Split(cell.Text(), "\n")
+		for _, heading := range markdownHeadings(cell.Text()) {
+			switch {
+			case heading.Level == 1 && titleSeen:
+				issues = append(issues, LintIssue{
+					Rule: lintTitle, Cell: i, Line: heading.Line,
+					Message: fmt.Sprintf("「# %s」：每個 notebook 只有一個 # 標題，其他標題從 ## 開始", heading.Text),
+					Fix:     &lintFix{Replace: "#" + lines[heading.Line]},
+				})
+				heading.Level = 2
+			case heading.Level == 1:
+				titleSeen = true
+			case !titleSeen && prevLevel == 0:
+				issues = append(issues, LintIssue{
+					Rule: lintTitle, Cell: i, Line: heading.Line,
+					Message: "notebook 應以 # 標題開頭",
+				})
+			case prevLevel > 0 && heading.Level > prevLevel+1:
+				level := prevLevel + 1
+				issues = append(issues, LintIssue{
+					Rule: lintHeadingLevel, Cell: i, Line: heading.Line,
+					Message: fmt.Sprintf("「%s %s」跳過了 %s 層級", strings.Repeat("#", heading.Level), heading.Text, strings.Repeat("#", level)),
+					Fix:     &lintFix{Replace: strings.Repeat("#", level) + strings.TrimLeft(lines[heading.Line], "#")},
+				})
+				heading.Level = level
+			}
+			prevLevel = heading.Level
+		}
+	}
+	return issues
+}
+
+// lintSections 檢查每個 ## 與 ### Section 之後都有 code cell；節底下有小節時由小節提供程式碼
+func lintSections(nb *Notebook) []LintIssue {
+	type section struct {
+		cell, line, level int
+		text              string
+		hasCode           bool
+		lastMarkdown      int
+	}
+	var sections []*section
+	var current *section // # 標題之後到下一個 ## 之前不屬於任何 Section
+
+	for i, cell := range nb.Cells {
+		switch cell.CellType {
+		case "code":
+			if current != nil && strings.TrimSpace(cell.Text()) != "" {
+				current.hasCode = true
+			}
+		case "markdown":
+			if cell.ID == tocCellID {
+				continue
+			}
+			started := false
+			for _, heading := range markdownHeadings(cell.Text()) {
+				switch heading.Level {
+				case 1:
+					current = nil
+					started = true
+				case 2, 3:
+					current = &section{cell: i, line: heading.Line, level: heading.Level, text: heading.Text, lastMarkdown: i}
+					sections = append(sections, current)
+					started = true
+				}
+			}
+			if !started && current != nil {
+				current.lastMarkdown = i
+			}
+		}
+	}
+
+	var issues []LintIssue
+	for k, s := range sections {
+		if s.hasCode {
+			continue
+		}
+		var next *section
+		if k+1 < len(sections) {
+			next = sections[k+1]
+		}
+		if next != nil && next.level > s.level {
+			continue
+		}
+		issue := LintIssue{
+			Rule: lintSectionCode, Cell: s.cell, Line: s.line,
+			Message: fmt.Sprintf("「%s %s」之後沒有 code cell", strings.Repeat("#", s.level), s.text),
+		}
+		// 下一個標題在同一個 cell 時無法插入，需要手動拆開
+		if next == nil || next.cell != s.cell {
+			issue.Fix = &lintFix{InsertCode: sectionCodeScaffold(s.text), After: s.lastMarkdown}
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+func lintPitfalls(nb *Notebook) []LintIssue {
+	for _, cell := range nb.Cells {
+		if pitfallRegex.MatchString(cell.Text()) {
+			return nil
+		}
+	}
+	return []LintIssue{{
+		Rule: lintPitfall, Cell: -1,
+		Message: "沒有標示容易出錯或需要注意的地方（⚠️、注意、陷阱、常見錯誤）",
+	}}
+}
+
+// parseCodeCell 解析 code cell；沒有 package 宣告時補上 package main，仍無法解析時包進函式中（gonb 的片段）。
+// 回傳補上的行數，用來換算回 cell 中的行號
+func parseCodeCell(fset *token.FileSet, source string) (*ast.File, int, error) {
+	if packageClauseRegex.MatchString(source) {
+		file, err := parser.ParseFile(fset, "cell.go", source, parser.ParseComments)
+		return file, 0, err
+	}
+	if file, err := parser.ParseFile(fset, "cell.go", "package main\n"+source, parser.ParseComments); err == nil {
+		return file, 1, nil
+	}
+	file, err := parser.ParseFile(fset, "cell.go", "package main\nfunc _() {\n"+source+"\n}", parser.ParseComments)
+	return file, 2, err
+}
+
+// lintCode 以 go/ast 檢查 code cell 的註解；無法解析的 cell（例如示範語法錯誤）不檢查
+func lintCode(index int, source string) []LintIssue {
+	fset := token.NewFileSet()
+	file, offset, err := parseCodeCell(fset, source)
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(source, "\n")
+
+	var issues []LintIssue
+	commented := false
+	blockOutput := false
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			if !buildConstraintRegex.MatchString(comment.Text) {
+				commented = true
+			}
+			if outputBlockRegex.MatchString(strings.TrimSpace(comment.Text)) {
+				blockOutput = true
+			}
+		}
+	}
+	if !commented {
+		issues = append(issues, LintIssue{Rule: lintCodeComments, Cell: index, Message: "範例程式沒有任何註解說明"})
+	}
+	if blockOutput {
+		return issues // 以 `// 輸出:` 區塊寫出整段程式的輸出
+	}
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		stmt, ok := node.(*ast.ExprStmt)
+		if !ok {
+			return true
+		}
+		call, ok := stmt.X.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		selector, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || !printFuncs[selector.Sel.Name] {
+			return true
+		}
+		if pkg, ok := selector.X.(*ast.Ident); !ok || pkg.Name != "fmt" {
+			return true
+		}
+
+		start, end := fset.Position(call.Pos()).Line, fset.Position(call.End()).Line
+		for _, group := range file.Comments {
+			if group.Pos() > call.End() && fset.Position(group.Pos()).Line == end {
+				return true
+			}
+		}
+
+		line := end - 1 - offset
+		issue := LintIssue{
+			Rule: lintPrintOutput, Cell: index, Line: line,
+			Message: fmt.Sprintf("fmt.%s 沒有以註解寫出輸出（例如 // 輸出: ...）", selector.Sel.Name),
+		}
+		if output, ok := literalOutput(call, selector.Sel.Name); ok && start == end && line >= 0 && line < len(lines) {
+			issue.Fix = &lintFix{Replace: strings.TrimRight(lines[line], " \t") + " // 輸出: " + output}
+		}
+		issues = append(issues, issue)
+		return true
+	})
+	return issues
+}
+
+// literalOutput 參數都是常值時直接算出 fmt.Print* 的輸出；多行或空白的輸出不自動填寫
+func literalOutput(call *ast.CallExpr, name string) (string, bool) {
+	var args []any
+	for _, arg := range call.Args {
+		lit, ok := arg.(*ast.BasicLit)
+		if !ok {
+			return "", false
+		}
+		value, ok := literalValue(lit)
+		if !ok {
+			return "", false
+		}
+		args = append(args, value)
+	}
+
+	var output string
+	switch name {
+	case "Print":
+		output = fmt.Sprint(args...)
+	case "Println":
+		output = fmt.Sprintln(args...)
+	case "Printf":
+		format, ok := "", len(args) > 0
+		if ok {
+			format, ok = args[0].(string)
+		}
+		if !ok {
+			return "", false
+		}
+		output = fmt.Sprintf(format, args[1:]...)
+	}
+	output = strings.TrimSuffix(output, "\n")
+	if strings.TrimSpace(output) == "" || strings.Contains(output, "\n") {
+		return "", false
+	}
+	return output, true
+}
+
+// literalValue 常值在 Go 中的預設型態與值
+func literalValue(lit *ast.BasicLit) (any, bool) {
+	switch lit.Kind {
+	case token.STRING:
+		s, err := strconv.Unquote(lit.Value)
+		return s, err == nil
+	case token.INT:
+		n, err := strconv.ParseInt(strings.ReplaceAll(lit.Value, "_", ""), 0, 64)
+		return int(n), err == nil
+	case token.FLOAT:
+		f, err := strconv.ParseFloat(strings.ReplaceAll(lit.Value, "_", ""), 64)
+		return f, err == nil
+	case token.CHAR:
+		// 去掉頭尾各一個引號後交給 UnquoteChar，'\'' 與 '\n' 這類跳脫字元才會正確解析
+		r, _, tail, err := strconv.UnquoteChar(lit.Value[1:len(lit.Value)-1], '\'')
+		return r, err == nil && tail == ""
+	}
+	return nil, false
+}
+
+// lintTarget 要檢查的檔案：來源檔或沒有來源檔的 notebook
+type lintTarget struct {
+	Path     string
+	Notebook *Notebook
+	// 來源檔才有：原始內容、每個 cell 的行範圍與格式
+	Lines  []string
+	Spans  []CellSpan
+	Marker bool
+}
+
+func loadLintTarget(path string) (*lintTarget, error) {
+	if strings.HasSuffix(path, ".ipynb") {
+		nb, err := ReadNotebook(path)
+		if err != nil {
+			return nil, err
+		}
+		return &lintTarget{Path: path, Notebook: nb}, nil
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := NewFileParser(strings.NewReader(string(source)), path, cfg)
+	nb, err := p.Parse()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &lintTarget{
+		Path:     path,
+		Notebook: nb,
+		Lines:    strings.Split(string(source), "\n"),
+		Spans:    p.Spans(),
+		Marker:   p.Format == FormatMarkers && p.Config.Dialect == "markers",
+	}, nil
+}
+
+// sourceLine 將 cell 內容中的行換算成來源檔的行（從 0 開始）；找不到對應的行時回傳 -1
+func (t *lintTarget) sourceLine(cell, line int) int {
+	start := t.cellStart(cell)
+	if start < 0 {
+		return -1
+	}
+	return start + line
+}
+
+// cellStart 回傳 cell 內容在來源檔中開始的行（從 0 開始）
+//
+// 在 cell 的範圍內尋找與 cell 內容逐行相同的區塊，空行或重複的行開頭也不會對錯位置。
+// percent 格式的 markdown cell 去掉了 `// `、面試考題的 cell 由標記組合而成，
+// 內容不會原樣出現在來源檔中，這時回傳 -1；notebook 也回傳 -1。
+func (t *lintTarget) cellStart(cell int) int {
+	if t.Lines == nil || cell < 0 || cell >= len(t.Spans) {
+		return -1
+	}
+	span := t.Spans[cell]
+	text := strings.Split(t.Notebook.Cells[cell].Text(), "\n")
+	for start := span.Start; start+len(text)-1 <= span.End && start+len(text) <= len(t.Lines); start++ {
+		if sameLines(t.Lines[start:start+len(text)], text) {
+			return start
+		}
+	}
+	return -1
+}
+
+// sameLines 逐行比較，忽略來源檔的 CR
+func sameLines(source, text []string) bool {
+	for i, line := range text {
+		if strings.TrimRight(source[i], "\r") != line {
+			return false
+		}
+	}
+	return true
+}
+
+// position 輸出 go vet 風格的位置
+func (t *lintTarget) position(issue LintIssue) string {
+	switch {
+	case issue.Cell < 0:
+		return t.Path
+	case t.sourceLine(issue.Cell, issue.Line) >= 0:
+		return fmt.Sprintf("%s:%d", t.Path, t.sourceLine(issue.Cell, issue.Line)+1)
+	}
+	return fmt.Sprintf("%s: cell %d line %d", t.Path, issue.Cell, issue.Line+1)
+}
+
+// fixable 這個檔案能不能套用此修正：插入 cell 只支援 notebook 與標記格式的來源檔，
+// 取代一行則要能在來源檔中找到那一行
+func (t *lintTarget) fixable(issue LintIssue) bool {
+	switch {
+	case issue.Fix == nil:
+		return false
+	case t.Lines == nil:
+		return true
+	case issue.Fix.InsertCode != "":
+		return t.Marker
+	}
+	return t.sourceLine(issue.Cell, issue.Line) >= 0
+}
+
+// applyFixes 套用修正並回傳新的檔案內容
+func (t *lintTarget) applyFixes(issues []LintIssue) ([]byte, error) {
+	if t.Lines == nil {
+		return applyNotebookFixes(t.Notebook, issues).ToJSON()
+	}
+
+	lines := append([]string(nil), t.Lines...)
+	inserts := map[int][]string{} // 在來源檔這一行之後插入
+	for _, issue := range issues {
+		switch {
+		case issue.Fix.InsertCode != "":
+			end := t.Spans[issue.Fix.After].End
+			cell := Cell{CellType: "code", Source: splitLines(issue.Fix.InsertCode)}
+			inserts[end] = append(inserts[end], "", renderMarkerCell(cell))
+		default:
+			lines[t.sourceLine(issue.Cell, issue.Line)] = issue.Fix.Replace
+		}
+	}
+
+	var out []string
+	for i, line := range lines {
+		out = append(out, line)
+		out = append(out, inserts[i]...)
+	}
+	return []byte(strings.Join(out, "\n")), nil
+}
+
+// applyNotebookFixes 直接修改 notebook 的 cells
+func applyNotebookFixes(nb *Notebook, issues []LintIssue) *Notebook {
+	inserts := map[int][]string{}
+	for _, issue := range issues {
+		if issue.Fix.InsertCode != "" {
+			inserts[issue.Fix.After] = append(inserts[issue.Fix.After], issue.Fix.InsertCode)
+			continue
+		}
+		cell := &nb.Cells[issue.Cell]
+		lines := strings.Split(cell.Text(), "\n")
+		lines[issue.Line] = issue.Fix.Replace
+		cell.Source = splitLines(strings.Join(lines, "\n"))
+	}
+
+	used := map[string]bool{}
+	for _, cell := range nb.Cells {
+		used[cell.ID] = true
+	}
+	var cells []Cell
+	for i, cell := range nb.Cells {
+		cells = append(cells, cell)
+		for _, code := range inserts[i] {
+			id := fmt.Sprintf("lint-%d", len(cells))
+			for n := 2; used[id]; n++ {
+				id = fmt.Sprintf("lint-%d-%d", len(cells), n)
+			}
+			used[id] = true
+			cells = append(cells, Cell{CellType: "code", ID: id, Metadata: CellMetadata{}, Source: splitLines(code), Outputs: []any{}})
+		}
+	}
+	nb.Cells = cells
+	return nb
+}
+
+// collectLintFiles 展開目錄：來源檔，以及沒有來源檔的 notebook（由來源檔產生的解答 notebook 不另外列出）
+func collectLintFiles(inputs []string) ([]string, error) {
+	return collectFiles(inputs, func(cfg Config, files []string) []string {
+		var sources, notebooks []string
+		generated := map[string]bool{}
+		for _, path := range files {
+			if output, ok := cfg.OutputPathFor(path); ok {
+				sources = append(sources, path)
+				generated[output] = true
+				generated[answerKeyPath(output)] = true
+			} else if filepath.Ext(path) == ".ipynb" {
+				notebooks = append(notebooks, path)
+			}
+		}
+		for _, path := range notebooks {
+			if !generated[path] {
+				sources = append(sources, path)
+			}
+		}
+		return sources
+	})
+}
+
+// runLint lint 子命令：檢查 product.md 的撰寫規範，--fix 時套用能自動修正的部分
+func runLint(args []string) error {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	fix := fs.Bool("fix", false, "套用自動修正並寫回檔案")
+	disable := fs.String("disable", "", "停用的規則，以逗號分隔："+strings.Join(lintRules, ", "))
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s lint [--fix] [--disable rules] file-or-dir...\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "檢查來源檔（*_source.md 等）與沒有來源檔的 notebook\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	disabled := map[string]bool{}
+	for _, rule := range splitList(*disable) {
+		known := false
+		for _, name := range lintRules {
+			known = known || name == rule
+		}
+		if !known {
+			return fmt.Errorf("unknown lint rule %q", rule)
+		}
+		disabled[rule] = true
+	}
+
+	paths, err := collectLintFiles(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	remaining := 0
+	for _, path := range paths {
+		target, err := loadLintTarget(path)
+		if err != nil {
+			return err
+		}
+
+		var issues, fixes []LintIssue
+		for _, issue := range LintNotebook(target.Notebook, isNoteNotebook(path)) {
+			if disabled[issue.Rule] {
+				continue
+			}
+			if *fix && target.fixable(issue) {
+				fixes = append(fixes, issue)
+				continue
+			}
+			issues = append(issues, issue)
+		}
+
+		for _, issue := range issues {
+			suffix := ""
+			if target.fixable(issue) {
+				suffix = "（可用 --fix 修正）"
+			}
+			fmt.Printf("%s: [%s] %s%s\n", target.position(issue), issue.Rule, issue.Message, suffix)
+		}
+		remaining += len(issues)
+
+		if len(fixes) > 0 {
+			data, err := target.applyFixes(fixes)
+			if err != nil {
+				return err
+			}
+			if err := writeFileAtomic(path, data, false); err != nil {
+				return fmt.Errorf("failed to write %s: %w", path, err)
+			}
+			fmt.Printf("📝 已修正 %d 個問題: %s\n", len(fixes), path)
+		}
+	}
+
+	if remaining > 0 {
+		return fmt.Errorf("%d lint issues", remaining)
+	}
+	fmt.Printf("✅ 沒有發現問題（%d 個檔案）\n", len(paths))
+	return nil
+}
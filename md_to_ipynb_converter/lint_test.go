@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hank/learning-go/ch9/converter/outline"
+)
+
+func TestLintNotebook(t *testing.T) {
+	nb := &Notebook{Cells: []Cell{
+		{CellType: "markdown", Source: splitLines("# 第五章 函式\n\n⚠️ 注意閉包")},
+		{CellType: "markdown", Source: splitLines("## 宣告函式")},
+		{CellType: "code", Source: splitLines("package main\n\nimport \"fmt\"\n\nfunc main() {\n\tx := 1\n\tfmt.Println(\"hi\", 2)\n\tfmt.Println(x)\n}")},
+		{CellType: "markdown", Source: splitLines("#### 跳級的標題")},
+		{CellType: "markdown", Source: splitLines("## 沒有程式碼的節")},
+		{CellType: "markdown", Source: splitLines("補充說明")},
+		{CellType: "markdown", Source: splitLines("# 第二個標題")},
+		{CellType: "code", Source: splitLines("// 片段\nfmt.Println(\"ok\") // 輸出: ok")},
+	}}
+
+	var got []string
+	for _, issue := range LintNotebook(nb, true) {
+		fixed := ""
+		if issue.Fix != nil {
+			fixed = " fix"
+		}
+		got = append(got, fmt.Sprintf("%s@%d:%d%s", issue.Rule, issue.Cell, issue.Line, fixed))
+	}
+	want := []string{
+		"code-comments@2:0",
+		"print-output@2:6 fix",
+		"print-output@2:7",
+		"heading-level@3:0 fix",
+		"section-code@4:0 fix",
+		"title@6:0 fix",
+	}
+	assertStrings(t, "issues", got, want)
+
+	issues := LintNotebook(nb, true)
+	if fix := issues[1].Fix.Replace; fix != "\tfmt.Println(\"hi\", 2) // 輸出: hi 2" {
+		t.Errorf("Unexpected print fix %q", fix)
+	}
+	if fix := issues[3].Fix.Replace; fix != "### 跳級的標題" {
+		t.Errorf("Unexpected heading fix %q", fix)
+	}
+	if fix := issues[4].Fix; fix.After != 5 || !strings.Contains(fix.InsertCode, "/* 沒有程式碼的節 */") {
+		t.Errorf("Code cell should be inserted after the section's last markdown cell: %+v", fix)
+	}
+
+	// 面試考題不套用 Section 與 pitfall 規則
+	for _, issue := range LintNotebook(&Notebook{Cells: []Cell{{CellType: "markdown", Source: splitLines("# 考題\n\n## 題目")}}}, false) {
+		t.Errorf("Unexpected issue for a questions notebook: %+v", issue)
+	}
+	if issues := LintNotebook(&Notebook{Cells: []Cell{{CellType: "markdown", Source: splitLines("# 筆記")}}}, true); len(issues) != 1 || issues[0].Rule != lintPitfall {
+		t.Errorf("Expected a pitfall issue, got %+v", issues)
+	}
+}
+
+func TestLintSections_ParentWithSubsections(t *testing.T) {
+	nb := &Notebook{Cells: []Cell{
+		{CellType: "markdown", Source: splitLines("## channel")},
+		{CellType: "markdown", Source: splitLines("### 關閉 channel")},
+		{CellType: "code", Source: splitLines("close(ch)")},
+		{CellType: "markdown", Source: splitLines("## select\n\n## 總結")},
+	}}
+	issues := lintSections(nb)
+	if len(issues) != 2 || issues[0].Fix != nil || issues[1].Fix == nil {
+		t.Errorf("Expected select (not fixable, same cell as 總結) and 總結, got %+v", issues)
+	}
+}
+
+func TestLiteralOutput(t *testing.T) {
+	tests := []struct {
+		call, want string
+		ok         bool
+	}{
+		{`fmt.Println("a", 1, 2.5, 'x')`, "a 1 2.5 120", true},
+		{`fmt.Print(1, 2)`, "1 2", true},
+		{`fmt.Print("a", "b")`, "ab", true},
+		{`fmt.Printf("%d-%s\n", 7, "go")`, "7-go", true},
+		{`fmt.Printf("%T", 1)`, "int", true},
+		{`fmt.Printf("%c%c", '\'', '語')`, "'語", true},
+		{`fmt.Println('\\', '\x41', '\u00e9')`, "92 65 233", true},
+		{`fmt.Printf("%q", '\n')`, `'\n'`, true},
+		{`fmt.Println(x)`, "", false},
+		{`fmt.Println("a\nb")`, "", false},
+		{`fmt.Println()`, "", false},
+	}
+	for _, tt := range tests {
+		expr, err := parser.ParseExpr(tt.call)
+		if err != nil {
+			t.Fatal(err)
+		}
+		call := expr.(*ast.CallExpr)
+		got, ok := literalOutput(call, call.Fun.(*ast.SelectorExpr).Sel.Name)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("literalOutput(%s) = %q, %v; want %q, %v", tt.call, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRunLint_FixSource(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "ch5_functions_source.md")
+	writeTestFile(t, source, "<!-- MARKDOWN_CELL -->\n# 第五章 函式\n\n⚠️ 注意\n<!-- END_MARKDOWN_CELL -->\n\n"+
+		"<!-- MARKDOWN_CELL -->\n#### 宣告\n<!-- END_MARKDOWN_CELL -->\n\n"+
+		"<!-- CODE_CELL -->\n```go\n// 宣告函式\nfmt.Println(\"hi\")\n```\n<!-- END_CODE_CELL -->\n\n"+
+		"<!-- MARKDOWN_CELL -->\n## 總結\n<!-- END_MARKDOWN_CELL -->\n")
+
+	if err := run([]string{"lint", source}); err == nil || !strings.Contains(err.Error(), "3 lint issues") {
+		t.Fatalf("Expected 3 lint issues, got %v", err)
+	}
+	if err := run([]string{"lint", "--fix", source}); err != nil {
+		t.Fatalf("All issues should be fixable: %v", err)
+	}
+
+	got := readFile(t, source)
+	for _, want := range []string{
+		"<!-- MARKDOWN_CELL -->\n## 宣告\n",
+		"fmt.Println(\"hi\") // 輸出: hi\n",
+		"## 總結\n<!-- END_MARKDOWN_CELL -->\n\n<!-- CODE_CELL -->\n```go\npackage main",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Fixed source should contain %q:\n%s", want, got)
+		}
+	}
+	if err := run([]string{"lint", source}); err != nil {
+		t.Errorf("Fixed source should pass: %v", err)
+	}
+	if err := run([]string{"lint", "--disable", "nope", source}); err == nil {
+		t.Error("Expected an error for an unknown rule")
+	}
+}
+
+func TestRunLint_FixPercent(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "ch5_functions.go")
+	original := "// %% [markdown]\n// # 第五章 函式\n//\n// ⚠️ 注意\n\n" +
+		"// %% [markdown]\n// # 宣告\n\n" +
+		"// %%\n// 宣告函式\nfmt.Println(\"hi\")\n\n" +
+		"// %% [markdown]\n// ## 總結\n"
+	writeTestFile(t, source, original)
+
+	// markdown cell 的內容去掉了 `// `，不能原地取代、percent 格式也不能插入 cell；
+	// code cell 的輸出註解仍可修正
+	if err := run([]string{"lint", "--fix", source}); err == nil || !strings.Contains(err.Error(), "2 lint issues") {
+		t.Fatalf("Expected the second title and the missing code to remain, got %v", err)
+	}
+	got := readFile(t, source)
+	want := strings.Replace(original, "fmt.Println(\"hi\")", "fmt.Println(\"hi\") // 輸出: hi", 1)
+	if got != want {
+		t.Errorf("Only the code cell should change:\n%s", got)
+	}
+}
+
+func TestRunLint_ScaffoldIsClean(t *testing.T) {
+	root := t.TempDir()
+	toc := filepath.Join(root, outline.FileName)
+	writeTestFile(t, toc, "## 第五章 函式 ..... 89\n- 宣告與呼叫函式 ..... 89\n  - 可變參數 ..... 91\n- 閉包 ..... 97\n")
+	if err := run([]string{"scaffold", "chapter", "5", "--toc", toc}); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := collectLintFiles([]string{root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Errorf("Expected only the three source files, got %v", files)
+	}
+	if err := run([]string{"lint", root}); err != nil {
+		t.Errorf("Scaffolded chapter should pass lint: %v", err)
+	}
+}
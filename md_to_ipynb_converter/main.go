@@ -1,28 +1,230 @@
 package main
 
 import (
+	"bytes"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"strings"
 )
 
+// commands 所有子命令；第一個參數不是子命令時視為舊版用法 `input.md output.ipynb`
+var commands = map[string]func(args []string) error{
+	"convert":      runConvert,
+	"sync":         runSync,
+	"install-hook": runInstallHook,
+	"export":       runExport,
+	"from-go":      runFromGo,
+	"toc":          runTOC,
+	"outline":      runOutline,
+	"scaffold":     runScaffold,
+	"coverage":     runCoverage,
+	"status":       runStatus,
+	"lint":         runLint,
+	"prereq":       runPrereq,
+	"annotate":     runAnnotate,
+	"grade":        runGrade,
+	"review":       runReview,
+	"bank":         runBank,
+}
+
 func main() {
-	if len(os.Args) != 3 {
-		fmt.Fprintf(os.Stderr, "Usage: %s input.md output.ipynb\n", os.Args[0])
+	if err := run(os.Args[1:]); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
 		os.Exit(1)
 	}
+}
 
-	inputFile := os.Args[1]
-	outputFile := os.Args[2]
+func run(args []string) error {
+	if len(args) == 0 {
+		printUsage()
+		return flag.ErrHelp
+	}
 
-	if err := convert(inputFile, outputFile); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	if command, ok := commands[args[0]]; ok {
+		return command(args[1:])
+	}
+
+	return runConvert(args)
+}
+
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [convert] [flags] input.md [output.ipynb]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s convert [flags] dir\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s sync input.md output.ipynb\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s export <format> input [output]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s from-go [flags] input.go [output.ipynb]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s toc [--depth N] [--check] notebook.ipynb...\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s outline [--chapter N] [目錄.md]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s scaffold chapter [--topic name] N\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s coverage [--chapter N] [--check] [path...]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s status [--exec] [--format table|json|md] [-o file]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s prereq [--chapter N] [--list] file-or-dir...\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s lint [--fix] [--disable rules] file-or-dir...\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s annotate [--dry-run] [--runs N] [--timeout d] file-or-dir...\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s grade [-o results.json] [--timeout d] questions.ipynb...\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s review [-o review.ipynb] [--key key.ipynb] [--grades results.json] [--all] questions.ipynb\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s bank [build [-o bank.json] path...] | bank exam [--chapters 3-5] [--tags a,b] [-n 10] [--seed N]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s install-hook [--force]\n", os.Args[0])
+}
+
+// convertOptions convert 子命令的選項
+type convertOptions struct {
+	// Update 將結果合併進既有的 notebook，保留 outputs 與 ID
+	Update bool
+	// Check 只在記憶體中轉換並與既有 notebook 比較，不寫入檔案
+	Check bool
+	// Backup 覆寫前把舊檔保存成 .bak
+	Backup bool
+	// DryRun 只輸出會產生的 cell 差異，不寫入檔案
+	DryRun bool
+	// ConfigPath 指定設定檔；空字串時從輸入檔往上尋找 .md2ipynb.json
+	ConfigPath string
+	// Settings 命令列上指定的設定，覆蓋設定檔
+	Settings map[string]string
+}
+
+// settingFlags 可以在命令列覆蓋的設定（flag 名稱 -> 設定 key）
+var settingFlags = map[string]string{
+	"kernel":      "kernel",
+	"id-strategy": "id_strategy",
+	"dialect":     "dialect",
+	"strict":      "strict",
+	"toc":         "toc",
+	"toc-depth":   "toc_depth",
+}
+
+func runConvert(args []string) error {
+	var opts convertOptions
+
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	fs.BoolVar(&opts.Update, "update", false, "合併進既有 notebook，保留未變更 cell 的 outputs、execution_count 與 metadata")
+	fs.BoolVar(&opts.Check, "check", false, "檢查 notebook 是否與 .md 一致，不一致時以非零狀態結束")
+	fs.BoolVar(&opts.Backup, "backup", false, "覆寫前把既有檔案保存成 .bak")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "只輸出 cell 層級的 unified diff，不寫入檔案")
+	fs.StringVar(&opts.ConfigPath, "config", "", "設定檔路徑（預設從輸入檔往上尋找 "+configFileName+"）")
+	fs.String("kernel", "", "kernel 設定：gophernotes 或 gonb")
+	fs.String("id-strategy", "", "cell ID 產生方式：sequential 或 hash")
+	fs.String("dialect", "", "Markdown 的 cell 語法：markers、myst 或 quarto")
+	fs.Bool("strict", false, "嚴格模式：標記不成對或標記外有內容時回報錯誤")
+	fs.Bool("toc", false, "在標題之後插入連到各標題的目錄 cell")
+	fs.Int("toc-depth", defaultTOCDepth, "目錄列出的最深標題層級")
+	fs.Usage = func() {
+		printUsage()
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// 只有明確指定的 flag 才覆蓋設定檔
+	opts.Settings = map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		if key, ok := settingFlags[f.Name]; ok {
+			opts.Settings[key] = f.Value.String()
+		}
+	})
+
+	switch fs.NArg() {
+	case 1:
+		if info, err := os.Stat(fs.Arg(0)); err == nil && info.IsDir() {
+			return convertDir(fs.Arg(0), opts)
+		}
+		cfg, err := loadConfig(fs.Arg(0), opts)
+		if err != nil {
+			return err
+		}
+		outputFile, ok := cfg.OutputPathFor(fs.Arg(0))
+		if !ok {
+			return fmt.Errorf("%s does not match source_pattern %q; specify the output file", fs.Arg(0), cfg.SourcePattern)
+		}
+		return convertOne(fs.Arg(0), outputFile, opts)
+	case 2:
+		return convertOne(fs.Arg(0), fs.Arg(1), opts)
+	default:
+		fs.Usage()
+		return flag.ErrHelp
+	}
+}
+
+// convertOne 轉換單一檔案並輸出結果訊息
+func convertOne(inputFile, outputFile string, opts convertOptions) error {
+	if err := convertWithOptions(inputFile, outputFile, opts); err != nil {
+		return err
+	}
+	if opts.Check {
+		fmt.Printf("✅ 已是最新: %s\n", outputFile)
+		return nil
+	}
+	if opts.DryRun {
+		return nil
 	}
 
 	fmt.Printf("✅ 成功轉換: %s -> %s\n", inputFile, outputFile)
+	return nil
+}
+
+// convertDir 批次轉換目錄下所有符合 source_pattern 的檔案，略過 exclude 中的目錄
+func convertDir(root string, opts convertOptions) error {
+	cfg, err := loadConfig(root, opts)
+	if err != nil {
+		return err
+	}
+
+	var failed []string
+	err = cfg.WalkFiles(root, func(path string) error {
+		outputFile, ok := cfg.OutputPathFor(path)
+		if !ok {
+			return nil
+		}
+		if err := convertOne(path, outputFile, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %s: %v\n", path, err)
+			failed = append(failed, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d file(s) failed: %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// loadConfig 依 opts 載入設定：設定檔，再套用命令列設定
+func loadConfig(inputPath string, opts convertOptions) (Config, error) {
+	var cfg Config
+	var err error
+	if opts.ConfigPath != "" {
+		cfg, err = LoadConfigFile(opts.ConfigPath)
+	} else {
+		cfg, err = LoadConfig(inputPath)
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := cfg.Apply(opts.Settings); err != nil {
+		return cfg, fmt.Errorf("invalid option: %w", err)
+	}
+	return cfg, nil
 }
 
 func convert(inputPath, outputPath string) error {
+	return convertWithOptions(inputPath, outputPath, convertOptions{})
+}
+
+func convertWithOptions(inputPath, outputPath string, opts convertOptions) error {
+	cfg, err := loadConfig(inputPath, opts)
+	if err != nil {
+		return err
+	}
+
 	// 讀取輸入檔案
 	inputFile, err := os.Open(inputPath)
 	if err != nil {
@@ -30,12 +232,48 @@ func convert(inputPath, outputPath string) error {
 	}
 	defer inputFile.Close()
 
-	// 解析
-	parser := NewParser(inputFile)
+	// 解析（front matter 會覆蓋設定檔與命令列設定）
+	parser := NewFileParser(inputFile, inputPath, cfg)
 	notebook, err := parser.Parse()
 	if err != nil {
 		return fmt.Errorf("failed to parse: %w", err)
 	}
+	UpdateTOC(notebook, parser.Config.TOCDepth, parser.Config.TOC)
+
+	// 面試考題：保留已經作答的 cell
+	if existing, err := ReadNotebook(outputPath); err == nil {
+		if n := preserveAnswers(existing, notebook); n > 0 && !opts.Check {
+			fmt.Printf("ℹ️  保留 %d 個已作答的 cells\n", n)
+		}
+	}
+
+	// 檢查模式：只比較，不寫入；考題與解答都會檢查
+	if opts.Check {
+		err := checkFreshness(notebook, outputPath)
+		key, keyErr := parser.AnswerKey(notebook)
+		if keyErr == nil && key != nil {
+			keyErr = checkFreshness(key, answerKeyPath(outputPath))
+		}
+		if err == nil {
+			err = keyErr
+		}
+		return err
+	}
+
+	// 更新模式：與既有 notebook 合併
+	if opts.Update {
+		existing, err := ReadNotebook(outputPath)
+		switch {
+		case err == nil:
+			var report UpdateReport
+			notebook, report = UpdateNotebook(existing, notebook)
+			printUpdateReport(report)
+		case errors.Is(err, os.ErrNotExist):
+			fmt.Printf("ℹ️  %s 不存在，直接建立新檔\n", outputPath)
+		default:
+			return fmt.Errorf("failed to read existing notebook: %w", err)
+		}
+	}
 
 	// 轉換成 JSON
 	jsonData, err := notebook.ToJSON()
@@ -43,12 +281,122 @@ func convert(inputPath, outputPath string) error {
 		return fmt.Errorf("failed to convert to JSON: %w", err)
 	}
 
+	// 試跑：只輸出差異
+	if opts.DryRun {
+		if err := printDryRun(notebook, outputPath); err != nil {
+			return err
+		}
+		key, err := parser.AnswerKey(notebook)
+		if err != nil || key == nil {
+			return err
+		}
+		return printDryRun(key, answerKeyPath(outputPath))
+	}
+
 	// 寫入輸出檔案
-	if err := os.WriteFile(outputPath, jsonData, 0644); err != nil {
+	if err := writeFileAtomic(outputPath, jsonData, opts.Backup); err != nil {
 		return fmt.Errorf("failed to write output file: %w", err)
 	}
 
 	fmt.Printf("📊 共 %d 個 cells\n", len(notebook.Cells))
 
+	return writeAnswerKey(parser, notebook, outputPath, opts.Backup)
+}
+
+// writeAnswerKey 來源檔有 ANSWER 區塊時，在考題旁寫入解答 notebook
+func writeAnswerKey(parser *Parser, questions *Notebook, outputPath string, backup bool) error {
+	key, err := parser.AnswerKey(questions)
+	if err != nil || key == nil {
+		return err
+	}
+	data, err := key.ToJSON()
+	if err != nil {
+		return fmt.Errorf("failed to convert answer key to JSON: %w", err)
+	}
+	keyPath := answerKeyPath(outputPath)
+	if err := writeFileAtomic(keyPath, data, backup); err != nil {
+		return fmt.Errorf("failed to write answer key: %w", err)
+	}
+	fmt.Printf("🔑 解答: %s\n", keyPath)
 	return nil
 }
+
+// checkFreshness 比較重新產生的 notebook 與既有檔案，有差異時回傳錯誤
+func checkFreshness(generated *Notebook, outputPath string) error {
+	committed, err := ReadNotebook(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to read existing notebook: %w", err)
+	}
+
+	diffs := DiffNotebooks(committed, generated)
+	if len(diffs) == 0 {
+		return nil
+	}
+
+	fmt.Printf("❌ %s 與來源不一致 (%d 個 cells):\n", outputPath, len(diffs))
+	for _, diff := range diffs {
+		fmt.Printf("  %s\n", diff)
+	}
+	return fmt.Errorf("%s is stale", outputPath)
+}
+
+// printDryRun 輸出寫入後 notebook 會產生的 cell 差異，以及會被覆蓋的輸出與 metadata
+func printDryRun(generated *Notebook, outputPath string) error {
+	existing, err := ReadNotebook(outputPath)
+	if errors.Is(err, os.ErrNotExist) {
+		existing = NewNotebook()
+	} else if err != nil {
+		return fmt.Errorf("failed to read existing notebook: %w", err)
+	}
+
+	// 以序列化後的 JSON 判斷是否真的沒有變更
+	oldData, err := existing.ToJSON()
+	if err != nil {
+		return fmt.Errorf("failed to convert to JSON: %w", err)
+	}
+	newData, err := generated.ToJSON()
+	if err != nil {
+		return fmt.Errorf("failed to convert to JSON: %w", err)
+	}
+	if bytes.Equal(oldData, newData) {
+		fmt.Printf("✅ %s 沒有任何變更\n", outputPath)
+		return nil
+	}
+
+	diffs := DiffNotebooks(existing, generated)
+	if len(diffs) > 0 {
+		WriteUnifiedDiff(os.Stdout, outputPath, outputPath+" (dry-run)", diffs)
+	}
+	outputs, metadata := OverwriteLosses(existing, generated)
+	if outputs > 0 {
+		fmt.Printf("⚠️  %d 個 cells 的執行輸出會被清除（可改用 --update 保留）\n", outputs)
+	}
+	if metadata > 0 {
+		fmt.Printf("⚠️  %d 個 cells 的 metadata 會變更\n", metadata)
+	}
+	if len(existing.Cells) > 0 && !sameJSON(existing.Metadata, generated.Metadata) {
+		fmt.Println("⚠️  notebook metadata（kernelspec 等）會變更")
+	}
+	if len(diffs) == 0 {
+		fmt.Println("📝 cell 內容沒有變更，但檔案會被覆寫（未寫入）")
+		return nil
+	}
+	fmt.Printf("📝 %d 個 cells 會變更（未寫入）\n", len(diffs))
+	return nil
+}
+
+func printUpdateReport(report UpdateReport) {
+	fmt.Printf("🔄 更新: %s\n", report)
+	for _, id := range report.Edited {
+		fmt.Printf("  ~ %s (修改，已清除輸出)\n", id)
+	}
+	for _, id := range report.Inserted {
+		fmt.Printf("  + %s (新增)\n", id)
+	}
+	for _, id := range report.Removed {
+		fmt.Printf("  - %s (刪除)\n", id)
+	}
+	for _, id := range report.Moved {
+		fmt.Printf("  > %s (移動)\n", id)
+	}
+}
@@ -0,0 +1,617 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Heading Markdown 中的標題
+type Heading struct {
+	Level int
+	Text  string // 去除 Markdown 語法後的純文字
+	ID    string // 錨點，與 JupyterLab 的產生方式相同
+}
+
+// headingID 以 JupyterLab 的方式產生標題錨點：標題文字中的空白換成 -，其餘字元（含中文）保持不變
+func headingID(text string) string {
+	return strings.ReplaceAll(text, " ", "-")
+}
+
+// MarkdownRenderer 將 Markdown 轉成 HTML，支援筆記中常用的 GitHub 風格語法：
+// 標題、段落、強調、行內程式碼、連結、圖片、清單（含巢狀與待辦事項）、引言、表格、code fence 與 HTML 區塊
+type MarkdownRenderer struct {
+	// ResolveImage 轉換圖片網址，例如把 attachment:xxx.png 換成 data URI；nil 時保持不變
+	ResolveImage func(src string) string
+	// XHTML 為 true 時輸出自我結束的空元素（<br/>、<img/>），用於 EPUB
+	XHTML bool
+	// EscapeHTML 為 true 時 HTML 區塊與行內 HTML 標籤都當成一般文字輸出
+	EscapeHTML bool
+
+	// Headings 目前為止轉換過的所有標題
+	Headings []Heading
+}
+
+var (
+	atxHeadingRegex     = regexp.MustCompile(`^(#{1,6})(?:\s+(.*?))?(?:\s+#+)?\s*$`)
+	fenceRegex          = regexp.MustCompile("^(\\s*)(`{3,}|~{3,})\\s*([^`\\s]*)")
+	hrRegex             = regexp.MustCompile(`^\s{0,3}(?:(?:\*\s*){3,}|(?:-\s*){3,}|(?:_\s*){3,})$`)
+	listItemRegex       = regexp.MustCompile(`^(\s*)([-*+]|\d{1,9}[.)])(\s+|$)`)
+	tableSeparatorRegex = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(?:\|\s*:?-+:?\s*)*\|?\s*$`)
+	htmlBlockRegex      = regexp.MustCompile(`^\s{0,3}</?(?:[a-zA-Z][\w-]*|!--)`)
+	taskItemRegex       = regexp.MustCompile(`^\[([ xX])\]\s+`)
+)
+
+// Render 將一段 Markdown 轉成 HTML
+func (r *MarkdownRenderer) Render(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	var b strings.Builder
+	r.renderBlocks(&b, lines)
+	return b.String()
+}
+
+// RenderMarkdown 以預設設定將 Markdown 轉成 HTML
+func RenderMarkdown(text string) string {
+	var r MarkdownRenderer
+	return r.Render(text)
+}
+
+func (r *MarkdownRenderer) renderBlocks(b *strings.Builder, lines []string) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		case fenceRegex.MatchString(line):
+			i = r.renderFence(b, lines, i)
+
+		case atxHeadingRegex.MatchString(trimmed) && !strings.HasPrefix(line, "    "):
+			match := atxHeadingRegex.FindStringSubmatch(trimmed)
+			r.renderHeading(b, len(match[1]), match[2])
+			i++
+
+		case hrRegex.MatchString(line):
+			b.WriteString(r.voidTag("hr") + "\n")
+			i++
+
+		case strings.HasPrefix(trimmed, ">"):
+			var quoted []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				content := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quoted = append(quoted, strings.TrimPrefix(content, " "))
+			}
+			b.WriteString("<blockquote>\n")
+			r.renderBlocks(b, quoted)
+			b.WriteString("</blockquote>\n")
+
+		case i+1 < len(lines) && strings.Contains(line, "|") && tableSeparatorRegex.MatchString(lines[i+1]):
+			i = r.renderTable(b, lines, i)
+
+		case listItemRegex.MatchString(line):
+			i = r.renderList(b, lines, i)
+
+		case !r.EscapeHTML && htmlBlockRegex.MatchString(line):
+			for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+				b.WriteString(lines[i] + "\n")
+			}
+
+		case strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t"):
+			var code []string
+			for ; i < len(lines) && (strings.TrimSpace(lines[i]) == "" || strings.HasPrefix(lines[i], "    ") || strings.HasPrefix(lines[i], "\t")); i++ {
+				code = append(code, strings.TrimPrefix(strings.TrimPrefix(lines[i], "\t"), "    "))
+			}
+			for len(code) > 0 && strings.TrimSpace(code[len(code)-1]) == "" {
+				code = code[:len(code)-1]
+			}
+			fmt.Fprintf(b, "<pre><code>%s</code></pre>\n", html.EscapeString(strings.Join(code, "\n")))
+
+		default:
+			var paragraph []string
+			for ; i < len(lines) && !r.startsBlock(lines, i); i++ {
+				paragraph = append(paragraph, lines[i])
+			}
+			fmt.Fprintf(b, "<p>%s</p>\n", r.renderInline(strings.Join(paragraph, "\n")))
+		}
+	}
+}
+
+// startsBlock 判斷第 i 行是否結束目前的段落
+func (r *MarkdownRenderer) startsBlock(lines []string, i int) bool {
+	line := lines[i]
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" ||
+		fenceRegex.MatchString(line) ||
+		atxHeadingRegex.MatchString(trimmed) ||
+		hrRegex.MatchString(line) ||
+		strings.HasPrefix(trimmed, ">") ||
+		listItemRegex.MatchString(line) ||
+		(i+1 < len(lines) && strings.Contains(line, "|") && tableSeparatorRegex.MatchString(lines[i+1]))
+}
+
+func (r *MarkdownRenderer) renderHeading(b *strings.Builder, level int, text string) {
+	inline := r.renderInline(text)
+	plain := plainText(inline)
+	id := headingID(plain)
+	r.Headings = append(r.Headings, Heading{Level: level, Text: plain, ID: id})
+	fmt.Fprintf(b, "<h%d id=\"%s\">%s</h%d>\n", level, html.EscapeString(id), inline, level)
+}
+
+// renderFence 輸出 code fence，回傳下一個要處理的行
+func (r *MarkdownRenderer) renderFence(b *strings.Builder, lines []string, start int) int {
+	match := fenceRegex.FindStringSubmatch(lines[start])
+	indent, fence, lang := len(match[1]), match[2], match[3]
+
+	var code []string
+	i := start + 1
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, fence[:3]) && strings.Trim(trimmed, fence[:1]) == "" && len(trimmed) >= len(fence) {
+			i++
+			break
+		}
+		line := lines[i]
+		for n := 0; n < indent && strings.HasPrefix(line, " "); n++ {
+			line = line[1:]
+		}
+		code = append(code, line)
+	}
+
+	b.WriteString(renderCodeBlock(lang, strings.Join(code, "\n")))
+	return i
+}
+
+// renderCodeBlock 輸出 <pre><code>；Go 程式碼會加上語法高亮
+func renderCodeBlock(lang, code string) string {
+	body := html.EscapeString(code)
+	class := ""
+	if lang != "" {
+		class = fmt.Sprintf(` class="language-%s"`, html.EscapeString(lang))
+	}
+	if lang == "go" || lang == "golang" {
+		body = HighlightGo(code)
+	}
+	return fmt.Sprintf("<pre><code%s>%s</code></pre>\n", class, body)
+}
+
+// renderTable 輸出 GitHub 風格的表格，回傳下一個要處理的行
+func (r *MarkdownRenderer) renderTable(b *strings.Builder, lines []string, start int) int {
+	header := splitTableRow(lines[start])
+	var aligns []string
+	for _, cell := range splitTableRow(lines[start+1]) {
+		switch {
+		case strings.HasPrefix(cell, ":") && strings.HasSuffix(cell, ":"):
+			aligns = append(aligns, "center")
+		case strings.HasSuffix(cell, ":"):
+			aligns = append(aligns, "right")
+		case strings.HasPrefix(cell, ":"):
+			aligns = append(aligns, "left")
+		default:
+			aligns = append(aligns, "")
+		}
+	}
+
+	writeRow := func(cells []string, tag string) {
+		b.WriteString("<tr>")
+		for j, cell := range cells {
+			style := ""
+			if j < len(aligns) && aligns[j] != "" {
+				style = fmt.Sprintf(` style="text-align: %s"`, aligns[j])
+			}
+			fmt.Fprintf(b, "<%s%s>%s</%s>", tag, style, r.renderInline(cell), tag)
+		}
+		b.WriteString("</tr>\n")
+	}
+
+	b.WriteString("<table>\n<thead>\n")
+	writeRow(header, "th")
+	b.WriteString("</thead>\n<tbody>\n")
+	i := start + 2
+	for ; i < len(lines) && strings.TrimSpace(lines[i]) != "" && strings.Contains(lines[i], "|"); i++ {
+		writeRow(splitTableRow(lines[i]), "td")
+	}
+	b.WriteString("</tbody>\n</table>\n")
+	return i
+}
+
+// splitTableRow 分割表格的一列；行內程式碼中與跳脫的 \| 不會被當成分隔
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	inCode := false
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case c == '`':
+			inCode = !inCode
+			cell.WriteByte(c)
+		case c == '|' && !inCode:
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(c)
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// renderList 輸出清單（含巢狀清單），回傳下一個要處理的行
+func (r *MarkdownRenderer) renderList(b *strings.Builder, lines []string, start int) int {
+	first := listItemRegex.FindStringSubmatch(lines[start])
+	indent := len(first[1])
+	ordered := first[2] != "-" && first[2] != "*" && first[2] != "+"
+
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+		if number := strings.TrimRight(first[2], ".)"); number != "1" {
+			fmt.Fprintf(b, "<ol start=\"%s\">\n", number)
+		} else {
+			b.WriteString("<ol>\n")
+		}
+	} else {
+		b.WriteString("<ul>\n")
+	}
+
+	i := start
+	for i < len(lines) {
+		match := listItemRegex.FindStringSubmatch(lines[i])
+		if match == nil || len(match[1]) != indent {
+			break
+		}
+		if isOrdered := match[2] != "-" && match[2] != "*" && match[2] != "+"; isOrdered != ordered {
+			break
+		}
+
+		// 項目內容：第一行去掉符號，之後縮排比符號深的行（或緊接的延續行）都屬於這個項目
+		contentIndent := len(match[0])
+		item := []string{lines[i][len(match[0]):]}
+		loose := false
+		i++
+		for i < len(lines) {
+			line := lines[i]
+			if strings.TrimSpace(line) == "" {
+				// 空行之後還有縮排的內容才算同一個項目
+				if i+1 < len(lines) && leadingSpaces(lines[i+1]) > indent && strings.TrimSpace(lines[i+1]) != "" {
+					item = append(item, "")
+					loose = true
+					i++
+					continue
+				}
+				break
+			}
+			if leadingSpaces(line) > indent {
+				item = append(item, dedent(line, contentIndent))
+				i++
+				continue
+			}
+			if listItemRegex.MatchString(line) || r.startsBlock(lines, i) {
+				break
+			}
+			item = append(item, line)
+			i++
+		}
+
+		b.WriteString("<li>")
+		if task := taskItemRegex.FindStringSubmatch(item[0]); task != nil && !ordered {
+			checked := ""
+			if task[1] != " " {
+				checked = ` checked="checked"`
+			}
+			fmt.Fprintf(b, `<input type="checkbox" disabled="disabled"%s%s `, checked, r.voidEnd())
+			item[0] = item[0][len(task[0]):]
+		}
+		r.renderListItem(b, item, loose)
+		b.WriteString("</li>\n")
+
+		// 項目之間的空行
+		if i < len(lines) && strings.TrimSpace(lines[i]) == "" && i+1 < len(lines) {
+			if next := listItemRegex.FindStringSubmatch(lines[i+1]); next != nil && len(next[1]) == indent {
+				i++
+			}
+		}
+	}
+
+	fmt.Fprintf(b, "</%s>\n", tag)
+	return i
+}
+
+// renderListItem 輸出清單項目的內容；緊湊清單的第一段不包 <p>
+func (r *MarkdownRenderer) renderListItem(b *strings.Builder, item []string, loose bool) {
+	if loose {
+		b.WriteString("\n")
+		r.renderBlocks(b, item)
+		return
+	}
+
+	// 第一段文字直接輸出，其餘（例如巢狀清單）照一般區塊處理
+	n := 0
+	for n < len(item) && strings.TrimSpace(item[n]) != "" && (n == 0 || !r.startsBlock(item, n)) {
+		n++
+	}
+	if n > 0 && !r.startsBlock(item, 0) {
+		b.WriteString(r.renderInline(strings.Join(item[:n], "\n")))
+		item = item[n:]
+	}
+	if len(item) > 0 {
+		b.WriteString("\n")
+		r.renderBlocks(b, item)
+	}
+}
+
+func leadingSpaces(line string) int {
+	n := 0
+	for _, c := range line {
+		switch c {
+		case ' ':
+			n++
+		case '\t':
+			n += 4
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// dedent 去掉最多 n 個前置空白
+func dedent(line string, n int) string {
+	for i := 0; i < n; i++ {
+		switch {
+		case strings.HasPrefix(line, " "):
+			line = line[1:]
+		case strings.HasPrefix(line, "\t"):
+			line = line[1:]
+			i += 3
+		default:
+			return line
+		}
+	}
+	return line
+}
+
+// inlineTags 段落中可以直接使用的 HTML 標籤
+var inlineTags = regexp.MustCompile(`^</?(?:br|kbd|sub|sup|b|i|u|em|strong|code|span|mark|small|del|ins|img|a|font)(?:\s[^<>]*)?/?>`)
+
+var autolinkRegex = regexp.MustCompile(`^<(https?://[^\s<>]+)>`)
+
+// renderInline 轉換行內語法
+func (r *MarkdownRenderer) renderInline(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		rest := text[i:]
+		switch c := text[i]; {
+		case c == '\\' && i+1 < len(text) && strings.ContainsRune("\\`*_{}[]()#+-.!|<>~", rune(text[i+1])):
+			b.WriteString(html.EscapeString(text[i+1 : i+2]))
+			i += 2
+
+		case c == '`':
+			ticks := len(rest) - len(strings.TrimLeft(rest, "`"))
+			end := strings.Index(rest[ticks:], rest[:ticks])
+			if end < 0 {
+				b.WriteString(rest[:ticks])
+				i += ticks
+				continue
+			}
+			code := rest[ticks : ticks+end]
+			if strings.TrimSpace(code) != "" && strings.HasPrefix(code, " ") && strings.HasSuffix(code, " ") {
+				code = code[1 : len(code)-1]
+			}
+			fmt.Fprintf(&b, "<code>%s</code>", html.EscapeString(strings.ReplaceAll(code, "\n", " ")))
+			i += ticks + end + ticks
+
+		case c == '!' && strings.HasPrefix(rest, "!["):
+			if label, dest, n, ok := parseLink(rest[1:]); ok {
+				src, title := splitLinkTitle(dest)
+				if r.ResolveImage != nil {
+					src = r.ResolveImage(src)
+				}
+				titleAttr := ""
+				if title != "" {
+					titleAttr = fmt.Sprintf(` title="%s"`, html.EscapeString(title))
+				}
+				fmt.Fprintf(&b, `<img src="%s" alt="%s"%s%s`, html.EscapeString(src), html.EscapeString(plainText(r.renderInline(label))), titleAttr, r.voidEnd())
+				i += 1 + n
+				continue
+			}
+			b.WriteString("!")
+			i++
+
+		case c == '[':
+			if label, dest, n, ok := parseLink(rest); ok {
+				href, title := splitLinkTitle(dest)
+				titleAttr := ""
+				if title != "" {
+					titleAttr = fmt.Sprintf(` title="%s"`, html.EscapeString(title))
+				}
+				fmt.Fprintf(&b, `<a href="%s"%s>%s</a>`, html.EscapeString(href), titleAttr, r.renderInline(label))
+				i += n
+				continue
+			}
+			b.WriteString("[")
+			i++
+
+		case c == '<':
+			if match := autolinkRegex.FindStringSubmatch(rest); match != nil {
+				fmt.Fprintf(&b, `<a href="%s">%s</a>`, html.EscapeString(match[1]), html.EscapeString(match[1]))
+				i += len(match[0])
+				continue
+			}
+			if tag := inlineTags.FindString(rest); tag != "" && !r.EscapeHTML {
+				i += len(tag)
+				if r.XHTML && (strings.HasPrefix(tag, "<br") || strings.HasPrefix(tag, "<img")) && !strings.HasSuffix(tag, "/>") {
+					tag = strings.TrimSuffix(tag, ">") + "/>"
+				}
+				b.WriteString(tag)
+				continue
+			}
+			b.WriteString("&lt;")
+			i++
+
+		case c == '*' || c == '_' || c == '~':
+			// _ 在字詞中間（例如 snake_case）不算強調
+			if c != '_' || i == 0 || !isWordByte(text[i-1]) {
+				if n, ok := r.renderEmphasis(&b, rest); ok {
+					i += n
+					continue
+				}
+			}
+			b.WriteByte(c)
+			i++
+
+		case c == '\n':
+			// 行尾兩個空白或反斜線代表換行
+			if strings.HasSuffix(b.String(), "  ") {
+				trimmed := strings.TrimRight(b.String(), " ")
+				b.Reset()
+				b.WriteString(trimmed + r.voidTag("br"))
+			}
+			b.WriteByte('\n')
+			i++
+
+		default:
+			// 一次處理到下一個特殊字元
+			next := strings.IndexAny(rest[1:], "\\`![<*_~\n")
+			if next < 0 {
+				next = len(rest) - 1
+			}
+			b.WriteString(html.EscapeString(rest[:next+1]))
+			i += next + 1
+		}
+	}
+	return b.String()
+}
+
+// renderEmphasis 處理 ***粗斜體***、**粗體**、*斜體*、~~刪除線~~；_ 只在字詞邊界生效，避免誤判 snake_case
+func (r *MarkdownRenderer) renderEmphasis(b *strings.Builder, text string) (int, bool) {
+	if strings.HasPrefix(text, "***") {
+		if end := closingDelimiter(text[3:], "***"); end > 0 {
+			fmt.Fprintf(b, "<strong><em>%s</em></strong>", r.renderInline(text[3:3+end]))
+			return end + 6, true
+		}
+	}
+	for _, d := range []struct{ delim, tag string }{
+		{"**", "strong"}, {"__", "strong"}, {"~~", "del"}, {"*", "em"}, {"_", "em"},
+	} {
+		if !strings.HasPrefix(text, d.delim) {
+			continue
+		}
+		inner := text[len(d.delim):]
+		if inner == "" || inner[0] == ' ' || inner[0] == '\n' {
+			continue
+		}
+		end := closingDelimiter(inner, d.delim)
+		if end <= 0 {
+			continue
+		}
+		after := inner[end+len(d.delim):]
+		if d.delim[0] == '_' && after != "" && isWordByte(after[0]) {
+			continue
+		}
+		fmt.Fprintf(b, "<%s>%s</%s>", d.tag, r.renderInline(inner[:end]), d.tag)
+		return len(d.delim) + end + len(d.delim), true
+	}
+	return 0, false
+}
+
+// closingDelimiter 尋找結束的強調符號：前面不是空白，且不在行內程式碼中
+func closingDelimiter(text, delim string) int {
+	inCode := false
+	for i := 0; i < len(text); i++ {
+		switch {
+		case text[i] == '\\':
+			i++
+		case text[i] == '`':
+			inCode = !inCode
+		case !inCode && strings.HasPrefix(text[i:], delim) && i > 0 && text[i-1] != ' ':
+			// ** 之中的 * 不算結束
+			if len(delim) == 1 && strings.HasPrefix(text[i:], delim+delim) {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// parseLink 解析 [label](dest)，回傳 label、dest 與佔用的長度
+func parseLink(text string) (label, dest string, n int, ok bool) {
+	depth := 0
+	closeBracket := -1
+	for i := 0; i < len(text) && closeBracket < 0; i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				closeBracket = i
+			}
+		}
+	}
+	if closeBracket < 0 || closeBracket+1 >= len(text) || text[closeBracket+1] != '(' {
+		return "", "", 0, false
+	}
+
+	depth = 0
+	for i := closeBracket + 1; i < len(text); i++ {
+		switch text[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return text[1:closeBracket], strings.TrimSpace(text[closeBracket+2 : i]), i + 1, true
+			}
+		case '\n':
+			return "", "", 0, false
+		}
+	}
+	return "", "", 0, false
+}
+
+// splitLinkTitle 分開連結網址與 "title"
+func splitLinkTitle(dest string) (string, string) {
+	if strings.HasSuffix(dest, `"`) {
+		if i := strings.Index(dest, ` "`); i >= 0 {
+			return strings.Trim(dest[:i], "<>"), dest[i+2 : len(dest)-1]
+		}
+	}
+	return strings.Trim(dest, "<>"), ""
+}
+
+func (r *MarkdownRenderer) voidTag(tag string) string {
+	return "<" + tag + r.voidEnd()
+}
+
+func (r *MarkdownRenderer) voidEnd() string {
+	if r.XHTML {
+		return "/>"
+	}
+	return ">"
+}
+
+var tagRegex = regexp.MustCompile(`<[^>]*>`)
+
+// plainText 去除 HTML 標籤並還原字元實體
+func plainText(htmlText string) string {
+	return strings.TrimSpace(html.UnescapeString(tagRegex.ReplaceAllString(htmlText, "")))
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown_Blocks(t *testing.T) {
+	input := "# 第十章 Go 的並行\n" +
+		"\n" +
+		"段落第一行\n" +
+		"第二行\n" +
+		"\n" +
+		"- 項目一\n" +
+		"  - 子項目\n" +
+		"- [x] 已完成\n" +
+		"\n" +
+		"1. 第一步\n" +
+		"2. 第二步\n" +
+		"\n" +
+		"> 引言\n" +
+		"\n" +
+		"| 名稱 | 說明 |\n" +
+		"|:-----|-----:|\n" +
+		"| `a|b` | 值 |\n" +
+		"\n" +
+		"```go\n" +
+		"x := 1\n" +
+		"```\n" +
+		"\n" +
+		"---\n"
+
+	got := RenderMarkdown(input)
+	for _, want := range []string{
+		`<h1 id="第十章-Go-的並行">第十章 Go 的並行</h1>`,
+		"<p>段落第一行\n第二行</p>",
+		"<ul>\n<li>項目一\n<ul>\n<li>子項目</li>\n</ul>\n</li>",
+		`<li><input type="checkbox" disabled="disabled" checked="checked"> 已完成</li>`,
+		"<ol>\n<li>第一步</li>\n<li>第二步</li>\n</ol>",
+		"<blockquote>\n<p>引言</p>\n</blockquote>",
+		`<th style="text-align: left">名稱</th><th style="text-align: right">說明</th>`,
+		`<td style="text-align: left"><code>a|b</code></td>`,
+		`<pre><code class="language-go">x := <span class="num">1</span></code></pre>`,
+		"<hr>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Output should contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestRenderMarkdown_Inline(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"**粗體** 與 *斜體* 與 ***兩者***", "<p><strong>粗體</strong> 與 <em>斜體</em> 與 <strong><em>兩者</em></strong></p>\n"},
+		{"snake_case_name 與 _強調_", "<p>snake_case_name 與 <em>強調</em></p>\n"},
+		{"`a < b` 與 a < b", "<p><code>a &lt; b</code> 與 a &lt; b</p>\n"},
+		{"[Go](https://go.dev \"官網\") ~~舊~~", "<p><a href=\"https://go.dev\" title=\"官網\">Go</a> <del>舊</del></p>\n"},
+		{"換行  \n下一行", "<p>換行<br>\n下一行</p>\n"},
+		{"2 * 3 * 4", "<p>2 * 3 * 4</p>\n"},
+		{`\*不是斜體\*`, "<p>*不是斜體*</p>\n"},
+	}
+	for _, tt := range tests {
+		if got := RenderMarkdown(tt.input); got != tt.want {
+			t.Errorf("RenderMarkdown(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestMarkdownRenderer_ImagesAndXHTML(t *testing.T) {
+	r := &MarkdownRenderer{
+		XHTML:        true,
+		ResolveImage: func(src string) string { return strings.Replace(src, "attachment:", "images/", 1) },
+	}
+	got := r.Render("![圖](attachment:a.png)  \n文字<br>• 項目\n\n## 小節")
+
+	if !strings.Contains(got, `<img src="images/a.png" alt="圖"/>`) || !strings.Contains(got, "文字<br/>• 項目") {
+		t.Errorf("Expected XHTML image and line break, got %q", got)
+	}
+	if len(r.Headings) != 1 || r.Headings[0].Level != 2 || r.Headings[0].ID != "小節" {
+		t.Errorf("Unexpected headings: %+v", r.Headings)
+	}
+}
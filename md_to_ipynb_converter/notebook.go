@@ -1,6 +1,11 @@
 package main
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
 
 // Notebook 代表 Jupyter Notebook 的完整結構
 type Notebook struct {
@@ -12,21 +17,86 @@ type Notebook struct {
 
 // Cell 代表單一 cell
 type Cell struct {
-	CellType       string       `json:"cell_type"`
-	ID             string       `json:"id"`
-	Metadata       CellMetadata `json:"metadata"`
-	Source         []string     `json:"source"`
-	ExecutionCount *int         `json:"execution_count,omitempty"`
-	Outputs        []any        `json:"outputs,omitempty"`
+	CellType       string          `json:"cell_type"`
+	ID             string          `json:"id"`
+	Metadata       CellMetadata    `json:"metadata"`
+	Source         MultilineString `json:"source"`
+	ExecutionCount *int            `json:"execution_count,omitempty"`
+	Outputs        []any           `json:"outputs,omitempty"`
+	// Attachments markdown cell 中以 attachment:name 引用的圖片（檔名 -> MIME 類型 -> base64）
+	Attachments map[string]map[string]MultilineString `json:"attachments,omitempty"`
+
+	// explicitID 表示 ID 是在 Markdown 標記中明確指定的（不會寫入 JSON）
+	explicitID bool
+}
+
+// CellMetadata cell 的 metadata（保留 Jupyter 寫入的任意欄位）
+type CellMetadata map[string]any
+
+// MultilineString nbformat 的多行字串，讀取時可接受字串或字串陣列
+type MultilineString []string
+
+// UnmarshalJSON 同時支援 "source": "..." 與 "source": ["...", "..."]
+func (s *MultilineString) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*s = splitLines(text)
+		return nil
+	}
+
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err != nil {
+		return err
+	}
+	*s = lines
+	return nil
 }
 
-// CellMetadata cell 的 metadata（目前為空）
-type CellMetadata struct{}
+// Text 回傳 cell 的完整內容
+func (c Cell) Text() string {
+	return strings.Join(c.Source, "")
+}
 
 // NotebookMetadata notebook 的 metadata
 type NotebookMetadata struct {
 	Kernelspec   Kernelspec   `json:"kernelspec"`
 	LanguageInfo LanguageInfo `json:"language_info"`
+
+	// Extra 保存 kernelspec 與 language_info 以外的欄位，讓讀寫既有 notebook 不會遺失資料
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON 將 Extra 欄位與已知欄位一起輸出
+func (m NotebookMetadata) MarshalJSON() ([]byte, error) {
+	fields := map[string]any{}
+	for key, value := range m.Extra {
+		fields[key] = value
+	}
+	fields["kernelspec"] = m.Kernelspec
+	fields["language_info"] = m.LanguageInfo
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON 讀取已知欄位，其餘欄位放進 Extra
+func (m *NotebookMetadata) UnmarshalJSON(data []byte) error {
+	type plain NotebookMetadata
+	var known plain
+	if err := json.Unmarshal(data, &known); err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	delete(raw, "kernelspec")
+	delete(raw, "language_info")
+
+	*m = NotebookMetadata(known)
+	if len(raw) > 0 {
+		m.Extra = raw
+	}
+	return nil
 }
 
 // Kernelspec kernel 設定
@@ -38,32 +108,64 @@ type Kernelspec struct {
 
 // LanguageInfo 語言資訊
 type LanguageInfo struct {
-	FileExtension string `json:"file_extension"`
-	MimeType      string `json:"mimetype"`
-	Name          string `json:"name"`
+	CodemirrorMode    any    `json:"codemirror_mode,omitempty"`
+	FileExtension     string `json:"file_extension"`
+	MimeType          string `json:"mimetype"`
+	Name              string `json:"name"`
+	NbconvertExporter string `json:"nbconvert_exporter,omitempty"`
+	PygmentsLexer     string `json:"pygments_lexer,omitempty"`
+	Version           string `json:"version,omitempty"`
+}
+
+// kernelProfiles 支援的 Go kernel 設定
+var kernelProfiles = map[string]NotebookMetadata{
+	"gophernotes": {
+		Kernelspec: Kernelspec{
+			DisplayName: "Go",
+			Language:    "go",
+			Name:        "gophernotes",
+		},
+		LanguageInfo: LanguageInfo{
+			FileExtension: ".go",
+			MimeType:      "text/x-go",
+			Name:          "go",
+		},
+	},
+	"gonb": {
+		Kernelspec: Kernelspec{
+			DisplayName: "Go (gonb)",
+			Language:    "go",
+			Name:        "gonb",
+		},
+		LanguageInfo: LanguageInfo{
+			FileExtension: ".go",
+			MimeType:      "text/x-go",
+			Name:          "go",
+		},
+	},
 }
 
 // NewNotebook 創建新的 Go Notebook
 func NewNotebook() *Notebook {
 	return &Notebook{
-		Cells: []Cell{},
-		Metadata: NotebookMetadata{
-			Kernelspec: Kernelspec{
-				DisplayName: "Go",
-				Language:    "go",
-				Name:        "gophernotes",
-			},
-			LanguageInfo: LanguageInfo{
-				FileExtension: ".go",
-				MimeType:      "text/x-go",
-				Name:          "go",
-			},
-		},
+		Cells:         []Cell{},
+		Metadata:      kernelProfiles["gophernotes"],
 		NBFormat:      4,
 		NBFormatMinor: 4,
 	}
 }
 
+// SetKernel 套用指定的 kernel 設定（gophernotes 或 gonb）
+func (nb *Notebook) SetKernel(profile string) error {
+	metadata, ok := kernelProfiles[profile]
+	if !ok {
+		return fmt.Errorf("unknown kernel %q", profile)
+	}
+	metadata.Extra = nb.Metadata.Extra
+	nb.Metadata = metadata
+	return nil
+}
+
 // AddMarkdownCell 新增 markdown cell
 func (nb *Notebook) AddMarkdownCell(id, content string) {
 	cell := Cell{
@@ -88,29 +190,54 @@ func (nb *Notebook) AddCodeCell(id, content string) {
 	nb.Cells = append(nb.Cells, cell)
 }
 
+// AddRawCell 新增 raw cell
+func (nb *Notebook) AddRawCell(id, content string) {
+	cell := Cell{
+		CellType: "raw",
+		ID:       id,
+		Metadata: CellMetadata{},
+		Source:   splitLines(content),
+	}
+	nb.Cells = append(nb.Cells, cell)
+}
+
 // ToJSON 輸出 JSON 格式
 func (nb *Notebook) ToJSON() ([]byte, error) {
 	return json.MarshalIndent(nb, "", "  ")
 }
 
+// ReadNotebook 讀取既有的 .ipynb 檔案
+func ReadNotebook(path string) (*Notebook, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var nb Notebook
+	if err := json.Unmarshal(data, &nb); err != nil {
+		return nil, fmt.Errorf("invalid notebook %s: %w", path, err)
+	}
+	for i := range nb.Cells {
+		if nb.Cells[i].Metadata == nil {
+			nb.Cells[i].Metadata = CellMetadata{}
+		}
+		// 既有 notebook 的 ID 都是穩定的，比對時可以直接使用
+		nb.Cells[i].explicitID = nb.Cells[i].ID != ""
+	}
+
+	return &nb, nil
+}
+
 // splitLines 將字串分割成行（保留換行符）
 func splitLines(content string) []string {
 	if content == "" {
 		return []string{}
 	}
 
-	lines := []string{}
-	current := ""
-
-	for i, ch := range content {
-		current += string(ch)
-		if ch == '\n' {
-			lines = append(lines, current)
-			current = ""
-		} else if i == len(content)-1 {
-			// 最後一行沒有換行符
-			lines = append(lines, current)
-		}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		// 結尾是換行符時 SplitAfter 會多出一個空字串
+		lines = lines[:len(lines)-1]
 	}
 
 	return lines
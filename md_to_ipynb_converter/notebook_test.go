@@ -150,6 +150,11 @@ func TestSplitLines(t *testing.T) {
 			input:    "line1\nline2\nline3",
 			expected: []string{"line1\n", "line2\n", "line3"},
 		},
+		{
+			name:     "last line ends with multibyte character",
+			input:    "## 總結\n\n成功了！✨",
+			expected: []string{"## 總結\n", "\n", "成功了！✨"},
+		},
 	}
 
 	for _, tt := range tests {
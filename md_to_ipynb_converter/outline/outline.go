@@ -0,0 +1,223 @@
+// Package outline 解析全書目錄（目錄.md）成為章、節、小節的樹狀結構
+//
+// 目錄.md 的格式：
+//
+//	## 第十章 Go 的並行 .................................................... 203
+//	- goroutine ......................................................... 203
+//	- channel ........................................................... 205
+//	  - 讀取、寫入與緩衝 ................................................ 205
+//	  - for-range 與 channel ............................................. 206
+//
+// 章是 ## 標題，節是 - 開頭的項目，小節是縮排兩格的項目；虛線之後是頁碼。
+// 上面這段（節錄自第十章）解析成 Chapter{Number: 10, Numeral: "十", Title: "Go 的並行", Page: "203"}，
+// 其中「channel」一節（205 頁）有「讀取、寫入與緩衝」（205 頁）、「for-range 與 channel」（206 頁）兩個小節。
+// 沒有章號的 ## 標題（例如「前言」、「索引」）也會列為一章，Number 為 0。
+package outline
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FileName 全書目錄的檔名，位於專案根目錄
+const FileName = "目錄.md"
+
+// Outline 整本書的目錄
+type Outline struct {
+	Title    string    `json:"title"`
+	Chapters []Chapter `json:"chapters"`
+}
+
+// Chapter 一章
+type Chapter struct {
+	// Number 章號；前言、索引等沒有章號的項目為 0
+	Number int `json:"number"`
+	// Numeral 目錄中的寫法，例如「十」
+	Numeral  string    `json:"numeral,omitempty"`
+	Title    string    `json:"title"`
+	Page     string    `json:"page,omitempty"`
+	Sections []Section `json:"sections,omitempty"`
+}
+
+// Section 節
+type Section struct {
+	Title       string       `json:"title"`
+	Page        string       `json:"page,omitempty"`
+	Subsections []Subsection `json:"subsections,omitempty"`
+}
+
+// Subsection 小節
+type Subsection struct {
+	Title string `json:"title"`
+	Page  string `json:"page,omitempty"`
+}
+
+var (
+	// titleRegex 符合 `# Learning Go - 目錄`
+	titleRegex = regexp.MustCompile(`^#\s+(.*?)\s*$`)
+	// chapterRegex 符合 `## 第十章 Go 的並行 ...... 203` 與 `## 前言 ...... xiii`
+	chapterRegex = regexp.MustCompile(`^##\s+(.*)$`)
+	// numberedRegex 由章名取出章號
+	numberedRegex = regexp.MustCompile(`^第([零一二三四五六七八九十百\d]+)章\s+(.*)$`)
+	// itemRegex 符合節（- ）與小節（縮排的 - ）
+	itemRegex = regexp.MustCompile(`^(\s*)[-*]\s+(.*)$`)
+	// leaderRegex 虛線與頁碼
+	leaderRegex = regexp.MustCompile(`\s*\.{2,}\s*(\S*)\s*$`)
+)
+
+// ParseFile 讀取並解析目錄檔
+func ParseFile(path string) (*Outline, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	outline, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return outline, nil
+}
+
+// Parse 解析目錄；節出現在第一章之前、小節沒有所屬的節時回報錯誤
+func Parse(r io.Reader) (*Outline, error) {
+	outline := &Outline{}
+	scanner := bufio.NewScanner(r)
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimRight(scanner.Text(), " \t")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if match := chapterRegex.FindStringSubmatch(line); match != nil {
+			outline.Chapters = append(outline.Chapters, parseChapter(match[1]))
+			continue
+		}
+		if match := titleRegex.FindStringSubmatch(line); match != nil {
+			if outline.Title == "" {
+				outline.Title = match[1]
+			}
+			continue
+		}
+
+		match := itemRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue // 說明文字
+		}
+		title, page := splitPage(match[2])
+		if len(outline.Chapters) == 0 {
+			return nil, fmt.Errorf("line %d: section %q appears before any chapter", lineNo, title)
+		}
+		chapter := &outline.Chapters[len(outline.Chapters)-1]
+
+		switch indent := len(strings.ReplaceAll(match[1], "\t", "  ")); {
+		case indent == 0:
+			chapter.Sections = append(chapter.Sections, Section{Title: title, Page: page})
+		case indent <= 3:
+			if len(chapter.Sections) == 0 {
+				return nil, fmt.Errorf("line %d: subsection %q has no section", lineNo, title)
+			}
+			section := &chapter.Sections[len(chapter.Sections)-1]
+			section.Subsections = append(section.Subsections, Subsection{Title: title, Page: page})
+		default:
+			return nil, fmt.Errorf("line %d: %q is nested deeper than a subsection", lineNo, title)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return outline, nil
+}
+
+// parseChapter 解析 ## 之後的文字
+func parseChapter(text string) Chapter {
+	title, page := splitPage(text)
+	chapter := Chapter{Title: title, Page: page}
+	if match := numberedRegex.FindStringSubmatch(title); match != nil {
+		if number, ok := ChineseNumber(match[1]); ok {
+			chapter.Number = number
+			chapter.Numeral = match[1]
+			chapter.Title = match[2]
+		}
+	}
+	return chapter
+}
+
+// splitPage 分開標題與虛線後的頁碼
+func splitPage(text string) (title, page string) {
+	if loc := leaderRegex.FindStringSubmatchIndex(text); loc != nil {
+		return strings.TrimSpace(text[:loc[0]]), text[loc[2]:loc[3]]
+	}
+	return strings.TrimSpace(text), ""
+}
+
+// Label 回傳「第十章 Go 的並行」這樣的標題；沒有章號時只有章名
+func (c Chapter) Label() string {
+	if c.Number == 0 {
+		return c.Title
+	}
+	numeral := c.Numeral
+	if numeral == "" {
+		numeral = strconv.Itoa(c.Number)
+	}
+	return "第" + numeral + "章 " + c.Title
+}
+
+// Numbered 回傳有章號的章，依目錄順序
+func (o *Outline) Numbered() []Chapter {
+	var chapters []Chapter
+	for _, chapter := range o.Chapters {
+		if chapter.Number > 0 {
+			chapters = append(chapters, chapter)
+		}
+	}
+	return chapters
+}
+
+// Chapter 依章號尋找一章
+func (o *Outline) Chapter(number int) (Chapter, bool) {
+	for _, chapter := range o.Chapters {
+		if chapter.Number == number && number > 0 {
+			return chapter, true
+		}
+	}
+	return Chapter{}, false
+}
+
+// ChineseNumber 將「十三」這類中文數字（或阿拉伯數字）轉成整數，支援到九百九十九
+func ChineseNumber(text string) (int, bool) {
+	if n, err := strconv.Atoi(text); err == nil {
+		return n, true
+	}
+
+	digits := map[rune]int{'零': 0, '一': 1, '二': 2, '三': 3, '四': 4, '五': 5, '六': 6, '七': 7, '八': 8, '九': 9}
+	total, current := 0, 0
+	for _, r := range text {
+		switch r {
+		case '百', '十':
+			unit := 10
+			if r == '百' {
+				unit = 100
+			}
+			if current == 0 {
+				current = 1 // 「十三」省略了「一」
+			}
+			total += current * unit
+			current = 0
+		default:
+			digit, ok := digits[r]
+			if !ok {
+				return 0, false
+			}
+			current = digit
+		}
+	}
+	return total + current, text != ""
+}
@@ -0,0 +1,117 @@
+package outline
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	input := `# Learning Go - 目錄
+
+## 前言 ............ xiii
+
+## 第三章 複合型態 ..... 35
+- 陣列—很難直接使用 ..... 35
+- slice ..... 37
+  - len ..... 39
+- var vs. := ..... 27
+
+## 第十三章 編寫測試 ..... 277
+- 沒有頁碼的節
+`
+	got, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	want := &Outline{
+		Title: "Learning Go - 目錄",
+		Chapters: []Chapter{
+			{Title: "前言", Page: "xiii"},
+			{Number: 3, Numeral: "三", Title: "複合型態", Page: "35", Sections: []Section{
+				{Title: "陣列—很難直接使用", Page: "35"},
+				{Title: "slice", Page: "37", Subsections: []Subsection{{Title: "len", Page: "39"}}},
+				{Title: "var vs. :=", Page: "27"},
+			}},
+			{Number: 13, Numeral: "十三", Title: "編寫測試", Page: "277", Sections: []Section{
+				{Title: "沒有頁碼的節"},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := map[string]string{
+		"- 節 .... 1\n": "line 1: section",
+		"## 第一章 A .... 1\n  - 小節 .... 2\n":          "line 2: subsection",
+		"## 第一章 A .... 1\n- 節\n      - 太深 .... 2\n": "line 3:",
+	}
+	for input, want := range tests {
+		_, err := Parse(strings.NewReader(input))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) error = %v, want %q", input, err, want)
+		}
+	}
+}
+
+func TestParseFile_Book(t *testing.T) {
+	path := filepath.Join("..", "..", FileName)
+	if _, err := os.Stat(path); err != nil {
+		t.Skip("目錄.md not found")
+	}
+	book, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	numbered := book.Numbered()
+	if len(numbered) != 15 {
+		t.Fatalf("Expected 15 numbered chapters, got %d", len(numbered))
+	}
+	for i, chapter := range numbered {
+		if chapter.Number != i+1 {
+			t.Errorf("Chapter %d has number %d", i+1, chapter.Number)
+		}
+	}
+
+	ch10, ok := book.Chapter(10)
+	if !ok || ch10.Label() != "第十章 Go 的並行" || ch10.Page != "203" {
+		t.Errorf("Unexpected chapter 10: %+v", ch10)
+	}
+	if _, ok := book.Chapter(0); ok {
+		t.Error("Chapter(0) should not match unnumbered chapters")
+	}
+}
+
+func TestChapter_JSON(t *testing.T) {
+	chapter := Chapter{Number: 6, Numeral: "六", Title: "指標", Page: "113", Sections: []Section{{Title: "指標入門", Page: "113"}}}
+	data, err := json.Marshal(chapter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"number":6,"numeral":"六","title":"指標","page":"113","sections":[{"title":"指標入門","page":"113"}]}`
+	if string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+}
+
+func TestChineseNumber(t *testing.T) {
+	tests := map[string]int{"一": 1, "十": 10, "十三": 13, "二十": 20, "二十一": 21, "一百零五": 105, "15": 15}
+	for text, want := range tests {
+		if got, ok := ChineseNumber(text); !ok || got != want {
+			t.Errorf("ChineseNumber(%q) = %d, %v; want %d", text, got, ok, want)
+		}
+	}
+	for _, text := range []string{"", "章"} {
+		if _, ok := ChineseNumber(text); ok {
+			t.Errorf("ChineseNumber(%q) should fail", text)
+		}
+	}
+}
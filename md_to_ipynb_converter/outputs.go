@@ -0,0 +1,112 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Output code cell 的單一輸出（nbformat 的 stream、execute_result、display_data、error）
+type Output struct {
+	// Type nbformat 的 output_type
+	Type string
+	// Name stream 的名稱：stdout 或 stderr
+	Name string
+	// Text stream 的文字或 error 的 traceback（皆已去除 ANSI 色碼）
+	Text string
+	// Data execute_result / display_data 的內容（MIME 類型 -> 內容）
+	Data map[string]string
+}
+
+// ansiRegex 終端機色碼，traceback 中常見
+var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// CellOutputs 將 cell 的原始 outputs 轉成 Output；無法辨識的輸出會被略過
+func CellOutputs(cell Cell) []Output {
+	var outputs []Output
+	for _, raw := range cell.Outputs {
+		fields, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		var output Output
+		output.Type, _ = fields["output_type"].(string)
+		switch output.Type {
+		case "stream":
+			output.Name, _ = fields["name"].(string)
+			// gonb 的 stderr 會以色碼標示發生錯誤的 cell 行號
+			output.Text = ansiRegex.ReplaceAllString(joinText(fields["text"], ""), "")
+		case "execute_result", "display_data":
+			data, _ := fields["data"].(map[string]any)
+			output.Data = map[string]string{}
+			for mime, value := range data {
+				output.Data[mime] = joinText(value, "")
+			}
+		case "error":
+			output.Text = ansiRegex.ReplaceAllString(joinText(fields["traceback"], "\n"), "")
+			if output.Text == "" {
+				ename, _ := fields["ename"].(string)
+				evalue, _ := fields["evalue"].(string)
+				output.Text = ename + ": " + evalue
+			}
+		default:
+			continue
+		}
+		outputs = append(outputs, output)
+	}
+	return outputs
+}
+
+// PreferredMIME 回傳最適合顯示的 MIME 類型，依 preferred 的順序；都沒有時回傳空字串
+func (o Output) PreferredMIME(preferred ...string) string {
+	for _, mime := range preferred {
+		if _, ok := o.Data[mime]; ok {
+			return mime
+		}
+	}
+	return ""
+}
+
+// ImageMIMEs 支援以圖片輸出的 MIME 類型，依偏好排序
+var ImageMIMEs = []string{"image/png", "image/jpeg", "image/gif", "image/svg+xml"}
+
+// joinText 將 nbformat 的多行字串（字串或字串陣列）以 sep 合併；traceback 的元素不含換行符，要以 "\n" 合併
+func joinText(value any, sep string) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case []any:
+		var lines []string
+		for _, line := range value {
+			if text, ok := line.(string); ok {
+				lines = append(lines, text)
+			}
+		}
+		return strings.Join(lines, sep)
+	}
+	return ""
+}
+
+// attachmentData 回傳 attachment 的 MIME 類型與 base64 內容，依 ImageMIMEs 的順序挑選
+func attachmentData(cell Cell, name string) (mime, data string, ok bool) {
+	bundle, ok := cell.Attachments[name]
+	if !ok {
+		return "", "", false
+	}
+	for _, mime := range ImageMIMEs {
+		if value, ok := bundle[mime]; ok {
+			return mime, strings.Join(value, ""), true
+		}
+	}
+	// 其他類型依名稱排序，結果才會穩定
+	mimes := make([]string, 0, len(bundle))
+	for mime := range bundle {
+		mimes = append(mimes, mime)
+	}
+	sort.Strings(mimes)
+	if len(mimes) == 0 {
+		return "", "", false
+	}
+	return mimes[0], strings.Join(bundle[mimes[0]], ""), true
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"regexp"
@@ -15,81 +17,163 @@ const (
 	Unknown CellType = iota
 	MarkdownCell
 	CodeCell
+	// ExerciseCell、QuestionCell 面試考題的練習與題目，見 interview.go
+	ExerciseCell
+	QuestionCell
+	// RawCell 不執行也不渲染的 raw cell，對應 percent 格式的 // %% [raw]
+	RawCell
 )
 
 // Parser Markdown 解析器
 type Parser struct {
+	// Config 解析設定；檔案開頭的 front matter 會覆蓋其中的欄位
+	Config Config
+	// Format 輸入格式，預設為標記 Markdown
+	Format InputFormat
+
 	scanner *bufio.Scanner
 	cellID  int
+
+	lineNo    int // 目前處理到的行（從 0 開始）
+	cellStart int // 目前 cell 開始標記所在的行
+	spans     []CellSpan
+	usedIDs   map[string]bool
+	// reservedIDs 檔案中所有明確指定的 id，產生的 ID 會避開
+	reservedIDs map[string]bool
+
+	// chapter 由檔案路徑判斷的章節，寫進題目 metadata
+	chapter   int
+	interview interviewState
+}
+
+// CellSpan 記錄 cell 在原始檔案中的行範圍（從 0 開始，包含兩端）
+type CellSpan struct {
+	Start int
+	End   int
+	// Closed 表示 End 是 END_*_CELL 結束標記
+	Closed bool
 }
 
 // NewParser 創建新的解析器
 func NewParser(r io.Reader) *Parser {
 	return &Parser{
+		Config:  DefaultConfig(),
 		scanner: bufio.NewScanner(r),
 		cellID:  0,
+		usedIDs: map[string]bool{},
+	}
+}
+
+// NewFileParser 依設定與檔名建立解析器：.go 為 percent 格式，.qmd 預設使用 quarto 方言
+func NewFileParser(r io.Reader, path string, cfg Config) *Parser {
+	p := NewParser(r)
+	p.Config = cfg
+	p.Format = FormatForPath(path)
+	p.chapter = chapterOfPath(path)
+	if strings.HasSuffix(path, ".qmd") && cfg.Dialect == "markers" {
+		p.Config.Dialect = "quarto"
 	}
+	return p
 }
 
 // Parse 解析 markdown 並返回 Notebook
 func (p *Parser) Parse() (*Notebook, error) {
+	if err := p.reserveExplicitIDs(); err != nil {
+		return nil, err
+	}
+	if p.Format == FormatPercent {
+		return p.parsePercent()
+	}
+	if dialect, ok := dialects[p.Config.Dialect]; ok {
+		return p.parseDialect(NewNotebook(), dialect)
+	}
+
 	notebook := NewNotebook()
 
 	var currentType CellType
 	var currentContent strings.Builder
+	var currentAttrs map[string]string
 
 	codeFenceRegex := regexp.MustCompile("^```go\\s*$")
 	endCodeFenceRegex := regexp.MustCompile("^```\\s*$")
 	inCodeFence := false
 
-	for p.scanner.Scan() {
+	var frontMatter []string
+	inFrontMatter := false
+
+	for ; p.scanner.Scan(); p.lineNo++ {
 		line := p.scanner.Text()
 
-		// 檢查標記
-		if strings.TrimSpace(line) == "<!-- MARKDOWN_CELL -->" {
-			// 儲存前一個 cell（如果有的話）
-			if err := p.saveCell(notebook, currentType, currentContent.String()); err != nil {
-				return nil, err
+		// 檔案開頭的 front matter
+		if p.lineNo == 0 && strings.TrimSpace(line) == "---" {
+			inFrontMatter = true
+		}
+		if inFrontMatter {
+			frontMatter = append(frontMatter, line)
+			if len(frontMatter) > 1 && strings.TrimSpace(line) == "---" {
+				inFrontMatter = false
+				if err := p.applyFrontMatter(frontMatter); err != nil {
+					return nil, err
+				}
+				// front matter 指定了方言：其餘內容交給方言解析
+				if dialect, ok := dialects[p.Config.Dialect]; ok {
+					p.lineNo++
+					p.cellStart = p.lineNo
+					return p.parseDialect(notebook, dialect)
+				}
 			}
-			currentType = MarkdownCell
-			currentContent.Reset()
 			continue
 		}
 
-		if strings.TrimSpace(line) == "<!-- END_MARKDOWN_CELL -->" {
-			// 儲存 markdown cell
-			if err := p.saveCell(notebook, currentType, currentContent.String()); err != nil {
+		// 檢查標記
+		if marker, attrs, ok := parseMarker(line); ok {
+			if handled, err := p.subBlockMarker(marker, currentType); err != nil {
 				return nil, err
+			} else if handled {
+				continue
+			}
+			closed := strings.HasPrefix(marker, "END_")
+			if p.Config.Strict {
+				if err := p.checkMarker(marker, currentType, inCodeFence); err != nil {
+					return nil, err
+				}
 			}
-			currentType = Unknown
-			currentContent.Reset()
-			continue
-		}
 
-		if strings.TrimSpace(line) == "<!-- CODE_CELL -->" {
-			// 儲存前一個 cell（如果有的話）
-			if err := p.saveCell(notebook, currentType, currentContent.String()); err != nil {
+			// 遇到任何標記都先儲存前一個 cell（如果有的話）
+			end := p.lineNo - 1
+			if closed {
+				end = p.lineNo
+			}
+			if err := p.saveCell(notebook, currentType, currentContent.String(), currentAttrs, CellSpan{p.cellStart, end, closed}); err != nil {
 				return nil, err
 			}
-			currentType = CodeCell
 			currentContent.Reset()
+			currentAttrs = attrs
 			inCodeFence = false
+			p.cellStart = p.lineNo
+
+			switch marker {
+			case "MARKDOWN_CELL":
+				currentType = MarkdownCell
+			case "CODE_CELL":
+				currentType = CodeCell
+			case "EXERCISE":
+				currentType = ExerciseCell
+			case "QUESTION":
+				currentType = QuestionCell
+			default:
+				currentType = Unknown
+			}
 			continue
 		}
 
-		if strings.TrimSpace(line) == "<!-- END_CODE_CELL -->" {
-			// 儲存 code cell
-			if err := p.saveCell(notebook, currentType, currentContent.String()); err != nil {
-				return nil, err
-			}
-			currentType = Unknown
-			currentContent.Reset()
-			inCodeFence = false
+		// QUESTION、EXERCISE 中的 ANSWER、TEST 區塊
+		if p.appendBlockLine(line) {
 			continue
 		}
 
 		// 處理 code fence
-		if currentType == CodeCell {
+		if currentType == CodeCell || currentType == ExerciseCell {
 			if codeFenceRegex.MatchString(line) {
 				inCodeFence = true
 				continue // 跳過 ```go 這一行
@@ -106,11 +190,23 @@ func (p *Parser) Parse() (*Notebook, error) {
 				currentContent.WriteString("\n")
 			}
 			currentContent.WriteString(line)
+		} else if p.Config.Strict && strings.TrimSpace(line) != "" {
+			return nil, fmt.Errorf("line %d: content outside of cell markers", p.lineNo+1)
 		}
 	}
 
+	if inFrontMatter {
+		return nil, fmt.Errorf("front matter is not closed with ---")
+	}
+	if p.Config.Strict && currentType != Unknown {
+		return nil, fmt.Errorf("line %d: cell is missing its END marker", p.cellStart+1)
+	}
+	if p.interview.block != "" {
+		return nil, fmt.Errorf("line %d: %s is missing its END marker", p.interview.blockStart+1, p.interview.block)
+	}
+
 	// 處理最後一個 cell
-	if err := p.saveCell(notebook, currentType, currentContent.String()); err != nil {
+	if err := p.saveCell(notebook, currentType, currentContent.String(), currentAttrs, CellSpan{p.cellStart, p.lineNo - 1, false}); err != nil {
 		return nil, err
 	}
 
@@ -118,26 +214,227 @@ func (p *Parser) Parse() (*Notebook, error) {
 		return nil, fmt.Errorf("scanner error: %w", err)
 	}
 
+	if err := notebook.SetKernel(p.Config.Kernel); err != nil {
+		return nil, err
+	}
+
 	return notebook, nil
 }
 
+// applyFrontMatter 將 front matter 的設定套用到 p.Config
+//
+// MyST / Quarto 文件的 front matter 也給其他工具使用，不認得的 key 會被略過；
+// 標記格式則回報錯誤，避免 kernal: gonb 之類的拼字錯誤被默默忽略。
+func (p *Parser) applyFrontMatter(lines []string) error {
+	settings, _, err := parseFrontMatter(lines)
+	if err != nil {
+		return fmt.Errorf("front matter: %w", err)
+	}
+	dialect := p.Config.Dialect
+	if value, ok := settings["dialect"]; ok {
+		dialect = value
+	}
+	if _, ok := dialects[dialect]; ok {
+		for key := range settings {
+			if !isSetting(key) {
+				delete(settings, key)
+			}
+		}
+	}
+	if err := p.Config.Apply(settings); err != nil {
+		return fmt.Errorf("front matter: %w", err)
+	}
+	return nil
+}
+
+// checkMarker 嚴格模式下檢查標記是否成對出現
+func (p *Parser) checkMarker(marker string, currentType CellType, inCodeFence bool) error {
+	lineNo := p.lineNo + 1
+	switch marker {
+	case "MARKDOWN_CELL", "CODE_CELL", "EXERCISE", "QUESTION":
+		if currentType != Unknown {
+			return fmt.Errorf("line %d: cell starting at line %d is missing its END marker", lineNo, p.cellStart+1)
+		}
+	case "END_MARKDOWN_CELL":
+		if currentType != MarkdownCell {
+			return fmt.Errorf("line %d: unexpected %s", lineNo, marker)
+		}
+	case "END_CODE_CELL":
+		if currentType != CodeCell {
+			return fmt.Errorf("line %d: unexpected %s", lineNo, marker)
+		}
+		if inCodeFence {
+			return fmt.Errorf("line %d: code fence is not closed", lineNo)
+		}
+	case "END_EXERCISE":
+		if currentType != ExerciseCell {
+			return fmt.Errorf("line %d: unexpected %s", lineNo, marker)
+		}
+	case "END_QUESTION":
+		if currentType != QuestionCell {
+			return fmt.Errorf("line %d: unexpected %s", lineNo, marker)
+		}
+	}
+	return nil
+}
+
+// Spans 回傳 Parse 後每個 cell 在原始檔案中的行範圍，順序與 notebook.Cells 相同
+func (p *Parser) Spans() []CellSpan {
+	return p.spans
+}
+
 // saveCell 儲存當前 cell 到 notebook
-func (p *Parser) saveCell(notebook *Notebook, cellType CellType, content string) error {
+func (p *Parser) saveCell(notebook *Notebook, cellType CellType, content string, attrs map[string]string, span CellSpan) error {
+	if cellType == ExerciseCell || cellType == QuestionCell {
+		return p.saveInterview(notebook, cellType, content, attrs, span)
+	}
 	if cellType == Unknown || strings.TrimSpace(content) == "" {
 		return nil
 	}
 
-	cellID := fmt.Sprintf("cell-%d", p.cellID)
+	typeName := "markdown"
+	switch cellType {
+	case CodeCell:
+		typeName = "code"
+	case RawCell:
+		typeName = "raw"
+	}
+
+	cellID := p.nextID(typeName, content)
 	p.cellID++
 
+	explicitID := attrs["id"] != ""
+	if explicitID {
+		cellID = attrs["id"]
+		if p.usedIDs[cellID] {
+			return fmt.Errorf("duplicate cell id: %s", cellID)
+		}
+	}
+	p.usedIDs[cellID] = true
+
 	switch cellType {
 	case MarkdownCell:
 		notebook.AddMarkdownCell(cellID, content)
 	case CodeCell:
 		notebook.AddCodeCell(cellID, content)
+	case RawCell:
+		notebook.AddRawCell(cellID, content)
 	default:
 		return fmt.Errorf("unknown cell type: %d", cellType)
 	}
 
+	cell := &notebook.Cells[len(notebook.Cells)-1]
+	cell.explicitID = explicitID
+	applyAttributes(cell.Metadata, p.Config.CellDefaults[typeName])
+	applyAttributes(cell.Metadata, attrs)
+	p.spans = append(p.spans, span)
+
+	return nil
+}
+
+// reserveExplicitIDs 先讀完整個檔案，記下所有明確指定的 id，之後再從頭解析；
+// 明確的 id 出現在產生 ID 的 cell 之後（例如後面的 id="cell-3"）也不會衝突
+func (p *Parser) reserveExplicitIDs() error {
+	var lines []string
+	for p.scanner.Scan() {
+		lines = append(lines, p.scanner.Text())
+	}
+	if err := p.scanner.Err(); err != nil {
+		return fmt.Errorf("scanner error: %w", err)
+	}
+
+	p.reservedIDs = map[string]bool{}
+	for _, line := range lines {
+		if _, attrs, ok := parseMarker(line); ok && attrs["id"] != "" {
+			p.reservedIDs[attrs["id"]] = true
+		}
+		if match := percentMarkerRegex.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			if _, attrs := parsePercentOptions(match[1]); attrs["id"] != "" {
+				p.reservedIDs[attrs["id"]] = true
+			}
+		}
+		for _, dialect := range dialects {
+			if key, value, ok := dialect.CellOption(line); ok && key == "id" {
+				p.reservedIDs[value] = true
+			}
+		}
+	}
+	p.scanner = bufio.NewScanner(strings.NewReader(strings.Join(lines, "\n")))
 	return nil
 }
+
+// nextID 依 Config.IDStrategy 產生 cell ID
+func (p *Parser) nextID(cellType, content string) string {
+	if p.Config.IDStrategy != "hash" {
+		// 跳過已被明確 id 佔用的編號，例如 id="cell-3"
+		for p.usedIDs[fmt.Sprintf("cell-%d", p.cellID)] || p.reservedIDs[fmt.Sprintf("cell-%d", p.cellID)] {
+			p.cellID++
+		}
+		return fmt.Sprintf("cell-%d", p.cellID)
+	}
+
+	// 依內容產生，插入或刪除其他 cell 時 ID 不會改變
+	sum := sha1.Sum([]byte(cellType + "\x00" + content))
+	id := hex.EncodeToString(sum[:4])
+	for n := 2; p.usedIDs[id] || p.reservedIDs[id]; n++ {
+		id = fmt.Sprintf("%s-%d", hex.EncodeToString(sum[:4]), n)
+	}
+	return id
+}
+
+// applyAttributes 將標記屬性寫入 cell metadata
+//
+// id 不會寫入 metadata；tags 以逗號分隔成陣列；含 . 的 key 會建立巢狀物件，
+// 例如 slideshow.slide_type="fragment" 會寫成 {"slideshow": {"slide_type": "fragment"}}
+func applyAttributes(metadata CellMetadata, attrs map[string]string) {
+	for key, value := range attrs {
+		if key == "id" {
+			continue
+		}
+
+		var parsed any = value
+		switch {
+		case key == "tags":
+			tags := []any{}
+			for _, tag := range splitList(value) {
+				tags = append(tags, tag)
+			}
+			parsed = tags
+		case value == "true" || value == "false":
+			parsed = value == "true"
+		}
+
+		parts := strings.Split(key, ".")
+		target := map[string]any(metadata)
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := target[part].(map[string]any)
+			if !ok {
+				child = map[string]any{}
+				target[part] = child
+			}
+			target = child
+		}
+		target[parts[len(parts)-1]] = parsed
+	}
+}
+
+var (
+	markerRegex    = regexp.MustCompile(`^<!--\s*((?:END_)?(?:MARKDOWN_CELL|CODE_CELL|EXERCISE|QUESTION|ANSWER|TEST))((?:\s+[\w.-]+="[^"]*")*)\s*-->$`)
+	attributeRegex = regexp.MustCompile(`([\w.-]+)="([^"]*)"`)
+)
+
+// parseMarker 解析 cell 標記，例如 <!-- CODE_CELL id="setup" --> 或 <!-- QUESTION title="零值" -->
+// 回傳標記名稱與屬性；不是標記時 ok 為 false
+func parseMarker(line string) (marker string, attrs map[string]string, ok bool) {
+	match := markerRegex.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return "", nil, false
+	}
+
+	attrs = map[string]string{}
+	for _, attr := range attributeRegex.FindAllStringSubmatch(match[2], -1) {
+		attrs[attr[1]] = attr[2]
+	}
+
+	return match[1], attrs, true
+}
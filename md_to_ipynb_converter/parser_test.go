@@ -247,3 +247,206 @@ Third`
 		}
 	}
 }
+
+func TestParser_MarkerAttributes(t *testing.T) {
+	input := `<!-- MARKDOWN_CELL id="intro" -->
+# Title
+<!-- END_MARKDOWN_CELL -->
+
+<!-- CODE_CELL -->
+` + "```go" + `
+var x = 1
+` + "```" + `
+<!-- END_CODE_CELL -->`
+
+	parser := NewParser(strings.NewReader(input))
+	notebook, err := parser.Parse()
+
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(notebook.Cells) != 2 {
+		t.Fatalf("Expected 2 cells, got %d", len(notebook.Cells))
+	}
+
+	if notebook.Cells[0].ID != "intro" {
+		t.Errorf("Expected explicit ID intro, got %s", notebook.Cells[0].ID)
+	}
+
+	if notebook.Cells[1].ID != "cell-1" {
+		t.Errorf("Expected generated ID cell-1, got %s", notebook.Cells[1].ID)
+	}
+}
+
+func TestParser_DuplicateExplicitID(t *testing.T) {
+	input := `<!-- MARKDOWN_CELL id="same" -->
+First
+<!-- END_MARKDOWN_CELL -->
+<!-- MARKDOWN_CELL id="same" -->
+Second
+<!-- END_MARKDOWN_CELL -->`
+
+	parser := NewParser(strings.NewReader(input))
+	if _, err := parser.Parse(); err == nil {
+		t.Error("Expected error for duplicate cell id, got nil")
+	}
+}
+
+func TestParser_FrontMatterUnknownKey(t *testing.T) {
+	// 標記格式：拼錯的設定要回報錯誤
+	input := "---\nkernal: gonb\n---\n<!-- MARKDOWN_CELL -->\n# Title\n<!-- END_MARKDOWN_CELL -->"
+	if _, err := NewParser(strings.NewReader(input)).Parse(); err == nil || !strings.Contains(err.Error(), "kernal") {
+		t.Errorf("Expected an unknown setting error, got %v", err)
+	}
+
+	// 方言：其他工具的設定會被略過
+	input = "---\ndialect: myst\ntitle: 並行\n---\n# Title"
+	if _, err := NewParser(strings.NewReader(input)).Parse(); err != nil {
+		t.Errorf("MyST front matter should ignore unknown keys, got %v", err)
+	}
+}
+
+func TestParser_GeneratedIDSkipsExplicit(t *testing.T) {
+	input := `<!-- MARKDOWN_CELL id="cell-1" -->
+First
+<!-- END_MARKDOWN_CELL -->
+<!-- MARKDOWN_CELL -->
+Second
+<!-- END_MARKDOWN_CELL -->
+<!-- MARKDOWN_CELL -->
+Third
+<!-- END_MARKDOWN_CELL -->`
+
+	parser := NewParser(strings.NewReader(input))
+	notebook, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	var ids []string
+	for _, cell := range notebook.Cells {
+		ids = append(ids, cell.ID)
+	}
+	if strings.Join(ids, " ") != "cell-1 cell-2 cell-3" {
+		t.Errorf("Expected generated ids to skip cell-1, got %v", ids)
+	}
+}
+
+func TestParser_GeneratedIDSkipsLaterExplicit(t *testing.T) {
+	tests := map[string]struct {
+		path, input string
+	}{
+		"markers": {"notes.md", "<!-- MARKDOWN_CELL -->\nFirst\n<!-- END_MARKDOWN_CELL -->\n<!-- MARKDOWN_CELL -->\nSecond\n<!-- END_MARKDOWN_CELL -->\n" +
+			"<!-- MARKDOWN_CELL id=\"cell-1\" -->\nThird\n<!-- END_MARKDOWN_CELL -->"},
+		"percent": {"notes.go", "// %%\nvar a = 1\n\n// %%\nvar b = 2\n\n// %% id=\"cell-1\"\nvar c = 3"},
+	}
+	for name, tt := range tests {
+		parser := NewFileParser(strings.NewReader(tt.input), tt.path, DefaultConfig())
+		notebook, err := parser.Parse()
+		if err != nil {
+			t.Fatalf("%s: Parse failed: %v", name, err)
+		}
+		var ids []string
+		for _, cell := range notebook.Cells {
+			ids = append(ids, cell.ID)
+		}
+		// 後面明確指定的 cell-1 也要避開
+		if strings.Join(ids, " ") != "cell-0 cell-2 cell-1" {
+			t.Errorf("%s: expected generated ids to skip cell-1, got %v", name, ids)
+		}
+	}
+}
+
+func TestParser_StrictMode(t *testing.T) {
+	tests := map[string]string{
+		"content outside markers": "stray text\n<!-- MARKDOWN_CELL -->\n# Title\n<!-- END_MARKDOWN_CELL -->",
+		"missing end marker":      "<!-- MARKDOWN_CELL -->\n# Title\n<!-- MARKDOWN_CELL -->\n## Next\n<!-- END_MARKDOWN_CELL -->",
+		"mismatched end marker":   "<!-- MARKDOWN_CELL -->\n# Title\n<!-- END_CODE_CELL -->",
+		"unclosed code fence":     "<!-- CODE_CELL -->\n```go\nvar x = 1\n<!-- END_CODE_CELL -->",
+		"unclosed at end of file": "<!-- MARKDOWN_CELL -->\n# Title",
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			parser := NewParser(strings.NewReader(input))
+			parser.Config.Strict = true
+			if _, err := parser.Parse(); err == nil {
+				t.Error("Expected strict mode error, got nil")
+			}
+		})
+	}
+}
+
+func TestParser_HashIDStrategy(t *testing.T) {
+	input := `<!-- MARKDOWN_CELL -->
+Same
+<!-- END_MARKDOWN_CELL -->
+<!-- MARKDOWN_CELL -->
+Same
+<!-- END_MARKDOWN_CELL -->`
+
+	parser := NewParser(strings.NewReader(input))
+	parser.Config.IDStrategy = "hash"
+	notebook, err := parser.Parse()
+
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	first, second := notebook.Cells[0].ID, notebook.Cells[1].ID
+	if len(first) != 8 {
+		t.Errorf("Expected 8 character hash ID, got %s", first)
+	}
+	if second != first+"-2" {
+		t.Errorf("Duplicate content should get a suffix, got %s and %s", first, second)
+	}
+}
+
+func TestParser_AttributesToMetadata(t *testing.T) {
+	input := `<!-- CODE_CELL tags="demo,pitfall" slideshow.slide_type="fragment" collapsed="true" -->
+var x = 1
+<!-- END_CODE_CELL -->`
+
+	parser := NewParser(strings.NewReader(input))
+	parser.Config.CellDefaults = map[string]map[string]string{"code": {"editable": "false"}}
+	notebook, err := parser.Parse()
+
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	metadata := notebook.Cells[0].Metadata
+	if tags, ok := metadata["tags"].([]any); !ok || len(tags) != 2 || tags[1] != "pitfall" {
+		t.Errorf("Expected tags list, got %v", metadata["tags"])
+	}
+	if slideshow, ok := metadata["slideshow"].(map[string]any); !ok || slideshow["slide_type"] != "fragment" {
+		t.Errorf("Expected nested slideshow metadata, got %v", metadata["slideshow"])
+	}
+	if metadata["collapsed"] != true || metadata["editable"] != false {
+		t.Errorf("Expected boolean metadata, got %v", metadata)
+	}
+}
+
+func TestParser_FrontMatter(t *testing.T) {
+	input := `---
+kernel: gonb
+---
+<!-- MARKDOWN_CELL -->
+# Title
+<!-- END_MARKDOWN_CELL -->`
+
+	parser := NewParser(strings.NewReader(input))
+	parser.Config.Strict = true
+	notebook, err := parser.Parse()
+
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if notebook.Metadata.Kernelspec.Name != "gonb" {
+		t.Errorf("Expected gonb kernel from front matter, got %s", notebook.Metadata.Kernelspec.Name)
+	}
+	if len(notebook.Cells) != 1 {
+		t.Errorf("Front matter should not become a cell, got %d cells", len(notebook.Cells))
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// InputFormat 輸入檔的格式
+type InputFormat int
+
+const (
+	// FormatMarkers 以 <!-- MARKDOWN_CELL --> / <!-- CODE_CELL --> 標記的 Markdown
+	FormatMarkers InputFormat = iota
+	// FormatPercent Jupytext percent 格式的 Go 原始碼（// %% 分隔 cell）
+	FormatPercent
+)
+
+// FormatForPath 依副檔名判斷輸入格式
+func FormatForPath(path string) InputFormat {
+	if strings.HasSuffix(path, ".go") {
+		return FormatPercent
+	}
+	return FormatMarkers
+}
+
+// percentMarkerRegex 符合 `// %%`、`// %% [markdown]`、`// %% 標題 id="setup"`
+var percentMarkerRegex = regexp.MustCompile(`^//\s*%%(?:\s+(.*))?$`)
+
+// parsePercent 解析 Jupytext percent 格式
+//
+//	// %% [markdown]
+//	// # 標題
+//	// 說明文字
+//
+//	// %%
+//	fmt.Println("hello")
+//
+// 第一個 // %% 之前的內容視為一個 code cell；檔案開頭的 Jupytext header（// --- ... // ---）會被略過。
+func (p *Parser) parsePercent() (*Notebook, error) {
+	notebook := NewNotebook()
+
+	currentType := CodeCell
+	var currentAttrs map[string]string
+	var lines []string
+	inHeader := false
+
+	flush := func(end int) error {
+		// cell 之間習慣以空行分隔，這些空行不屬於 cell 內容
+		for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+			lines = lines[:len(lines)-1]
+		}
+		err := p.saveCell(notebook, currentType, strings.Join(lines, "\n"), currentAttrs, CellSpan{p.cellStart, end, false})
+		lines = nil
+		return err
+	}
+
+	for ; p.scanner.Scan(); p.lineNo++ {
+		line := p.scanner.Text()
+
+		if p.lineNo == 0 && strings.TrimSpace(line) == "// ---" {
+			inHeader = true
+			continue
+		}
+		if inHeader {
+			if strings.TrimSpace(line) == "// ---" {
+				inHeader = false
+			}
+			continue
+		}
+
+		if match := percentMarkerRegex.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			if err := flush(p.lineNo - 1); err != nil {
+				return nil, err
+			}
+			currentType, currentAttrs = parsePercentOptions(match[1])
+			p.cellStart = p.lineNo
+			continue
+		}
+
+		if currentType == MarkdownCell || currentType == RawCell {
+			line = uncommentLine(line)
+		}
+		lines = append(lines, line)
+	}
+
+	if err := flush(p.lineNo - 1); err != nil {
+		return nil, err
+	}
+	if err := p.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanner error: %w", err)
+	}
+	if err := notebook.SetKernel(p.Config.Kernel); err != nil {
+		return nil, err
+	}
+
+	return notebook, nil
+}
+
+// parsePercentOptions 解析 // %% 之後的內容：cell 類型與 key="value" 屬性（標題會被忽略）
+func parsePercentOptions(options string) (CellType, map[string]string) {
+	cellType := CodeCell
+	switch {
+	case strings.Contains(options, "[markdown]") || strings.Contains(options, "[md]"):
+		cellType = MarkdownCell
+	case strings.Contains(options, "[raw]"):
+		cellType = RawCell
+	}
+
+	attrs := map[string]string{}
+	for _, attr := range attributeRegex.FindAllStringSubmatch(options, -1) {
+		attrs[attr[1]] = attr[2]
+	}
+	return cellType, attrs
+}
+
+// uncommentLine 去掉 markdown 與 raw cell 每行開頭的 `// `
+func uncommentLine(line string) string {
+	trimmed := strings.TrimLeft(line, " \t")
+	if !strings.HasPrefix(trimmed, "//") {
+		return line
+	}
+	trimmed = strings.TrimPrefix(trimmed, "//")
+	return strings.TrimPrefix(trimmed, " ")
+}
+
+// WritePercent 將 notebook 輸出成 Jupytext percent 格式的 Go 原始碼
+func WritePercent(w io.Writer, nb *Notebook) error {
+	var b strings.Builder
+	for i, cell := range nb.Cells {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		header := "// %%"
+		switch cell.CellType {
+		case "code":
+		case "raw":
+			header += " [raw]"
+		default:
+			header += " [markdown]"
+		}
+		if cell.ID != "" && !generatedIDRegex.MatchString(cell.ID) {
+			// 與標記相同的 key="value"：值原樣寫入，不能含有引號或換行
+			if strings.ContainsAny(cell.ID, "\"\r\n") {
+				return fmt.Errorf("cell %d: id %q cannot be written as a percent attribute", i, cell.ID)
+			}
+			header += ` id="` + cell.ID + `"`
+		}
+		b.WriteString(header + "\n")
+
+		for _, line := range splitText(cell.Text()) {
+			if cell.CellType != "code" {
+				line = strings.TrimRight("// "+line, " ")
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParser_PercentFormat(t *testing.T) {
+	input := `package main
+
+import "fmt"
+
+// %% [markdown] id="intro"
+// # 標題
+//
+// 說明文字
+
+// %%
+func main() {
+	fmt.Println("hello") // 輸出: hello
+}
+`
+
+	parser := NewParser(strings.NewReader(input))
+	parser.Format = FormatPercent
+	notebook, err := parser.Parse()
+
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(notebook.Cells) != 3 {
+		t.Fatalf("Expected 3 cells, got %d", len(notebook.Cells))
+	}
+
+	expectedTypes := []string{"code", "markdown", "code"}
+	for i, cell := range notebook.Cells {
+		if cell.CellType != expectedTypes[i] {
+			t.Errorf("Cell %d: expected type %s, got %s", i, expectedTypes[i], cell.CellType)
+		}
+	}
+
+	if notebook.Cells[1].ID != "intro" {
+		t.Errorf("Expected explicit ID intro, got %s", notebook.Cells[1].ID)
+	}
+	if got := notebook.Cells[1].Text(); got != "# 標題\n\n說明文字" {
+		t.Errorf("Markdown should be uncommented, got %q", got)
+	}
+	if got := notebook.Cells[2].Text(); !strings.HasSuffix(got, "}") {
+		t.Errorf("Trailing blank lines should be trimmed, got %q", got)
+	}
+}
+
+func TestParser_PercentSkipsHeader(t *testing.T) {
+	input := `// ---
+// jupyter:
+//   kernelspec:
+//     name: gonb
+// ---
+
+// %%
+var x = 1
+`
+
+	parser := NewParser(strings.NewReader(input))
+	parser.Format = FormatPercent
+	notebook, err := parser.Parse()
+
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(notebook.Cells) != 1 || notebook.Cells[0].Text() != "var x = 1" {
+		t.Errorf("Expected only the code cell, got %+v", notebook.Cells)
+	}
+}
+
+func TestWritePercent_RoundTrip(t *testing.T) {
+	nb := NewNotebook()
+	nb.AddMarkdownCell("cell-0", "# 標題\n\n說明")
+	nb.AddCodeCell("setup", "package main\n\nimport \"fmt\"")
+	nb.AddCodeCell("cell-2", "func main() {\n\tfmt.Println(1)\n}")
+
+	var buf bytes.Buffer
+	if err := WritePercent(&buf, nb); err != nil {
+		t.Fatalf("WritePercent failed: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "// %% [markdown]\n// # 標題\n//\n// 說明\n") {
+		t.Errorf("Unexpected markdown output:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), `// %% id="setup"`) {
+		t.Errorf("Explicit IDs should be kept:\n%s", buf.String())
+	}
+
+	parser := NewParser(&buf)
+	parser.Format = FormatPercent
+	parsed, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if diffs := DiffNotebooks(nb, parsed); len(diffs) != 0 {
+		t.Errorf("Round trip changed cells: %v", diffs)
+	}
+}
+
+func TestWritePercent_RoundTripIDsAndRaw(t *testing.T) {
+	nb := NewNotebook()
+	nb.AddRawCell("front-matter", "---\ntitle: 指標\n---")
+	nb.AddCodeCell(`C:\go\設定`, "x := 1")
+	nb.AddMarkdownCell("說明.1", "# 指標")
+
+	var buf bytes.Buffer
+	if err := WritePercent(&buf, nb); err != nil {
+		t.Fatalf("WritePercent failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "// %% [raw] id=\"front-matter\"\n// ---\n") {
+		t.Errorf("Raw cells should be written as [raw]:\n%s", buf.String())
+	}
+
+	parser := NewParser(&buf)
+	parser.Format = FormatPercent
+	parsed, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(parsed.Cells) != len(nb.Cells) {
+		t.Fatalf("Expected %d cells, got %d", len(nb.Cells), len(parsed.Cells))
+	}
+	for i, cell := range parsed.Cells {
+		want := nb.Cells[i]
+		if cell.CellType != want.CellType || cell.ID != want.ID || cell.Text() != want.Text() {
+			t.Errorf("Cell %d: got %s %q %q, want %s %q %q", i, cell.CellType, cell.ID, cell.Text(), want.CellType, want.ID, want.Text())
+		}
+	}
+
+	// 引號無法寫成 key="value"，寫出去會讀不回來，直接報錯
+	nb.AddCodeCell(`say "hi"`, "y := 2")
+	if err := WritePercent(&bytes.Buffer{}, nb); err == nil {
+		t.Error("An id containing quotes should be rejected")
+	}
+}
+
+func TestIntegration_ConvertPercentFile(t *testing.T) {
+	testDir := t.TempDir()
+	inputPath := filepath.Join(testDir, "pointer.go")
+	outputPath := filepath.Join(testDir, "pointer.ipynb")
+
+	input := "// %% [markdown]\n// # 指標\n\n// %%\nx := 1\np := &x\n"
+	if err := os.WriteFile(inputPath, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := convert(inputPath, outputPath); err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	nb, err := ReadNotebook(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nb.Cells) != 2 || nb.Cells[0].CellType != "markdown" {
+		t.Errorf("Unexpected cells: %+v", nb.Cells)
+	}
+}
+
+func TestExport_OutputFlag(t *testing.T) {
+	testDir := t.TempDir()
+	input := filepath.Join(testDir, "pointer.go")
+	writeTestFile(t, input, "// %% [markdown]\n// # 指標\n\n// %%\nx := 1\n")
+
+	output := filepath.Join(testDir, "out", "pointer.go")
+	if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := run([]string{"export", "percent", "-o", output, input}); err != nil {
+		t.Fatalf("export percent failed: %v", err)
+	}
+	if !strings.Contains(readFile(t, output), "// # 指標") {
+		t.Errorf("Unexpected output: %s", readFile(t, output))
+	}
+
+	// 所有格式的位置參數都是輸入，把輸出寫成位置參數要報錯而不是當成輸入
+	missing := filepath.Join(testDir, "site")
+	for _, format := range []string{"percent", "slides", "md", "html", "epub", "gotest"} {
+		err := run([]string{"export", format, input, missing})
+		if err == nil || !strings.Contains(err.Error(), "-o") {
+			t.Errorf("export %s with a positional output: got %v, want an error mentioning -o", format, err)
+		}
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("Nothing should be written to %s", missing)
+	}
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// defaultPrerequisites 語法與套件在這本書（目錄.md）中第一次介紹的章
+//
+// 語法以名稱表示，套件以 import 路徑表示，套件中的函式以「路徑.名稱」表示（例如 errors.As）。
+// 可在 .md2ipynb.json 的 prerequisites 中覆蓋，設為 0 表示不檢查。
+var defaultPrerequisites = map[string]int{
+	// 第三章 複合型態
+	"slice":  3,
+	"map":    3,
+	"struct": 3,
+	// 第四章 區塊、遮蔽與控制結構
+	"for-range": 4,
+	"switch":    4,
+	"goto":      4,
+	// 第五章 函式
+	"closure": 5,
+	"defer":   5,
+	// 第六章 指標
+	"pointer": 6,
+	// 第七章 型態、方法與介面
+	"method":         7,
+	"interface":      7,
+	"embedding":      7,
+	"iota":           7,
+	"type-assertion": 7,
+	"type-switch":    7,
+	// 第八章 錯誤與泛型；錯誤只檢查套件中的函式，errors.New 在前面的章節就會用到
+	"panic":     8,
+	"errors.Is": 8,
+	"generics":  8,
+	// 第九章
+	"errors.As": 9,
+	// 第十章 Go 的並行
+	"goroutine":   10,
+	"channel":     10,
+	"select":      10,
+	"sync":        10,
+	"sync/atomic": 10,
+	// 第十一章 標準程式庫
+	"io":            11,
+	"encoding/json": 11,
+	"net/http":      11,
+	// 第十二章 context
+	"context": 12,
+	// 第十三章 編寫測試
+	"testing": 13,
+	// 第十四章 Reflect、Unsafe 與 Cgo
+	"reflect": 14,
+	"unsafe":  14,
+	"C":       14,
+}
+
+// prerequisiteTable 合併內建對照表與設定檔
+func prerequisiteTable(overrides map[string]int) map[string]int {
+	table := make(map[string]int, len(defaultPrerequisites)+len(overrides))
+	for name, chapter := range defaultPrerequisites {
+		table[name] = chapter
+	}
+	for name, chapter := range overrides {
+		table[name] = chapter
+	}
+	return table
+}
+
+// prereqUse code cell 中用到的一個語法或套件
+type prereqUse struct {
+	Name string
+	Line int // cell 中的行（從 0 開始）
+}
+
+// prereqImporter 型態檢查 code cell 時載入標準程式庫；會快取已載入的套件
+var prereqImporter = importer.ForCompiler(token.NewFileSet(), "source", nil)
+
+// goConstructs 以 go/ast 找出 code cell 用到的語法，並以 go/types 解析名稱：
+// 套件函式、panic、iota 與泛型的實例化都依型態資訊判斷，不會被同名的變數誤判；同一行的同一項只回報一次
+func goConstructs(source string) []prereqUse {
+	fset := token.NewFileSet()
+	file, offset, err := parseCodeCell(fset, source)
+	if err != nil {
+		return nil
+	}
+	// cell 常常依賴其他 cell 的宣告而無法完整通過型態檢查，錯誤略過，只使用解析得到的部分
+	info := &types.Info{Uses: map[*ast.Ident]types.Object{}, Instances: map[*ast.Ident]types.Instance{}}
+	conf := types.Config{Importer: prereqImporter, Error: func(error) {}}
+	_, _ = conf.Check("main", fset, []*ast.File{file}, info)
+
+	var uses []prereqUse
+	seen := map[prereqUse]bool{}
+	add := func(name string, pos token.Pos) {
+		use := prereqUse{Name: name, Line: fset.Position(pos).Line - 1 - offset}
+		if use.Line >= 0 && !seen[use] {
+			seen[use] = true
+			uses = append(uses, use)
+		}
+	}
+
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		add(path, spec.Pos())
+	}
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.GoStmt:
+			add("goroutine", n.Pos())
+		case *ast.ChanType, *ast.SendStmt:
+			add("channel", n.Pos())
+		case *ast.UnaryExpr:
+			switch n.Op {
+			case token.ARROW:
+				add("channel", n.Pos())
+			case token.AND:
+				add("pointer", n.Pos())
+			}
+		case *ast.StarExpr:
+			add("pointer", n.Pos())
+		case *ast.SelectStmt:
+			add("select", n.Pos())
+		case *ast.DeferStmt:
+			add("defer", n.Pos())
+		case *ast.FuncLit:
+			add("closure", n.Pos())
+		case *ast.FuncDecl:
+			if n.Recv != nil {
+				add("method", n.Pos())
+			}
+			if n.Type.TypeParams != nil {
+				add("generics", n.Pos())
+			}
+		case *ast.TypeSpec:
+			if n.TypeParams != nil {
+				add("generics", n.Pos())
+			}
+		case *ast.InterfaceType:
+			if len(n.Methods.List) > 0 {
+				add("interface", n.Pos())
+			}
+		case *ast.StructType:
+			add("struct", n.Pos())
+			for _, field := range n.Fields.List {
+				if len(field.Names) == 0 {
+					add("embedding", field.Pos())
+				}
+			}
+		case *ast.MapType:
+			add("map", n.Pos())
+		case *ast.ArrayType:
+			if n.Len == nil {
+				add("slice", n.Pos())
+			}
+		case *ast.RangeStmt:
+			add("for-range", n.Pos())
+		case *ast.SwitchStmt:
+			add("switch", n.Pos())
+		case *ast.TypeSwitchStmt:
+			add("type-switch", n.Pos())
+			return true
+		case *ast.TypeAssertExpr:
+			if n.Type != nil { // x.(type) 已算在 type-switch
+				add("type-assertion", n.Pos())
+			}
+		case *ast.BranchStmt:
+			if n.Tok == token.GOTO {
+				add("goto", n.Pos())
+			}
+		case *ast.Ident:
+			if _, ok := info.Instances[n]; ok {
+				add("generics", n.Pos())
+			}
+			if obj, ok := info.Uses[n].(*types.Const); ok && obj.Parent() == types.Universe && obj.Name() == "iota" {
+				add("iota", n.Pos())
+			}
+		case *ast.CallExpr:
+			if ident, ok := n.Fun.(*ast.Ident); ok {
+				if builtin, ok := info.Uses[ident].(*types.Builtin); ok && (builtin.Name() == "panic" || builtin.Name() == "recover") {
+					add("panic", n.Pos())
+				}
+			}
+		case *ast.SelectorExpr:
+			if x, ok := n.X.(*ast.Ident); ok {
+				if pkg, ok := info.Uses[x].(*types.PkgName); ok {
+					add(pkg.Imported().Path()+"."+n.Sel.Name, n.Pos())
+				}
+			}
+		}
+		return true
+	})
+	return uses
+}
+
+// CheckPrerequisites 回報 notebook 中在 chapter 之後才介紹的語法與套件
+func CheckPrerequisites(nb *Notebook, chapter int, table map[string]int) []LintIssue {
+	var issues []LintIssue
+	for i, cell := range nb.Cells {
+		if cell.CellType != "code" {
+			continue
+		}
+		for _, use := range goConstructs(cell.Text()) {
+			introduced := table[use.Name]
+			if introduced <= chapter {
+				continue
+			}
+			issues = append(issues, LintIssue{
+				Rule: use.Name, Cell: i, Line: use.Line,
+				Message: fmt.Sprintf("%s 在 ch%d 才介紹，不應出現在 ch%d", use.Name, introduced, chapter),
+			})
+		}
+	}
+	return issues
+}
+
+// runPrereq prereq 子命令：找出各章使用了後面章節才介紹的語法或套件
+func runPrereq(args []string) error {
+	fs := flag.NewFlagSet("prereq", flag.ContinueOnError)
+	chapterFlag := fs.Int("chapter", 0, "以指定的章檢查（預設由路徑中的 chN 判斷）")
+	list := fs.Bool("list", false, "列出目前的對照表")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s prereq [--chapter N] [--list] file-or-dir...\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "對照表可在 .md2ipynb.json 的 prerequisites 中覆蓋\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *list {
+		start := fs.Arg(0)
+		if start == "" {
+			start = "."
+		}
+		cfg, err := LoadConfig(start)
+		if err != nil {
+			return err
+		}
+		table := prerequisiteTable(cfg.Prerequisites)
+		var names []string
+		for name, chapter := range table {
+			if chapter > 0 {
+				names = append(names, name)
+			}
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if table[names[i]] != table[names[j]] {
+				return table[names[i]] < table[names[j]]
+			}
+			return names[i] < names[j]
+		})
+		for _, name := range names {
+			fmt.Printf("ch%-3d %s\n", table[name], name)
+		}
+		return nil
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	paths, err := collectLintFiles(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	total := 0
+	checked := 0
+	for _, path := range paths {
+		chapter := *chapterFlag
+		if chapter == 0 {
+			chapter = chapterOfPath(path)
+		}
+		if chapter == 0 {
+			fmt.Printf("ℹ️  無法判斷章節，略過: %s（使用 --chapter）\n", path)
+			continue
+		}
+		cfg, err := LoadConfig(path)
+		if err != nil {
+			return err
+		}
+		target, err := loadLintTarget(path)
+		if err != nil {
+			return err
+		}
+
+		issues := CheckPrerequisites(target.Notebook, chapter, prerequisiteTable(cfg.Prerequisites))
+		for _, issue := range issues {
+			fmt.Printf("%s: %s\n", target.position(issue), issue.Message)
+		}
+		total += len(issues)
+		checked++
+	}
+
+	if total > 0 {
+		return fmt.Errorf("%d uses of concepts from later chapters", total)
+	}
+	fmt.Printf("✅ 沒有使用後面章節的概念（%d 個檔案）\n", checked)
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGoConstructs(t *testing.T) {
+	source := "package main\n\nimport (\n\t\"errors\"\n\tj \"encoding/json\"\n)\n\n" +
+		"type Box[T any] struct{ v T }\n\n" +
+		"func (b *Box[T]) Get() T { return b.v }\n\n" +
+		"func main() {\n" +
+		"\tch := make(chan int)\n" +
+		"\tgo func() { ch <- 1 }()\n" +
+		"\tvar target *MyErr\n" +
+		"\t_ = errors.As(nil, &target)\n" +
+		"\t_, _ = j.Marshal(<-ch)\n" +
+		"}"
+
+	var got []string
+	for _, use := range goConstructs(source) {
+		got = append(got, fmt.Sprintf("%s@%d", use.Name, use.Line))
+	}
+	want := []string{
+		"errors@3", "encoding/json@4",
+		"generics@7", "struct@7",
+		"method@9", "pointer@9", "generics@9",
+		"channel@12",
+		"goroutine@13", "closure@13", "channel@13",
+		"pointer@14",
+		"errors.As@15", "pointer@15",
+		"encoding/json.Marshal@16", "channel@16",
+	}
+	assertStrings(t, "constructs", got, want)
+
+	// 片段也能分析，行號對應 cell 本身
+	got = nil
+	for _, use := range goConstructs("x := 1\ndefer fmt.Println(x)") {
+		got = append(got, fmt.Sprintf("%s@%d", use.Name, use.Line))
+	}
+	assertStrings(t, "snippet", got, []string{"defer@1"})
+
+	// 名稱以 go/types 解析：同名的區域變數不算，呼叫泛型函式算泛型
+	got = nil
+	for _, use := range goConstructs("errors := shadow{}\nerrors.As()\npanic := func() {}\npanic()\nfmt.Println(slices.Max([]int{1}))") {
+		got = append(got, fmt.Sprintf("%s@%d", use.Name, use.Line))
+	}
+	assertStrings(t, "resolved", got, []string{"closure@2", "slice@4"})
+	got = nil
+	for _, use := range goConstructs("package main\n\nimport \"slices\"\n\nvar m = slices.Max([]int{1})") {
+		got = append(got, fmt.Sprintf("%s@%d", use.Name, use.Line))
+	}
+	assertStrings(t, "instance", got, []string{"slices@2", "slices.Max@4", "generics@4", "slice@4"})
+}
+
+func TestCheckPrerequisites(t *testing.T) {
+	nb := &Notebook{Cells: []Cell{
+		{CellType: "markdown", Source: splitLines("go func() {}()")},
+		{CellType: "code", Source: splitLines("go work()\nvar p *int\n_ = p")},
+	}}
+
+	issues := CheckPrerequisites(nb, 6, prerequisiteTable(nil))
+	if len(issues) != 1 || issues[0].Rule != "goroutine" || issues[0].Cell != 1 || issues[0].Line != 0 {
+		t.Fatalf("Expected only the goroutine in the code cell, got %+v", issues)
+	}
+	if !strings.Contains(issues[0].Message, "ch10") {
+		t.Errorf("Message should name the introducing chapter: %q", issues[0].Message)
+	}
+
+	// errors.New 在前面的章節就會用到，只有 errors.Is/errors.As 有章節
+	errs := &Notebook{Cells: []Cell{{CellType: "code", Source: splitLines("package main\n\nimport \"errors\"\n\nvar a = errors.New(\"a\")\nvar ok = errors.As(a, new(error))")}}}
+	if issues := CheckPrerequisites(errs, 5, prerequisiteTable(nil)); len(issues) != 1 || issues[0].Rule != "errors.As" || !strings.Contains(issues[0].Message, "ch9") {
+		t.Errorf("Expected only errors.As (ch9) to be reported, got %+v", issues)
+	}
+
+	if issues := CheckPrerequisites(nb, 6, prerequisiteTable(map[string]int{"goroutine": 0})); len(issues) != 0 {
+		t.Errorf("0 should disable the check, got %+v", issues)
+	}
+	if issues := CheckPrerequisites(nb, 5, prerequisiteTable(map[string]int{"goroutine": 5})); len(issues) != 1 || issues[0].Rule != "pointer" {
+		t.Errorf("Expected the overridden table to allow goroutines in ch5, got %+v", issues)
+	}
+}
+
+func TestRunPrereq(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "ch4", "ch4_blocks_source.md")
+	writeTestFile(t, source, "<!-- MARKDOWN_CELL -->\n# 第四章\n<!-- END_MARKDOWN_CELL -->\n\n"+
+		"<!-- CODE_CELL -->\n```go\nfor i := range 3 {\n\tdefer fmt.Println(i)\n}\n```\n<!-- END_CODE_CELL -->\n")
+
+	if err := run([]string{"prereq", source}); err == nil || !strings.Contains(err.Error(), "1 uses") {
+		t.Errorf("Expected the defer to be reported, got %v", err)
+	}
+	if err := run([]string{"prereq", "--chapter", "5", source}); err != nil {
+		t.Errorf("--chapter 5 should allow defer: %v", err)
+	}
+
+	writeConfig(t, dir, `{"prerequisites": {"defer": 0}}`)
+	if err := run([]string{"prereq", source}); err != nil {
+		t.Errorf("Config should disable the defer check: %v", err)
+	}
+	writeConfig(t, dir, `{"prerequisites": {"defer": -1}}`)
+	if err := run([]string{"prereq", source}); err == nil {
+		t.Error("Expected an error for a negative chapter")
+	}
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// reviewIDRegex 每次改卷的總結 cell，例如 review-2
+var reviewIDRegex = regexp.MustCompile(`^review-(\d+)$`)
+
+// reviewStatusLabels 批改狀態在改卷講解中的說明；沒有批改結果的題目需要人工批改
+var reviewStatusLabels = []struct {
+	Status, Icon, Label string
+}{
+	{gradeFailed, "❌", "測試失敗"},
+	{runCompileError, "🛠️", "編譯錯誤"},
+	{runTimeout, "⏱️", "逾時"},
+	{gradeUnanswered, "⬜", "未作答"},
+	{"", "📝", "需要人工批改"},
+	{runPassed, "✅", "通過"},
+}
+
+// reviewItem 改卷講解中的一題
+type reviewItem struct {
+	Meta   QuestionMeta
+	Prompt string // 題目內容（不含 ### 標題）
+	Answer string // 學習者的答案
+	Result *GradeResult
+	// Solutions 解答 notebook 中這題的解答 cells
+	Solutions []Cell
+	// Changed 批改後答案又改過，批改結果不再適用
+	Changed bool
+}
+
+func (item reviewItem) status() string {
+	if item.Result != nil {
+		return item.Result.Status
+	}
+	if contentHash(item.Answer) == item.Meta.Template {
+		return gradeUnanswered
+	}
+	return ""
+}
+
+// reviewItems 依考題 notebook 的順序整理每題的題目、答案、批改結果與解答
+func reviewItems(questions, key *Notebook, report *GradeReport) []reviewItem {
+	prompts := map[string]string{}
+	for _, cell := range questions.Cells {
+		if meta, ok := questionMetaOf(cell); ok && meta.Role == rolePrompt {
+			_, body, _ := strings.Cut(cell.Text(), "\n")
+			prompts[meta.ID] = strings.TrimSpace(body)
+		}
+	}
+	solutions := map[string][]Cell{}
+	if key != nil {
+		for _, cell := range key.Cells {
+			if meta, ok := questionMetaOf(cell); ok && meta.Role == roleAnswer {
+				solutions[meta.ID] = append(solutions[meta.ID], cell)
+			}
+		}
+	}
+	results := map[string]*GradeResult{}
+	if report != nil {
+		for i := range report.Results {
+			results[report.Results[i].ID] = &report.Results[i]
+		}
+	}
+
+	var items []reviewItem
+	for _, cell := range questions.Cells {
+		meta, ok := questionMetaOf(cell)
+		if !ok || cell.CellType != "code" || (meta.Role != roleExercise && meta.Role != roleAnswerSlot) {
+			continue
+		}
+		item := reviewItem{
+			Meta: meta, Prompt: prompts[meta.ID], Answer: cell.Text(),
+			Result: results[meta.ID], Solutions: solutions[meta.ID],
+		}
+		// 舊的批改結果沒有 answer_hash，照舊使用
+		if item.Result != nil && item.Result.AnswerHash != "" && item.Result.AnswerHash != contentHash(item.Answer) {
+			item.Result, item.Changed = nil, true
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// reviewTitle 改卷講解的標題，例如「第五章 函式 - 面試考題」-> 「第五章 函式 - 面試答案批改與講解」
+func reviewTitle(questions *Notebook) string {
+	for _, cell := range questions.Cells {
+		if match := h1Regex.FindStringSubmatch(cell.Text()); cell.CellType == "markdown" && match != nil {
+			title := strings.TrimSpace(strings.TrimSuffix(match[1], "面試考題"))
+			return strings.TrimSpace(strings.TrimSuffix(title, "-")) + " - 面試答案批改與講解"
+		}
+	}
+	return "面試答案批改與講解"
+}
+
+// reviewRound 下一次改卷的編號：既有 review-N 總結 cell 的最大編號加一
+func reviewRound(existing *Notebook) int {
+	round := 0
+	if existing == nil {
+		return 1
+	}
+	for _, cell := range existing.Cells {
+		if match := reviewIDRegex.FindStringSubmatch(cell.ID); match != nil {
+			if n, _ := strconv.Atoi(match[1]); n > round {
+				round = n
+			}
+		}
+	}
+	return round + 1
+}
+
+// BuildReview 依 product.md 的改卷格式產生一次改卷的 cells：
+// 先是總結（批改結果與留給講解者填寫的評語），再逐題列出題目、答案、問題分析與正確解答。
+// 只講解沒有通過的題目，all 為 true 時也講解通過的題目；reviewed 為講解的題數
+func BuildReview(items []reviewItem, round int, date time.Time, all bool) (cells []Cell, reviewed int) {
+	prefix := fmt.Sprintf("review-%d", round)
+
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "## 第 %d 次改卷（%s）\n\n", round, date.Format("2006-01-02"))
+	passed := 0
+	for _, item := range items {
+		if item.status() == runPassed {
+			passed++
+		}
+	}
+	fmt.Fprintf(&summary, "### 批改結果：通過 %d / %d 題\n", passed, len(items))
+	for _, label := range reviewStatusLabels {
+		var headings []string
+		for _, item := range items {
+			if item.status() == label.Status {
+				headings = append(headings, item.Meta.Heading())
+			}
+		}
+		if len(headings) > 0 {
+			fmt.Fprintf(&summary, "- %s **%s**：%s\n", label.Icon, label.Label, strings.Join(headings, "、"))
+		}
+	}
+	summary.WriteString("\n### 整體表現總結：\n")
+	summary.WriteString("- ✅ **做得好的地方**：TODO\n")
+	summary.WriteString("- ⚠️ **需要改進的地方**：TODO\n")
+	summary.WriteString("- 📝 **建議**：TODO")
+
+	cells = []Cell{{CellType: "markdown", ID: prefix, Metadata: CellMetadata{}, Source: splitLines(summary.String())}}
+	for _, item := range items {
+		if item.status() == runPassed && !all {
+			continue
+		}
+		cells = append(cells, reviewItemCells(item, prefix)...)
+		reviewed++
+	}
+	return cells, reviewed
+}
+
+// reviewItemCells 一題的講解：markdown 的題目、答案與問題分析，接著複製解答 notebook 中的解答
+func reviewItemCells(item reviewItem, prefix string) []Cell {
+	id := prefix + "-" + item.Meta.ID
+	status := item.status()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "---\n\n## %s - 評分：TODO/10\n\n", item.Meta.Heading())
+	if item.Prompt != "" {
+		fmt.Fprintf(&b, "### 題目：\n%s\n\n", item.Prompt)
+	}
+	b.WriteString("### 你的答案：\n")
+	if status == gradeUnanswered {
+		b.WriteString("（未作答）\n\n")
+	} else {
+		fmt.Fprintf(&b, "```go\n%s\n```\n\n", strings.TrimSpace(item.Answer))
+	}
+	for _, label := range reviewStatusLabels {
+		if label.Status != status {
+			continue
+		}
+		fmt.Fprintf(&b, "### 批改結果：%s %s\n", label.Icon, label.Label)
+		if item.Changed {
+			b.WriteString("- 答案在批改後有修改，批改結果不適用\n")
+		}
+		if item.Result != nil && len(item.Result.FailedTests) > 0 {
+			fmt.Fprintf(&b, "- 失敗的測試：%s\n", strings.Join(item.Result.FailedTests, ", "))
+		}
+		if item.Result != nil && item.Result.Message != "" {
+			fmt.Fprintf(&b, "```\n%s\n```\n", item.Result.Message)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("### 問題分析：\n- TODO: 說明哪裡寫錯或寫得不好\n\n### 正確答案：")
+	if len(item.Solutions) == 0 {
+		b.WriteString("\nTODO: 解答 notebook 中沒有這題的解答")
+	}
+
+	cells := []Cell{{CellType: "markdown", ID: id, Metadata: CellMetadata{}, Source: splitLines(b.String())}}
+	for i, solution := range item.Solutions {
+		cell := Cell{CellType: solution.CellType, ID: id + "-solution", Metadata: CellMetadata{}, Source: solution.Source}
+		if i > 0 {
+			cell.ID = fmt.Sprintf("%s-solution-%d", id, i+1)
+		}
+		if cell.CellType == "code" {
+			cell.Outputs = []any{}
+		}
+		cells = append(cells, cell)
+	}
+	return cells
+}
+
+// reviewPath 改卷講解的預設路徑，例如 ch5_interview_questions.ipynb -> ch5_interview_answers_review.ipynb
+func reviewPath(questionsPath string) string {
+	stem := strings.TrimSuffix(questionsPath, ".ipynb")
+	return strings.TrimSuffix(stem, "_questions") + "_answers_review.ipynb"
+}
+
+// runReview review 子命令：依考題、解答與批改結果產生改卷講解
+func runReview(args []string) error {
+	fs := flag.NewFlagSet("review", flag.ContinueOnError)
+	output := fs.String("o", "", "改卷講解 notebook（預設為 chN_interview_answers_review.ipynb）")
+	keyPath := fs.String("key", "", "解答 notebook（預設為 chN_interview_answer_key.ipynb）")
+	gradesFile := fs.String("grades", "", "grade 的批改結果（預設為 <notebook>.grades.json）")
+	all := fs.Bool("all", false, "也列出通過的題目")
+	backup := fs.Bool("backup", false, "覆寫前把既有檔案保存成 .bak")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s review [-o review.ipynb] [--key key.ipynb] [--grades results.json] [--all] questions.ipynb\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "在改卷講解中新增一次改卷：總結加上每題的答案、問題分析與正確解答\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	questionsPath := fs.Arg(0)
+	if *output == "" {
+		*output = reviewPath(questionsPath)
+	}
+	if *keyPath == "" {
+		*keyPath = answerKeyPath(questionsPath)
+	}
+	if *gradesFile == "" {
+		*gradesFile = gradesPath(questionsPath)
+	}
+
+	questions, err := ReadNotebook(questionsPath)
+	if err != nil {
+		return err
+	}
+	key, err := ReadNotebook(*keyPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if key == nil {
+		fmt.Printf("ℹ️  找不到解答 %s，正確答案留待補充\n", *keyPath)
+	}
+	report, err := ReadGradeReport(*gradesFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if report != nil && report.Notebook != "" && filepath.Base(filepath.FromSlash(report.Notebook)) != filepath.Base(questionsPath) {
+		return fmt.Errorf("%s grades %s, not %s", *gradesFile, report.Notebook, questionsPath)
+	}
+	date := time.Now()
+	if report != nil {
+		date = report.GradedAt
+	} else {
+		fmt.Printf("ℹ️  找不到批改結果 %s，所有已作答的題目都列為需要人工批改（可先執行 grade）\n", *gradesFile)
+	}
+
+	items := reviewItems(questions, key, report)
+	if len(items) == 0 {
+		return fmt.Errorf("%s has no questions with metadata; generate it from a source file with EXERCISE/QUESTION markers", questionsPath)
+	}
+
+	cfg, err := LoadConfig(*output)
+	if err != nil {
+		return err
+	}
+	// 有改卷講解的來源檔（scaffold chapter 產生）時寫入來源檔再轉換，否則直接加在 notebook 後面
+	if source, ok := cfg.SourcePathFor(*output); ok {
+		if _, err := os.Stat(source); err == nil {
+			return appendReviewSource(source, *output, items, date, *all, *backup)
+		}
+	}
+
+	existing, err := ReadNotebook(*output)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	nb := existing
+	if nb == nil {
+		nb = NewNotebook()
+		nb.Metadata = questions.Metadata
+		nb.AddMarkdownCell("review", "# "+reviewTitle(questions))
+	}
+	round := reviewRound(existing)
+	cells, reviewed := BuildReview(items, round, date, *all)
+	nb.Cells = append(nb.Cells, cells...)
+
+	data, err := nb.ToJSON()
+	if err != nil {
+		return fmt.Errorf("failed to convert to JSON: %w", err)
+	}
+	if err := writeFileAtomic(*output, data, *backup); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+	fmt.Printf("✅ 第 %d 次改卷：%d 題需要講解 -> %s\n", round, reviewed, *output)
+	return nil
+}
+
+// appendReviewSource 將改卷加在來源檔後面並重新轉換
+func appendReviewSource(source, output string, items []reviewItem, date time.Time, all, backup bool) error {
+	target, err := loadLintTarget(source)
+	if err != nil {
+		return err
+	}
+	round := reviewRound(target.Notebook)
+	cells, reviewed := BuildReview(items, round, date, all)
+
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	buf.Write(bytes.TrimRight(data, "\n"))
+	buf.WriteString("\n\n")
+	if err := WriteMarkdown(&buf, &Notebook{Cells: cells}); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(source, buf.Bytes(), backup); err != nil {
+		return fmt.Errorf("failed to write %s: %w", source, err)
+	}
+	fmt.Printf("📝 第 %d 次改卷：%d 題需要講解 -> %s\n", round, reviewed, source)
+	return convertOne(source, output, convertOptions{Update: true, Backup: backup})
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupReview 產生 ch5 的考題與解答，寫入練習的答案與批改結果
+func setupReview(t *testing.T) (dir, questions string) {
+	t.Helper()
+	dir = t.TempDir()
+	source := filepath.Join(dir, "ch5", "ch5_interview_questions_source.md")
+	questions = filepath.Join(dir, "ch5", "ch5_interview_questions.ipynb")
+	writeTestFile(t, source, interviewSource)
+	if err := convert(source, questions); err != nil {
+		t.Fatal(err)
+	}
+
+	nb, err := ReadNotebook(questions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nb.Cells[2].Source = splitLines("func sum(nums ...int) int { return len(nums) }")
+	nb.Cells[5].Source = splitLines("func counter() func() int { n := 0; return func() int { n++; return n } }")
+	data, _ := nb.ToJSON()
+	writeTestFile(t, questions, string(data))
+
+	report := GradeReport{
+		Notebook: questions,
+		GradedAt: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		Results: []GradeResult{
+			{ID: "ch5-ex1", Kind: kindExercise, Number: 1, Status: gradeFailed, FailedTests: []string{"TestSum"}, Message: "sum(1, 2, 3) = 3, want 6", AnswerHash: contentHash(nb.Cells[2].Text())},
+			{ID: "closure-counter", Kind: kindQuestion, Number: 1, Status: runPassed, AnswerHash: contentHash(nb.Cells[5].Text())},
+		},
+	}
+	data, _ = json.Marshal(report)
+	writeTestFile(t, gradesPath(questions), string(data))
+	return dir, questions
+}
+
+func TestBuildReview(t *testing.T) {
+	_, questions := setupReview(t)
+	nb, _ := ReadNotebook(questions)
+	key, _ := ReadNotebook(answerKeyPath(questions))
+	report, err := ReadGradeReport(gradesPath(questions))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cells, reviewed := BuildReview(reviewItems(nb, key, report), 1, report.GradedAt, false)
+	var ids []string
+	for _, cell := range cells {
+		ids = append(ids, cell.ID)
+	}
+	// 通過的 closure-counter 不講解；沒有測試也沒作答的題目 2 列為未作答
+	assertStrings(t, "ids", ids, []string{"review-1", "review-1-ch5-ex1", "review-1-ch5-ex1-solution", "review-1-ch5-q2"})
+	if reviewed != 2 {
+		t.Errorf("reviewed = %d, want 2", reviewed)
+	}
+
+	summary := cells[0].Text()
+	for _, want := range []string{"## 第 1 次改卷（2026-10-19）", "通過 1 / 3 題", "❌ **測試失敗**：練習 1：可變參數", "⬜ **未作答**：題目 2：defer 的順序", "✅ **通過**：題目 1：閉包", "**做得好的地方**：TODO"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Summary missing %q:\n%s", want, summary)
+		}
+	}
+	exercise := cells[1].Text()
+	for _, want := range []string{"## 練習 1：可變參數 - 評分：TODO/10", "return len(nums)", "失敗的測試：TestSum", "sum(1, 2, 3) = 3, want 6", "### 問題分析：\n- TODO"} {
+		if !strings.Contains(exercise, want) {
+			t.Errorf("Exercise review missing %q:\n%s", want, exercise)
+		}
+	}
+	if cells[2].CellType != "code" || cells[2].Text() != "func sum(nums ...int) int { return 0 }" {
+		t.Errorf("Expected the solution from the answer key, got %q", cells[2].Text())
+	}
+	if question := cells[3].Text(); !strings.Contains(question, "多個 defer 以什麼順序執行？") || !strings.Contains(question, "（未作答）") || !strings.Contains(question, "TODO: 解答 notebook 中沒有這題的解答") {
+		t.Errorf("Unexpected question review:\n%s", question)
+	}
+
+	cells, reviewed = BuildReview(reviewItems(nb, key, nil), 1, report.GradedAt, true)
+	if reviewed != 3 || !strings.Contains(cells[0].Text(), "📝 **需要人工批改**：練習 1：可變參數、題目 1：閉包") {
+		t.Errorf("Without grades every answer needs a manual review, got %d:\n%s", reviewed, cells[0].Text())
+	}
+
+	// 批改後又改過的答案不沿用批改結果
+	nb.Cells[5].Source = splitLines("func counter() func() int { return nil }")
+	cells, reviewed = BuildReview(reviewItems(nb, key, report), 1, report.GradedAt, false)
+	if reviewed != 3 || !strings.Contains(cells[0].Text(), "📝 **需要人工批改**：題目 1：閉包") {
+		t.Errorf("A changed answer needs a manual review, got %d:\n%s", reviewed, cells[0].Text())
+	}
+	noted := false
+	for _, cell := range cells {
+		noted = noted || cell.ID == "review-1-closure-counter" && strings.Contains(cell.Text(), "答案在批改後有修改")
+	}
+	if !noted {
+		t.Error("Expected a note on the changed answer")
+	}
+}
+
+func TestRunReview(t *testing.T) {
+	dir, questions := setupReview(t)
+	output := filepath.Join(dir, "ch5", "ch5_interview_answers_review.ipynb")
+
+	for i := 0; i < 2; i++ {
+		if err := run([]string{"review", questions}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	nb, err := ReadNotebook(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nb.Cells[0].Text() != "# 第五章 函式 - 面試答案批改與講解" {
+		t.Errorf("Unexpected title %q", nb.Cells[0].Text())
+	}
+	if reviewRound(nb) != 3 || len(nb.Cells) != 9 {
+		t.Errorf("Expected two reviews appended, got %d cells", len(nb.Cells))
+	}
+
+	other := filepath.Join(dir, "ch6", "ch6_interview_questions.ipynb")
+	writeTestFile(t, other, readFile(t, questions))
+	if err := run([]string{"review", "--grades", gradesPath(questions), other}); err == nil || !strings.Contains(err.Error(), "not "+other) {
+		t.Errorf("Expected an error for grades of another notebook, got %v", err)
+	}
+}
+
+func TestRunReview_Source(t *testing.T) {
+	dir, questions := setupReview(t)
+	source := filepath.Join(dir, "ch5", "ch5_interview_answers_review_source.md")
+	output := filepath.Join(dir, "ch5", "ch5_interview_answers_review.ipynb")
+	writeTestFile(t, source, "<!-- MARKDOWN_CELL -->\n# 第五章：函式 - 考卷批改與解答\n<!-- END_MARKDOWN_CELL -->\n")
+
+	if err := run([]string{"review", questions}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(readFile(t, source), `<!-- MARKDOWN_CELL id="review-1-ch5-ex1" -->`) {
+		t.Error("Expected the review to be appended to the source")
+	}
+	if err := run([]string{"convert", "--check", source, output}); err != nil {
+		t.Errorf("Notebook should be regenerated from the source: %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hank/learning-go/ch9/converter/outline"
+)
+
+// topicRegex 檔名中的主題：小寫英文、數字與底線
+var topicRegex = regexp.MustCompile(`^[a-z0-9]+(?:_[a-z0-9]+)*$`)
+
+// scaffoldFile 要建立的一個來源檔
+type scaffoldFile struct {
+	Name    string // 來源檔名，例如 ch10_note_source.md
+	Content string
+}
+
+// runScaffold scaffold 子命令；目前只有 scaffold chapter
+func runScaffold(args []string) error {
+	if len(args) == 0 || args[0] != "chapter" {
+		fmt.Fprintf(os.Stderr, "Usage: %s scaffold chapter [--topic name] [--toc 目錄.md] N\n", os.Args[0])
+		return flag.ErrHelp
+	}
+	return runScaffoldChapter(args[1:])
+}
+
+func runScaffoldChapter(args []string) error {
+	fs := flag.NewFlagSet("scaffold chapter", flag.ContinueOnError)
+	topic := fs.String("topic", "note", "筆記檔名中的主題（小寫英文，以底線分隔），例如 concurrency")
+	tocPath := fs.String("toc", "", "全書目錄（預設從目前目錄往上尋找 "+outline.FileName+"）")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s scaffold chapter [--topic name] [--toc 目錄.md] N\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "在目錄.md 所在的目錄下建立 chN/，已存在的檔案不會被覆寫\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	positional := fs.Args()
+	if len(positional) > 1 {
+		// 章號寫在 flag 前面：scaffold chapter 10 --topic concurrency
+		if err := fs.Parse(positional[1:]); err != nil {
+			return err
+		}
+		positional = append([]string{positional[0]}, fs.Args()...)
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	number, err := strconv.Atoi(positional[0])
+	if err != nil {
+		return fmt.Errorf("invalid chapter number %q", positional[0])
+	}
+	if !topicRegex.MatchString(*topic) {
+		return fmt.Errorf("invalid topic %q: use lowercase words separated by _", *topic)
+	}
+
+	if *tocPath == "" {
+		found, err := findFileUpward(".", outline.FileName)
+		if err != nil {
+			return err
+		}
+		if found == "" {
+			return fmt.Errorf("%s not found; use --toc", outline.FileName)
+		}
+		*tocPath = found
+	}
+	book, err := outline.ParseFile(*tocPath)
+	if err != nil {
+		return err
+	}
+	chapter, ok := book.Chapter(number)
+	if !ok {
+		return fmt.Errorf("chapter %d not found in %s", number, *tocPath)
+	}
+
+	dir := filepath.Join(filepath.Dir(*tocPath), fmt.Sprintf("ch%d", number))
+	return scaffoldChapter(dir, chapter, *topic)
+}
+
+// scaffoldChapter 建立章節資料夾、來源檔與轉換後的 notebook；已存在的檔案都會略過
+func scaffoldChapter(dir string, chapter outline.Chapter, topic string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range chapterScaffold(chapter, topic) {
+		source := filepath.Join(dir, file.Name)
+		output, _ := cfg.OutputPathFor(source)
+		if output == "" {
+			output = strings.TrimSuffix(source, "_source.md") + ".ipynb"
+		}
+
+		switch created, err := createFile(source, file.Content); {
+		case err != nil:
+			return err
+		case created:
+			fmt.Printf("✅ 已建立: %s\n", source)
+		default:
+			fmt.Printf("ℹ️  已存在，略過: %s\n", source)
+		}
+
+		if _, err := os.Stat(output); err == nil {
+			fmt.Printf("ℹ️  已存在，略過: %s\n", output)
+			continue
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if err := convert(source, output); err != nil {
+			return fmt.Errorf("failed to convert %s: %w", source, err)
+		}
+		fmt.Printf("✅ 已建立: %s\n", output)
+	}
+	return nil
+}
+
+// createFile 建立新檔案；檔案已存在時不寫入並回傳 false
+func createFile(path, content string) (bool, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return true, file.Close()
+}
+
+// chapterScaffold 依 .kiro/steering/product.md 的規範產生每章的三個來源檔：
+// 章節筆記、面試考題與改卷講解
+func chapterScaffold(chapter outline.Chapter, topic string) []scaffoldFile {
+	prefix := fmt.Sprintf("ch%d_", chapter.Number)
+	return []scaffoldFile{
+		{Name: prefix + topic + "_source.md", Content: noteScaffold(chapter)},
+		{Name: prefix + "interview_questions_source.md", Content: questionsScaffold(chapter)},
+		{Name: prefix + "interview_answers_review_source.md", Content: reviewScaffold(chapter)},
+	}
+}
+
+// noteScaffold 章節筆記：每個節與小節都是一個 Section（一個 markdown cell 加一個 code cell）
+func noteScaffold(chapter outline.Chapter) string {
+	var b strings.Builder
+	writeMarkdownCell(&b, "# "+chapter.Label()+"\n\nTODO: 本章概要")
+
+	for _, section := range chapter.Sections {
+		writeSection(&b, "## "+section.Title, section.Title)
+		for _, subsection := range section.Subsections {
+			writeSection(&b, "### "+subsection.Title, subsection.Title)
+		}
+	}
+	return b.String()
+}
+
+func writeSection(b *strings.Builder, heading, title string) {
+	writeMarkdownCell(b, heading+"\n\nTODO: 從初階到進階說明"+title+"，並標示容易出錯的地方")
+	writeCodeCell(b, sectionCodeScaffold(title))
+}
+
+// sectionCodeScaffold Section 的範例程式骨架；lint --fix 補上缺少的 code cell 時也使用
+func sectionCodeScaffold(title string) string {
+	return fmt.Sprintf("package main\n\nimport \"fmt\"\n\n/* %s */\nfunc main() {\n    // TODO: 範例程式，每一行輸出都以註解寫出結果\n    fmt.Println(\"TODO\") // 輸出: TODO\n}", title)
+}
+
+// questionsScaffold 面試考題：練習（題目寫在 code cell 註解中）與題目（markdown 題目加上作答的 code cell）
+func questionsScaffold(chapter outline.Chapter) string {
+	var b strings.Builder
+	writeMarkdownCell(&b, fmt.Sprintf("# 第%s章：%s - 面試考題\n\n## 說明\nTODO: 考題涵蓋的概念。請在每個程式碼區塊中寫下你的答案。", chapter.Numeral, chapter.Title))
+
+	writeMarkdownCell(&b, "## 練習")
+	for _, section := range chapter.Sections {
+		writeCodeCell(&b, fmt.Sprintf("// 練習：%s\n// TODO: 題目", section.Title))
+	}
+
+	writeMarkdownCell(&b, "## 題目")
+	for i, section := range chapter.Sections {
+		writeMarkdownCell(&b, fmt.Sprintf("### 題目 %d：%s\n\n**問題：** TODO", i+1, section.Title))
+		writeCodeCell(&b, "// 請在這裡寫下你的答案")
+	}
+	return b.String()
+}
+
+// reviewScaffold 改卷講解：先寫總結，再逐題講解
+func reviewScaffold(chapter outline.Chapter) string {
+	var b strings.Builder
+	writeMarkdownCell(&b, fmt.Sprintf("# 第%s章：%s - 考卷批改與解答\n\n## 總體評估\n\nTODO: 哪裡寫錯或寫得不好", chapter.Numeral, chapter.Title))
+	return b.String()
+}
+
+func writeMarkdownCell(b *strings.Builder, content string) {
+	b.WriteString("<!-- MARKDOWN_CELL -->\n" + content + "\n<!-- END_MARKDOWN_CELL -->\n\n")
+}
+
+func writeCodeCell(b *strings.Builder, code string) {
+	b.WriteString("<!-- CODE_CELL -->\n```go\n" + code + "\n```\n<!-- END_CODE_CELL -->\n\n")
+}
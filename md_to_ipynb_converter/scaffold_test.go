@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hank/learning-go/ch9/converter/outline"
+)
+
+func TestScaffoldChapter(t *testing.T) {
+	root := t.TempDir()
+	toc := filepath.Join(root, outline.FileName)
+	writeTestFile(t, toc, "## 第三章 複合型態 ..... 35\n- 陣列 ..... 35\n- slice ..... 37\n  - len ..... 39\n  - append ..... 41\n- map ..... 55\n")
+
+	if err := run([]string{"scaffold", "chapter", "3", "--toc", toc, "--topic", "composite_type"}); err != nil {
+		t.Fatalf("scaffold failed: %v", err)
+	}
+
+	dir := filepath.Join(root, "ch3")
+	for _, name := range []string{
+		"ch3_composite_type_source.md", "ch3_composite_type.ipynb",
+		"ch3_interview_questions_source.md", "ch3_interview_questions.ipynb",
+		"ch3_interview_answers_review_source.md", "ch3_interview_answers_review.ipynb",
+	} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Missing %s: %v", name, err)
+		}
+	}
+
+	nb, err := ReadNotebook(filepath.Join(dir, "ch3_composite_type.ipynb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 標題 + 5 個 Section（3 節、2 小節），每個 Section 是 markdown 加 code
+	wantHeadings := []string{"# 第三章 複合型態", "## 陣列", "## slice", "### len", "### append", "## map"}
+	if len(nb.Cells) != 1+2*5 {
+		t.Fatalf("Expected 11 cells, got %d", len(nb.Cells))
+	}
+	for i, heading := range wantHeadings {
+		cell := nb.Cells[0]
+		if i > 0 {
+			cell = nb.Cells[2*i-1]
+			if code := nb.Cells[2*i]; code.CellType != "code" || !strings.Contains(code.Text(), "func main()") {
+				t.Errorf("Section %q should be followed by a code cell", heading)
+			}
+		}
+		if !strings.HasPrefix(cell.Text(), heading+"\n") {
+			t.Errorf("Cell should start with %q, got %q", heading, cell.Text())
+		}
+	}
+
+	questions, err := ReadNotebook(filepath.Join(dir, "ch3_interview_questions.ipynb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := questions.Cells[0].Text(); !strings.HasPrefix(text, "# 第三章：複合型態 - 面試考題") {
+		t.Errorf("Unexpected questions title %q", text)
+	}
+}
+
+func TestScaffoldChapter_NeverOverwrites(t *testing.T) {
+	root := t.TempDir()
+	toc := filepath.Join(root, outline.FileName)
+	writeTestFile(t, toc, "## 第六章 指標 ..... 113\n- 指標入門 ..... 113\n")
+	source := filepath.Join(root, "ch6", "ch6_note_source.md")
+	writeTestFile(t, source, "<!-- MARKDOWN_CELL -->\n# 我的筆記\n<!-- END_MARKDOWN_CELL -->\n")
+	notebook := filepath.Join(root, "ch6", "ch6_interview_questions.ipynb")
+	writeTestFile(t, notebook, "既有內容")
+
+	if err := run([]string{"scaffold", "chapter", "--toc", toc, "6"}); err != nil {
+		t.Fatalf("scaffold failed: %v", err)
+	}
+
+	if got := readFile(t, source); !strings.Contains(got, "我的筆記") {
+		t.Errorf("Existing source was overwritten: %q", got)
+	}
+	if got := readFile(t, notebook); got != "既有內容" {
+		t.Errorf("Existing notebook was overwritten: %q", got)
+	}
+	// 既有的來源檔還沒有 notebook 時會被轉換
+	nb, err := ReadNotebook(filepath.Join(root, "ch6", "ch6_note.ipynb"))
+	if err != nil || len(nb.Cells) != 1 {
+		t.Errorf("Expected notebook converted from the existing source, got %v", err)
+	}
+}
+
+func TestScaffoldChapter_Errors(t *testing.T) {
+	root := t.TempDir()
+	toc := filepath.Join(root, outline.FileName)
+	writeTestFile(t, toc, "## 第一章 A ..... 1\n")
+
+	tests := map[string][]string{
+		"chapter 9 not found": {"scaffold", "chapter", "--toc", toc, "9"},
+		"invalid topic":       {"scaffold", "chapter", "--toc", toc, "--topic", "Bad-Topic", "1"},
+		"invalid chapter":     {"scaffold", "chapter", "--toc", toc, "一"},
+	}
+	for want, args := range tests {
+		if err := run(args); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("run(%v) error = %v, want %q", args, err, want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// 在瀏覽器中搜尋 search-index.js 產生的索引，不需要伺服器
+(function () {
+  var input = document.getElementById("search");
+  var results = document.getElementById("search-results");
+  var index = window.SEARCH_INDEX || [];
+
+  function snippet(text, query) {
+    var at = text.toLowerCase().indexOf(query);
+    if (at < 0) return "";
+    var start = Math.max(0, at - 20);
+    return (start > 0 ? "…" : "") + text.slice(start, at + query.length + 40) + "…";
+  }
+
+  function item(href, title, detail) {
+    var li = document.createElement("li");
+    var a = document.createElement("a");
+    a.href = href;
+    a.textContent = title;
+    li.appendChild(a);
+    if (detail) {
+      var span = document.createElement("span");
+      span.className = "snippet";
+      span.textContent = detail;
+      li.appendChild(span);
+    }
+    return li;
+  }
+
+  input.addEventListener("input", function () {
+    var query = input.value.trim().toLowerCase();
+    results.innerHTML = "";
+    results.hidden = query === "";
+    if (query === "") return;
+
+    var count = 0;
+    index.forEach(function (page) {
+      if (count >= 30) return;
+      page.headings.forEach(function (heading) {
+        if (count < 30 && heading.text.toLowerCase().indexOf(query) >= 0) {
+          results.appendChild(item(page.url + "#" + encodeURIComponent(heading.id), heading.text, page.title));
+          count++;
+        }
+      });
+      if (count < 30 && (page.title.toLowerCase().indexOf(query) >= 0 || page.text.toLowerCase().indexOf(query) >= 0)) {
+        results.appendChild(item(page.url, page.title, snippet(page.text, query)));
+        count++;
+      }
+    });
+    if (count === 0) {
+      results.appendChild(item("#", "找不到符合的內容", ""));
+    }
+  });
+})();
@@ -0,0 +1,138 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+//go:embed slides/deck.html
+var deckTemplate string
+
+// 投影片類型，與 Jupyter（RISE、nbconvert）的 slideshow.slide_type 相同
+const (
+	slideTypeSlide    = "slide"
+	slideTypeSubslide = "subslide"
+	slideTypeFragment = "fragment"
+	slideTypeSkip     = "skip"
+	slideTypeNotes    = "notes"
+	slideTypeContinue = "-"
+)
+
+// deckSlide 一張投影片（reveal.js 的垂直 section）
+type deckSlide struct {
+	Parts []deckPart
+	Notes []template.HTML
+}
+
+// deckPart 投影片中的一個 cell；Fragment 為 true 時逐步顯示
+type deckPart struct {
+	HTML     template.HTML
+	Fragment bool
+}
+
+// deckData deck.html 的資料；Slides 的每個元素是一組水平投影片，內含一到多張垂直投影片
+type deckData struct {
+	Title  string
+	Slides [][]*deckSlide
+}
+
+// WriteSlides 將 notebook 輸出成 reveal.js 風格、不需網路的單一 HTML 投影片
+//
+// 依 cell metadata 的 slideshow.slide_type 分頁；沒有設定的 markdown cell
+// 以 # 開頭時自動開新投影片、以 ## 開頭時開新的垂直投影片，其他 cell 接在目前的投影片後面。
+func WriteSlides(w io.Writer, nb *Notebook) error {
+	tmpl, err := template.New("deck").Parse(deckTemplate)
+	if err != nil {
+		return err
+	}
+
+	data := deckData{Slides: buildDeck(nb)}
+	for _, cell := range nb.Cells {
+		if level, text := cellHeading(cell); level == 1 {
+			data.Title = plainText(RenderMarkdown(text))
+			break
+		}
+	}
+	if data.Title == "" {
+		data.Title = "Slides"
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render slides: %w", err)
+	}
+	return nil
+}
+
+// buildDeck 依 slide_type 將 cells 分成投影片
+func buildDeck(nb *Notebook) [][]*deckSlide {
+	renderer := &MarkdownRenderer{}
+	var slides [][]*deckSlide
+
+	current := func() *deckSlide {
+		if len(slides) == 0 {
+			slides = append(slides, []*deckSlide{{}})
+		}
+		group := slides[len(slides)-1]
+		return group[len(group)-1]
+	}
+
+	for _, cell := range nb.Cells {
+		slideType := cellSlideType(cell)
+		if slideType == slideTypeSkip {
+			continue
+		}
+		html := template.HTML(renderCellHTML(renderer, cell, htmlOptions{}))
+
+		switch slideType {
+		case slideTypeSlide:
+			slides = append(slides, []*deckSlide{{}})
+		case slideTypeSubslide:
+			if len(slides) == 0 {
+				slides = append(slides, []*deckSlide{{}})
+			} else {
+				slides[len(slides)-1] = append(slides[len(slides)-1], &deckSlide{})
+			}
+		case slideTypeNotes:
+			slide := current()
+			slide.Notes = append(slide.Notes, html)
+			continue
+		}
+
+		slide := current()
+		slide.Parts = append(slide.Parts, deckPart{HTML: html, Fragment: slideType == slideTypeFragment})
+	}
+	return slides
+}
+
+// cellSlideType 回傳 cell 的投影片類型：明確設定的 slideshow.slide_type 優先，
+// 否則 # 標題開新投影片、## 標題開新的垂直投影片
+func cellSlideType(cell Cell) string {
+	if slideshow, ok := cell.Metadata["slideshow"].(map[string]any); ok {
+		if slideType, ok := slideshow["slide_type"].(string); ok && slideType != "" {
+			return slideType
+		}
+	}
+	switch level, _ := cellHeading(cell); level {
+	case 1:
+		return slideTypeSlide
+	case 2:
+		return slideTypeSubslide
+	}
+	return slideTypeContinue
+}
+
+// cellHeading 回傳 markdown cell 開頭的 ATX 標題層級與文字；不是以標題開頭時層級為 0
+func cellHeading(cell Cell) (int, string) {
+	if cell.CellType != "markdown" {
+		return 0, ""
+	}
+	first, _, _ := strings.Cut(strings.TrimLeft(cell.Text(), "\n"), "\n")
+	match := atxHeadingRegex.FindStringSubmatch(first)
+	if match == nil {
+		return 0, ""
+	}
+	return len(match[1]), match[2]
+}
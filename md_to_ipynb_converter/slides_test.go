@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBuildDeck_AutomaticHeadings(t *testing.T) {
+	nb := NewNotebook()
+	nb.AddMarkdownCell("", "# 第十章 Go 的並行")
+	nb.AddMarkdownCell("", "## Goroutine")
+	nb.AddCodeCell("", "go f()")
+	nb.AddMarkdownCell("", "### 細節不換頁")
+	nb.AddMarkdownCell("", "## Channel")
+	nb.AddMarkdownCell("", "# 第十一章")
+
+	slides := buildDeck(nb)
+	if len(slides) != 2 {
+		t.Fatalf("Expected 2 horizontal slides, got %d", len(slides))
+	}
+	if len(slides[0]) != 3 || len(slides[1]) != 1 {
+		t.Errorf("Unexpected vertical slides: %d, %d", len(slides[0]), len(slides[1]))
+	}
+	if got := len(slides[0][1].Parts); got != 3 {
+		t.Errorf("Goroutine slide should contain 3 cells, got %d", got)
+	}
+}
+
+func TestBuildDeck_SlideTypeMetadata(t *testing.T) {
+	input := `<!-- MARKDOWN_CELL -->
+# 標題
+<!-- END_MARKDOWN_CELL -->
+
+<!-- MARKDOWN_CELL slideshow.slide_type="fragment" -->
+逐步出現
+<!-- END_MARKDOWN_CELL -->
+
+<!-- MARKDOWN_CELL slideshow.slide_type="notes" -->
+講者備忘
+<!-- END_MARKDOWN_CELL -->
+
+<!-- CODE_CELL slideshow.slide_type="skip" -->
+fmt.Println("不顯示")
+<!-- END_CODE_CELL -->
+
+<!-- MARKDOWN_CELL slideshow.slide_type="-" -->
+# 不換頁的標題
+<!-- END_MARKDOWN_CELL -->
+
+<!-- CODE_CELL slideshow.slide_type="slide" -->
+fmt.Println("新的一頁")
+<!-- END_CODE_CELL -->
+`
+	nb, err := NewParser(strings.NewReader(input)).Parse()
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	slides := buildDeck(nb)
+	if len(slides) != 2 {
+		t.Fatalf("Expected 2 slides, got %d", len(slides))
+	}
+	first := slides[0][0]
+	if len(first.Parts) != 3 || !first.Parts[1].Fragment || first.Parts[2].Fragment {
+		t.Errorf("Unexpected parts: %+v", first.Parts)
+	}
+	if len(first.Notes) != 1 || !strings.Contains(string(first.Notes[0]), "講者備忘") {
+		t.Errorf("Unexpected notes: %v", first.Notes)
+	}
+	for _, part := range first.Parts {
+		if strings.Contains(string(part.HTML), "不顯示") {
+			t.Error("Skipped cells should not be rendered")
+		}
+	}
+}
+
+func TestWriteSlides(t *testing.T) {
+	nb := NewNotebook()
+	nb.AddMarkdownCell("", "# **並行** 簡介")
+	nb.AddCodeCell("", `fmt.Println("hi")`)
+	nb.Cells[1].Outputs = []any{map[string]any{"output_type": "stream", "name": "stdout", "text": "hi\n"}}
+	nb.AddMarkdownCell("", "## 下一頁")
+	nb.Cells[2].Metadata["slideshow"] = map[string]any{"slide_type": "notes"}
+
+	var buf bytes.Buffer
+	if err := WriteSlides(&buf, nb); err != nil {
+		t.Fatalf("WriteSlides failed: %v", err)
+	}
+	got := buf.String()
+
+	for _, want := range []string{
+		"<title>並行 簡介</title>",
+		`<div class="slides">`,
+		`<span class="str">&#34;hi&#34;</span>`,
+		"<pre class=\"stdout\">hi\n</pre>",
+		`<aside class="notes">`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Output should contain %q", want)
+		}
+	}
+	if strings.Contains(got, "<script src=") || strings.Contains(got, "<link ") {
+		t.Error("Deck should be self-contained")
+	}
+	if strings.Count(got, "<section>") != 2 {
+		t.Errorf("Notes should not start a new slide, got %d sections", strings.Count(got, "<section>"))
+	}
+}
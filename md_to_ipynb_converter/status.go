@@ -0,0 +1,448 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hank/learning-go/ch9/converter/outline"
+)
+
+// chapterStatus 一章的進度：筆記、面試考題與改卷講解（.kiro/steering/product.md 的三個任務）
+type chapterStatus struct {
+	Number    int              `json:"number"`
+	Title     string           `json:"title"`
+	Notes     []notebookStatus `json:"notes"`
+	Questions *notebookStatus  `json:"questions"`
+	Review    *notebookStatus  `json:"review"`
+	// AnswerKey 由考題來源檔的 ANSWER 區塊產生的解答
+	AnswerKey *notebookStatus `json:"answer_key,omitempty"`
+}
+
+// notebookStatus 一個 notebook 的狀態
+type notebookStatus struct {
+	Path string `json:"path"`
+	// Source 對應的來源檔；notebook 是直接編寫時為空
+	Source string `json:"source,omitempty"`
+	// Stale 重新轉換來源檔會產生不同的 cells
+	Stale    bool         `json:"stale"`
+	Cells    int          `json:"cells"`
+	Markdown int          `json:"markdown"`
+	Code     int          `json:"code"`
+	Exec     *execSummary `json:"exec,omitempty"`
+}
+
+// execSummary 執行 code cells 的統計；片段（沒有 func main 的 cell）算在 Skipped
+type execSummary struct {
+	Passed   int           `json:"passed"`
+	Failed   int           `json:"failed"`
+	Skipped  int           `json:"skipped"`
+	Failures []execFailure `json:"failures,omitempty"`
+}
+
+type execFailure struct {
+	Cell    int    `json:"cell"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// statusReport status 子命令輸出的報告
+type statusReport struct {
+	Chapters []chapterStatus `json:"chapters"`
+}
+
+// statusOptions 產生報告時的選項
+type statusOptions struct {
+	Exec    bool
+	Timeout time.Duration
+}
+
+// runStatus status 子命令：依 product.md 的規範列出每章的進度
+func runStatus(args []string) error {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	tocPath := fs.String("toc", "", "全書目錄（預設從專案目錄往上尋找 "+outline.FileName+"）")
+	format := fs.String("format", "table", "輸出格式：table、json 或 md")
+	output := fs.String("o", "", "寫入檔案而不是標準輸出")
+	execute := fs.Bool("exec", false, "編譯並執行每個 code cell（較慢）")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s status [--exec] [--format table|json|md] [-o file] [project-dir]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "省略專案目錄時使用 %s 所在的目錄\n", outline.FileName)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	start := fs.Arg(0)
+	if start == "" {
+		start = "."
+	}
+	if *tocPath == "" {
+		found, err := findFileUpward(start, outline.FileName)
+		if err != nil {
+			return err
+		}
+		*tocPath = found
+	}
+	root := fs.Arg(0)
+	if root == "" {
+		if *tocPath == "" {
+			return fmt.Errorf("%s not found; pass the project directory", outline.FileName)
+		}
+		root = filepath.Dir(*tocPath)
+	}
+
+	var chapters []outline.Chapter
+	if *tocPath != "" {
+		book, err := outline.ParseFile(*tocPath)
+		if err != nil {
+			return err
+		}
+		chapters = book.Numbered()
+	}
+
+	cfg, err := LoadConfig(root)
+	if err != nil {
+		return err
+	}
+	report, err := buildStatus(root, chapters, statusOptions{Exec: *execute, Timeout: time.Duration(cfg.ExecTimeout)})
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	switch *format {
+	case "table":
+		writeStatusTable(&buf, report)
+	case "md":
+		writeStatusMarkdown(&buf, report)
+	case "json":
+		encoder := json.NewEncoder(&buf)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown format %q: use table, json or md", *format)
+	}
+
+	if *output == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	if err := writeFileAtomic(*output, buf.Bytes(), false); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+	fmt.Printf("✅ 已寫入: %s\n", *output)
+	return nil
+}
+
+// buildStatus 掃描 root 下的 chN 目錄；目錄中有列出的章即使沒有資料夾也會出現在報告中
+func buildStatus(root string, chapters []outline.Chapter, opts statusOptions) (*statusReport, error) {
+	byNumber := make(map[int]*chapterStatus)
+	report := &statusReport{}
+	for _, chapter := range chapters {
+		report.Chapters = append(report.Chapters, chapterStatus{Number: chapter.Number, Title: chapter.Title, Notes: []notebookStatus{}})
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if number := chapterOfPath(entry.Name()); entry.IsDir() && number > 0 && entry.Name() == fmt.Sprintf("ch%d", number) {
+			found := false
+			for _, chapter := range report.Chapters {
+				found = found || chapter.Number == number
+			}
+			if !found {
+				report.Chapters = append(report.Chapters, chapterStatus{Number: number, Notes: []notebookStatus{}})
+			}
+		}
+	}
+	sort.SliceStable(report.Chapters, func(i, j int) bool { return report.Chapters[i].Number < report.Chapters[j].Number })
+	for i := range report.Chapters {
+		byNumber[report.Chapters[i].Number] = &report.Chapters[i]
+	}
+
+	for number, chapter := range byNumber {
+		if err := scanChapterStatus(root, fmt.Sprintf("ch%d", number), chapter, opts); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// scanChapterStatus 讀取一章資料夾中的 notebook 與來源檔
+func scanChapterStatus(root, dirName string, chapter *chapterStatus, opts statusOptions) error {
+	dir := filepath.Join(root, dirName)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		return err
+	}
+
+	// 來源檔對應的 notebook
+	sources := make(map[string]string)
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if output, ok := cfg.OutputPathFor(path); ok && !entry.IsDir() {
+			sources[output] = path
+		}
+	}
+
+	prefix := fmt.Sprintf("ch%d_", chapter.Number)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".ipynb" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		status, err := notebookStatusOf(path, sources[path], opts)
+		if err != nil {
+			return err
+		}
+		status.Path = filepath.ToSlash(filepath.Join(dirName, entry.Name()))
+		if status.Source != "" {
+			status.Source = filepath.ToSlash(filepath.Join(dirName, filepath.Base(status.Source)))
+		}
+
+		switch entry.Name() {
+		case prefix + "interview_questions.ipynb":
+			chapter.Questions = status
+		case prefix + "interview_answers_review.ipynb":
+			chapter.Review = status
+		case prefix + "interview_answer_key.ipynb":
+			chapter.AnswerKey = status
+		default:
+			chapter.Notes = append(chapter.Notes, *status)
+		}
+	}
+	return nil
+}
+
+func notebookStatusOf(path, source string, opts statusOptions) (*notebookStatus, error) {
+	nb, err := ReadNotebook(path)
+	if err != nil {
+		return nil, err
+	}
+	status := &notebookStatus{Source: source, Cells: len(nb.Cells)}
+	for _, cell := range nb.Cells {
+		switch cell.CellType {
+		case "markdown":
+			status.Markdown++
+		case "code":
+			status.Code++
+		}
+	}
+
+	if source != "" {
+		generated, err := LoadNotebookFile(source)
+		if err != nil {
+			return nil, err
+		}
+		preserveAnswers(nb, generated)
+		status.Stale = len(DiffNotebooks(nb, generated)) > 0
+	}
+
+	if opts.Exec {
+		summary := &execSummary{}
+		for _, run := range ExecuteNotebook(context.Background(), nb, opts.Timeout) {
+			switch {
+			case run.Status == runSkipped:
+				summary.Skipped++
+			case run.Failed():
+				summary.Failed++
+				summary.Failures = append(summary.Failures, execFailure{Cell: run.Cell, Status: run.Status, Message: firstLine(strings.TrimSpace(run.Stderr))})
+			default:
+				summary.Passed++
+			}
+		}
+		status.Exec = summary
+	}
+	return status, nil
+}
+
+// statusRow 表格中的一列
+func statusRow(chapter chapterStatus) []string {
+	title := chapter.Title
+	if title == "" {
+		title = "—"
+	}
+
+	notes := "✗"
+	if len(chapter.Notes) > 0 {
+		notes = fmt.Sprintf("✓ %d", len(chapter.Notes))
+	}
+	exists := func(status *notebookStatus) string {
+		if status == nil {
+			return "✗"
+		}
+		return "✓"
+	}
+
+	all := chapter.notebooks()
+	var markdown, code, withSource, stale, passed, ran int
+	executed := false
+	for _, status := range all {
+		markdown += status.Markdown
+		code += status.Code
+		if status.Source != "" {
+			withSource++
+			if status.Stale {
+				stale++
+			}
+		}
+		if status.Exec != nil {
+			executed = true
+			passed += status.Exec.Passed
+			ran += status.Exec.Passed + status.Exec.Failed
+		}
+	}
+
+	cells := "—"
+	if len(all) > 0 {
+		cells = fmt.Sprintf("%d / %d", markdown, code)
+	}
+	source := "—"
+	switch {
+	case stale > 0:
+		source = fmt.Sprintf("過期 %d/%d", stale, withSource)
+	case withSource > 0:
+		source = "最新"
+	}
+	execution := "—"
+	if executed {
+		execution = fmt.Sprintf("%d/%d", passed, ran)
+	}
+
+	return []string{fmt.Sprint(chapter.Number), title, notes, exists(chapter.Questions), exists(chapter.Review), cells, source, execution}
+}
+
+// notebooks 依筆記、考題、講解的順序回傳這章所有的 notebook
+func (c chapterStatus) notebooks() []notebookStatus {
+	all := append([]notebookStatus(nil), c.Notes...)
+	for _, status := range []*notebookStatus{c.Questions, c.Review} {
+		if status != nil {
+			all = append(all, *status)
+		}
+	}
+	return all
+}
+
+var statusHeader = []string{"章", "標題", "筆記", "考題", "講解", "Cells (md / code)", "來源", "執行"}
+
+// writeStatusTable 輸出對齊的終端機表格，並列出執行失敗的 cells
+func writeStatusTable(w io.Writer, report *statusReport) {
+	rows := [][]string{statusHeader}
+	for _, chapter := range report.Chapters {
+		rows = append(rows, statusRow(chapter))
+	}
+
+	widths := make([]int, len(statusHeader))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], displayWidth(cell))
+		}
+	}
+	for r, row := range rows {
+		var line strings.Builder
+		for i, cell := range row {
+			if i > 0 {
+				line.WriteString("  ")
+			}
+			line.WriteString(cell + strings.Repeat(" ", widths[i]-displayWidth(cell)))
+		}
+		fmt.Fprintln(w, strings.TrimRight(line.String(), " "))
+		if r == 0 {
+			total := len(widths)*2 - 2
+			for _, width := range widths {
+				total += width
+			}
+			fmt.Fprintln(w, strings.Repeat("─", total))
+		}
+	}
+
+	for _, chapter := range report.Chapters {
+		for _, status := range chapter.notebooks() {
+			if status.Exec == nil {
+				continue
+			}
+			for _, failure := range status.Exec.Failures {
+				fmt.Fprintf(w, "❌ %s cell %d (%s): %s\n", status.Path, failure.Cell, failure.Status, failure.Message)
+			}
+		}
+	}
+}
+
+// writeStatusMarkdown 輸出可以提交到 repo 的進度頁
+func writeStatusMarkdown(w io.Writer, report *statusReport) {
+	fmt.Fprintln(w, "# 學習進度")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| "+strings.Join(statusHeader, " | ")+" |")
+	fmt.Fprintln(w, "|"+strings.Repeat("---|", len(statusHeader)))
+	for _, chapter := range report.Chapters {
+		row := statusRow(chapter)
+		for i := range row {
+			row[i] = strings.ReplaceAll(row[i], "|", `\|`)
+		}
+		fmt.Fprintln(w, "| "+strings.Join(row, " | ")+" |")
+	}
+
+	var failures []string
+	for _, chapter := range report.Chapters {
+		for _, status := range chapter.notebooks() {
+			if status.Exec == nil {
+				continue
+			}
+			for _, failure := range status.Exec.Failures {
+				failures = append(failures, fmt.Sprintf("- `%s` cell %d（%s）：%s", status.Path, failure.Cell, failure.Status, failure.Message))
+			}
+		}
+	}
+	if len(failures) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "## 執行失敗的 cells")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, strings.Join(failures, "\n"))
+	}
+}
+
+// displayWidth 終端機中的顯示寬度：中日韓文字與全形符號佔兩格
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		switch {
+		case r >= 0x1100 && r <= 0x115F,
+			r >= 0x2E80 && r <= 0xA4CF,
+			r >= 0xAC00 && r <= 0xD7A3,
+			r >= 0xF900 && r <= 0xFAFF,
+			r >= 0xFE30 && r <= 0xFE4F,
+			r >= 0xFF00 && r <= 0xFF60,
+			r >= 0xFFE0 && r <= 0xFFE6,
+			r >= 0x20000:
+			width += 2
+		default:
+			width++
+		}
+	}
+	return width
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hank/learning-go/ch9/converter/outline"
+)
+
+func TestBuildStatus(t *testing.T) {
+	root := t.TempDir()
+	source := filepath.Join(root, "ch2", "ch2_types_source.md")
+	writeTestFile(t, source, "<!-- MARKDOWN_CELL -->\n# 第二章\n<!-- END_MARKDOWN_CELL -->\n\n<!-- CODE_CELL -->\n```go\nx := 1\n```\n<!-- END_CODE_CELL -->\n")
+	notebook := filepath.Join(root, "ch2", "ch2_types.ipynb")
+	if err := convert(source, notebook); err != nil {
+		t.Fatal(err)
+	}
+	if err := convert(source, filepath.Join(root, "ch2", "ch2_interview_questions.ipynb")); err != nil {
+		t.Fatal(err)
+	}
+	// 來源改了但沒有重新轉換
+	writeTestFile(t, source, "<!-- MARKDOWN_CELL -->\n# 第二章 基本型態\n<!-- END_MARKDOWN_CELL -->\n")
+	if err := os.MkdirAll(filepath.Join(root, "ch4"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	chapters := []outline.Chapter{{Number: 1, Title: "設定你的 Go 環境"}, {Number: 2, Title: "基本型態與宣告"}}
+	report, err := buildStatus(root, chapters, statusOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Chapters) != 3 || report.Chapters[2].Number != 4 {
+		t.Fatalf("Expected chapters 1, 2 and the ch4 directory, got %+v", report.Chapters)
+	}
+	ch2 := report.Chapters[1]
+	if len(ch2.Notes) != 1 || ch2.Questions == nil || ch2.Review != nil {
+		t.Fatalf("Unexpected ch2 status: %+v", ch2)
+	}
+	note := ch2.Notes[0]
+	if note.Path != "ch2/ch2_types.ipynb" || note.Source != "ch2/ch2_types_source.md" || !note.Stale {
+		t.Errorf("Expected a stale note with its source, got %+v", note)
+	}
+	if note.Cells != 2 || note.Markdown != 1 || note.Code != 1 || note.Exec != nil {
+		t.Errorf("Unexpected cell counts: %+v", note)
+	}
+	if ch2.Questions.Source != "" || ch2.Questions.Stale {
+		t.Errorf("Questions notebook has no source and cannot be stale: %+v", ch2.Questions)
+	}
+
+	var table, markdown bytes.Buffer
+	writeStatusTable(&table, report)
+	writeStatusMarkdown(&markdown, report)
+	for _, want := range []string{"基本型態與宣告", "✓ 1", "2 / 2", "過期 1/1"} {
+		if !strings.Contains(table.String(), want) {
+			t.Errorf("Table should contain %q:\n%s", want, table.String())
+		}
+	}
+	if !strings.Contains(markdown.String(), "| 2 | 基本型態與宣告 | ✓ 1 | ✓ | ✗ | 2 / 2 | 過期 1/1 | — |") {
+		t.Errorf("Unexpected Markdown:\n%s", markdown.String())
+	}
+
+	// 分隔線至少與標題列一樣寬（中文字佔兩格）
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	header := displayWidth(strings.TrimRight(lines[0], " "))
+	if got := displayWidth(lines[1]); got < header {
+		t.Errorf("Separator (%d) should be at least as wide as the header (%d)", got, header)
+	}
+}
+
+func TestRunStatus_JSON(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, outline.FileName), "## 第一章 設定你的 Go 環境 ..... 1\n")
+	source := filepath.Join(root, "ch1", "ch1_note_source.md")
+	writeTestFile(t, source, "<!-- MARKDOWN_CELL -->\n# 第一章\n<!-- END_MARKDOWN_CELL -->\n")
+	if err := convert(source, filepath.Join(root, "ch1", "ch1_note.ipynb")); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(root, "status.json")
+	if err := run([]string{"status", "--format", "json", "-o", output, root}); err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+
+	var report statusReport
+	if err := json.Unmarshal([]byte(readFile(t, output)), &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Chapters) != 1 || report.Chapters[0].Title != "設定你的 Go 環境" || len(report.Chapters[0].Notes) != 1 || report.Chapters[0].Notes[0].Stale {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	if got := displayWidth("Go 的並行"); got != 9 {
+		t.Errorf("displayWidth = %d, want 9", got)
+	}
+}
@@ -0,0 +1,414 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 衝突標記，格式與 git 相同
+const (
+	conflictStart  = "<<<<<<< markdown"
+	conflictMiddle = "======="
+	conflictEnd    = ">>>>>>> notebook"
+)
+
+// syncEntry 合併後的單一 cell
+type syncEntry struct {
+	mdIndex  int    // 對應到 .md 中的 cell 索引，-1 表示要新增到 .md
+	mdText   string // 要寫進 .md 的內容
+	mdEdit   bool   // .md 中的內容需要改寫
+	mdDelete bool   // 要從 .md 中刪除
+	conflict bool   // 是否為衝突
+	cellType string
+	nbCell   *Cell // 要寫進 notebook 的 cell，nil 表示 notebook 中不存在
+}
+
+// SyncReport 記錄 sync 的結果
+type SyncReport struct {
+	ToMarkdown int // 由 notebook 套用到 .md 的變更數
+	ToNotebook int // 由 .md 套用到 notebook 的變更數
+	Conflicts  int
+}
+
+// String 輸出人類可讀的摘要
+func (r SyncReport) String() string {
+	return fmt.Sprintf("notebook → md %d 個, md → notebook %d 個, 衝突 %d 個", r.ToMarkdown, r.ToNotebook, r.Conflicts)
+}
+
+func runSync(args []string) error {
+	fs := flag.NewFlagSet("sync", flag.ContinueOnError)
+	backup := fs.Bool("backup", false, "覆寫前把既有的 .md 與 .ipynb 保存成 .bak")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s sync [--backup] input.md output.ipynb\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	report, err := syncFiles(fs.Arg(0), fs.Arg(1), *backup)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🔁 同步: %s\n", report)
+	if report.Conflicts > 0 {
+		return fmt.Errorf("%d conflict(s) written to %s; resolve them and run sync again", report.Conflicts, fs.Arg(0))
+	}
+
+	fmt.Printf("✅ 成功同步: %s <-> %s\n", fs.Arg(0), fs.Arg(1))
+	return nil
+}
+
+// syncBasePath 回傳 notebook 對應的 base snapshot 路徑，例如 ch10/.ch10_x.ipynb.sync-base
+func syncBasePath(notebookPath string) string {
+	dir, name := filepath.Split(notebookPath)
+	return filepath.Join(dir, "."+name+".sync-base")
+}
+
+// syncFiles 以 base snapshot 為共同祖先，對 .md 與 .ipynb 做三方合併
+func syncFiles(markdownPath, notebookPath string, backup bool) (SyncReport, error) {
+	var report SyncReport
+
+	source, err := os.ReadFile(markdownPath)
+	if err != nil {
+		return report, fmt.Errorf("failed to open input file: %w", err)
+	}
+	if bytes.Contains(source, []byte(conflictStart)) || bytes.Contains(source, []byte(conflictEnd)) {
+		return report, fmt.Errorf("%s still contains unresolved conflict markers", markdownPath)
+	}
+
+	cfg, err := LoadConfig(markdownPath)
+	if err != nil {
+		return report, err
+	}
+	parser := NewFileParser(bytes.NewReader(source), markdownPath, cfg)
+	markdown, err := parser.Parse()
+	if err != nil {
+		return report, fmt.Errorf("failed to parse: %w", err)
+	}
+	// 寫回 .md 時使用 <!-- CODE_CELL --> 標記，其他語法無法保留
+	if parser.Format != FormatMarkers || parser.Config.Dialect != "markers" {
+		return report, fmt.Errorf("sync only supports marker Markdown files")
+	}
+
+	notebook, err := ReadNotebook(notebookPath)
+	if errors.Is(err, os.ErrNotExist) {
+		// 還沒有 notebook：等同第一次轉換
+		notebook = NewNotebook()
+	} else if err != nil {
+		return report, fmt.Errorf("failed to read notebook: %w", err)
+	}
+
+	basePath := syncBasePath(notebookPath)
+	base, err := ReadNotebook(basePath)
+	if errors.Is(err, os.ErrNotExist) {
+		// 沒有 base snapshot 時以目前的 notebook 為基準，行為與 convert --update 相同
+		fmt.Printf("ℹ️  尚無 %s，以目前的 notebook 為基準\n", basePath)
+		base = notebook
+	} else if err != nil {
+		return report, fmt.Errorf("failed to read sync base: %w", err)
+	}
+
+	entries, report := mergeThreeWay(base, markdown, notebook)
+
+	// 寫回 .md
+	merged := applyMarkdownEdits(strings.Split(string(source), "\n"), parser.Spans(), entries)
+	if merged != string(source) {
+		if err := writeFileAtomic(markdownPath, []byte(merged), backup); err != nil {
+			return report, fmt.Errorf("failed to write markdown: %w", err)
+		}
+	}
+
+	// 寫回 notebook（保留未變更 cell 的 outputs）
+	generated := NewNotebook()
+	generated.Metadata = markdown.Metadata
+	for _, entry := range entries {
+		if entry.nbCell != nil {
+			generated.Cells = append(generated.Cells, *entry.nbCell)
+		}
+	}
+	result := generated
+	if len(notebook.Cells) > 0 {
+		result, _ = UpdateNotebook(notebook, generated)
+	}
+	jsonData, err := result.ToJSON()
+	if err != nil {
+		return report, fmt.Errorf("failed to convert to JSON: %w", err)
+	}
+	if err := writeFileAtomic(notebookPath, jsonData, backup); err != nil {
+		return report, fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	// 記錄新的 base snapshot（只需要 cell 的類型、ID 與內容）
+	snapshot := NewNotebook()
+	for _, cell := range result.Cells {
+		snapshot.Cells = append(snapshot.Cells, Cell{
+			CellType: cell.CellType,
+			ID:       cell.ID,
+			Metadata: CellMetadata{},
+			Source:   cell.Source,
+		})
+	}
+	baseData, err := snapshot.ToJSON()
+	if err != nil {
+		return report, fmt.Errorf("failed to convert to JSON: %w", err)
+	}
+	if err := writeFileAtomic(basePath, baseData, false); err != nil {
+		return report, fmt.Errorf("failed to write sync base: %w", err)
+	}
+
+	return report, nil
+}
+
+// mergeThreeWay 以 cell 為單位合併 .md 與 notebook 的變更，結果依 .md 的順序排列
+func mergeThreeWay(base, markdown, notebook *Notebook) ([]syncEntry, SyncReport) {
+	var report SyncReport
+
+	mdToBase := matchCells(base.Cells, markdown.Cells)
+	nbToBase := matchCells(base.Cells, notebook.Cells)
+
+	baseToNB := make([]int, len(base.Cells))
+	for i := range baseToNB {
+		baseToNB[i] = -1
+	}
+	for n, b := range nbToBase {
+		if b >= 0 {
+			baseToNB[b] = n
+		}
+	}
+
+	// 兩邊都新增了相同內容的 cell 時視為同一個
+	mdToNB := make([]int, len(markdown.Cells))
+	nbUsed := make([]bool, len(notebook.Cells))
+	for k, b := range mdToBase {
+		mdToNB[k] = -1
+		if b >= 0 {
+			mdToNB[k] = baseToNB[b]
+		}
+		if mdToNB[k] >= 0 {
+			nbUsed[mdToNB[k]] = true
+		}
+	}
+	for k, b := range mdToBase {
+		if b >= 0 {
+			continue
+		}
+		for n, cell := range notebook.Cells {
+			if !nbUsed[n] && nbToBase[n] < 0 && cell.CellType == markdown.Cells[k].CellType && cell.Text() == markdown.Cells[k].Text() {
+				mdToNB[k] = n
+				nbUsed[n] = true
+				break
+			}
+		}
+	}
+
+	// 依 .md 的順序處理每個 cell
+	var entries []syncEntry
+	for k, mdCell := range markdown.Cells {
+		entry := syncEntry{mdIndex: k, mdText: mdCell.Text(), cellType: mdCell.CellType}
+		b, n := mdToBase[k], mdToNB[k]
+
+		switch {
+		case b < 0 && n >= 0:
+			// 兩邊新增了相同的 cell
+			cell := notebook.Cells[n]
+			entry.nbCell = &cell
+		case b < 0:
+			// 只在 .md 新增
+			cell := mdCell
+			entry.nbCell = &cell
+			report.ToNotebook++
+		case n < 0:
+			// notebook 刪除了這個 cell
+			if mdCell.Text() == base.Cells[b].Text() {
+				entry.mdDelete = true
+				report.ToMarkdown++
+			} else {
+				entry.mdText = conflictText(mdCell.Text(), "")
+				entry.mdEdit, entry.conflict = true, true
+				report.Conflicts++
+			}
+		default:
+			baseText, mdText, nbText := base.Cells[b].Text(), mdCell.Text(), notebook.Cells[n].Text()
+			cell := notebook.Cells[n]
+			entry.nbCell = &cell
+
+			switch {
+			case mdText == nbText:
+				// 沒有變更，或兩邊改成相同內容
+			case nbText == baseText:
+				cell.Source = mdCell.Source
+				report.ToNotebook++
+			case mdText == baseText:
+				entry.mdText, entry.mdEdit = nbText, true
+				report.ToMarkdown++
+			default:
+				// 兩邊都改了：notebook 保留自己的版本，.md 寫入衝突標記
+				entry.mdText = conflictText(mdText, nbText)
+				entry.mdEdit, entry.conflict = true, true
+				report.Conflicts++
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	// .md 刪除了的 cell
+	mdHasBase := map[int]bool{}
+	for _, b := range mdToBase {
+		if b >= 0 {
+			mdHasBase[b] = true
+		}
+	}
+	for b := range base.Cells {
+		n := baseToNB[b]
+		if mdHasBase[b] || n < 0 {
+			continue
+		}
+		if notebook.Cells[n].Text() == base.Cells[b].Text() {
+			// notebook 沒改過，直接刪除
+			nbUsed[n] = true
+			report.ToNotebook++
+		}
+	}
+
+	// notebook 新增的 cell（或 .md 刪除但 notebook 修改過的 cell）插入到前一個 cell 之後
+	nbToEntry := map[int]int{}
+	for i, entry := range entries {
+		if entry.mdIndex >= 0 && mdToNB[entry.mdIndex] >= 0 {
+			nbToEntry[mdToNB[entry.mdIndex]] = i
+		}
+	}
+	var inserted []syncEntry
+	var anchors []int
+	for n, cell := range notebook.Cells {
+		if nbUsed[n] {
+			continue
+		}
+		entry := syncEntry{mdIndex: -1, mdText: cell.Text(), cellType: cell.CellType}
+		c := cell
+		entry.nbCell = &c
+		if b := nbToBase[n]; b >= 0 {
+			// .md 刪除但 notebook 修改過
+			entry.mdText = conflictText("", cell.Text())
+			entry.conflict = true
+			report.Conflicts++
+		} else {
+			report.ToMarkdown++
+		}
+
+		anchor := -1
+		for prev := n - 1; prev >= 0; prev-- {
+			if i, ok := nbToEntry[prev]; ok {
+				anchor = i
+				break
+			}
+		}
+		inserted = append(inserted, entry)
+		anchors = append(anchors, anchor)
+	}
+
+	var result []syncEntry
+	for i := -1; i < len(entries); i++ {
+		if i >= 0 {
+			result = append(result, entries[i])
+		}
+		for j, anchor := range anchors {
+			if anchor == i {
+				result = append(result, inserted[j])
+			}
+		}
+	}
+
+	return result, report
+}
+
+// conflictText 產生 git 風格的衝突內容
+func conflictText(markdown, notebook string) string {
+	var b strings.Builder
+	b.WriteString(conflictStart + "\n")
+	if markdown != "" {
+		b.WriteString(strings.TrimSuffix(markdown, "\n") + "\n")
+	}
+	b.WriteString(conflictMiddle + "\n")
+	if notebook != "" {
+		b.WriteString(strings.TrimSuffix(notebook, "\n") + "\n")
+	}
+	b.WriteString(conflictEnd)
+	return b.String()
+}
+
+// applyMarkdownEdits 依合併結果改寫 .md，cell 以外的內容保持不變
+func applyMarkdownEdits(lines []string, spans []CellSpan, entries []syncEntry) string {
+	byIndex := map[int]syncEntry{}
+	for _, entry := range entries {
+		if entry.mdIndex >= 0 {
+			byIndex[entry.mdIndex] = entry
+		}
+	}
+
+	// 要插入的新 cell 放在前一個 .md cell 之後
+	insertAfter := map[int][]syncEntry{}
+	last := -1
+	for _, entry := range entries {
+		if entry.mdIndex >= 0 {
+			last = entry.mdIndex
+			continue
+		}
+		insertAfter[last] = append(insertAfter[last], entry)
+	}
+
+	var out []string
+	writeInserted := func(k int) {
+		for _, entry := range insertAfter[k] {
+			cell := Cell{CellType: entry.cellType, Source: splitLines(entry.mdText)}
+			if entry.nbCell != nil {
+				cell.ID = entry.nbCell.ID
+			}
+			out = append(out, "", renderMarkerCell(cell))
+		}
+	}
+
+	if len(spans) == 0 {
+		out = append(out, lines...)
+		writeInserted(-1)
+		return strings.Join(out, "\n")
+	}
+
+	out = append(out, lines[:spans[0].Start]...)
+	writeInserted(-1)
+	for k, span := range spans {
+		entry := byIndex[k]
+		switch {
+		case entry.mdDelete:
+		case entry.mdEdit:
+			out = append(out, lines[span.Start])
+			out = append(out, renderCellBody(entry.cellType, entry.mdText))
+			if span.Closed {
+				out = append(out, lines[span.End])
+			}
+		default:
+			out = append(out, lines[span.Start:span.End+1]...)
+		}
+		writeInserted(k)
+
+		// cell 之間的內容（空行、沒有標記的文字）
+		next := len(lines)
+		if k+1 < len(spans) {
+			next = spans[k+1].Start
+		}
+		out = append(out, lines[span.End+1:next]...)
+	}
+
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const syncInput = `<!-- MARKDOWN_CELL -->
+# 標題
+
+說明文字
+<!-- END_MARKDOWN_CELL -->
+
+這段沒有標記，應該原樣保留。
+
+<!-- CODE_CELL -->
+` + "```go" + `
+fmt.Println("hello")
+` + "```" + `
+<!-- END_CODE_CELL -->
+`
+
+// setupSync 建立 .md 並執行第一次 sync，回傳兩個檔案的路徑
+func setupSync(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	mdPath := filepath.Join(dir, "test_source.md")
+	nbPath := filepath.Join(dir, "test.ipynb")
+
+	if err := os.WriteFile(mdPath, []byte(syncInput), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := syncFiles(mdPath, nbPath, false); err != nil {
+		t.Fatalf("Initial sync failed: %v", err)
+	}
+	return mdPath, nbPath
+}
+
+func editNotebookCell(t *testing.T, nbPath string, index int, text string) {
+	t.Helper()
+	nb, err := ReadNotebook(nbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nb.Cells[index].Source = splitLines(text)
+	data, _ := nb.ToJSON()
+	if err := os.WriteFile(nbPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSync_NotebookEditToMarkdown(t *testing.T) {
+	mdPath, nbPath := setupSync(t)
+	editNotebookCell(t, nbPath, 0, "# 標題\n\n修正過的說明文字")
+
+	report, err := syncFiles(mdPath, nbPath, false)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if report.ToMarkdown != 1 || report.Conflicts != 0 {
+		t.Errorf("Expected 1 change to markdown, got %+v", report)
+	}
+
+	md := readFile(t, mdPath)
+	if !strings.Contains(md, "修正過的說明文字") {
+		t.Error("Notebook edit should be written back to markdown")
+	}
+	if !strings.Contains(md, "這段沒有標記，應該原樣保留。") {
+		t.Error("Content outside markers should be preserved")
+	}
+}
+
+func TestSync_MarkdownEditToNotebook(t *testing.T) {
+	mdPath, nbPath := setupSync(t)
+	edited := strings.Replace(syncInput, `"hello"`, `"hello, world"`, 1)
+	if err := os.WriteFile(mdPath, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := syncFiles(mdPath, nbPath, false)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if report.ToNotebook != 1 {
+		t.Errorf("Expected 1 change to notebook, got %+v", report)
+	}
+
+	nb, _ := ReadNotebook(nbPath)
+	if !strings.Contains(nb.Cells[1].Text(), "hello, world") {
+		t.Errorf("Markdown edit should reach notebook, got %q", nb.Cells[1].Text())
+	}
+	if readFile(t, mdPath) != edited {
+		t.Error("Markdown should not change when only it was edited")
+	}
+}
+
+func TestSync_ConflictMarkers(t *testing.T) {
+	mdPath, nbPath := setupSync(t)
+	editNotebookCell(t, nbPath, 1, `fmt.Println("from notebook")`)
+	edited := strings.Replace(syncInput, `"hello"`, `"from markdown"`, 1)
+	if err := os.WriteFile(mdPath, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := syncFiles(mdPath, nbPath, false)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if report.Conflicts != 1 {
+		t.Fatalf("Expected 1 conflict, got %+v", report)
+	}
+
+	md := readFile(t, mdPath)
+	want := "```go\n" + conflictStart + "\nfmt.Println(\"from markdown\")\n" + conflictMiddle + "\nfmt.Println(\"from notebook\")\n" + conflictEnd + "\n```"
+	if !strings.Contains(md, want) {
+		t.Errorf("Conflict markers should be written inside the cell, got:\n%s", md)
+	}
+
+	// 未解決衝突前不能再次同步
+	if _, err := syncFiles(mdPath, nbPath, false); err == nil {
+		t.Error("Expected error while conflict markers remain")
+	}
+
+	// 解決衝突後 .md 的版本會同步到 notebook
+	resolved := strings.Replace(syncInput, `"hello"`, `"resolved"`, 1)
+	if err := os.WriteFile(mdPath, []byte(resolved), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := syncFiles(mdPath, nbPath, false); err != nil {
+		t.Fatalf("Sync after resolving failed: %v", err)
+	}
+	nb, _ := ReadNotebook(nbPath)
+	if !strings.Contains(nb.Cells[1].Text(), "resolved") {
+		t.Errorf("Resolved content should reach notebook, got %q", nb.Cells[1].Text())
+	}
+}
+
+func TestSync_NotebookInsertedCell(t *testing.T) {
+	mdPath, nbPath := setupSync(t)
+	nb, _ := ReadNotebook(nbPath)
+	nb.AddMarkdownCell("a1b2c3", "## 在 Jupyter 新增的小節")
+	data, _ := nb.ToJSON()
+	if err := os.WriteFile(nbPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := syncFiles(mdPath, nbPath, false); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	md := readFile(t, mdPath)
+	if !strings.Contains(md, `<!-- MARKDOWN_CELL id="a1b2c3" -->`+"\n## 在 Jupyter 新增的小節") {
+		t.Errorf("Inserted cell should be added to markdown, got:\n%s", md)
+	}
+
+	// 再同步一次應該沒有任何變更
+	report, err := syncFiles(mdPath, nbPath, false)
+	if err != nil {
+		t.Fatalf("Second sync failed: %v", err)
+	}
+	if report != (SyncReport{}) {
+		t.Errorf("Expected no changes on second sync, got %+v", report)
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// tocCellID 自動插入的目錄 cell 的 ID，再次產生時以此找到並更新
+	tocCellID = "toc"
+	// tocStartMarker 與 tocEndMarker 標示 markdown cell 中的目錄位置，兩者之間的內容會被重新產生
+	tocStartMarker = "<!-- TOC -->"
+	tocEndMarker   = "<!-- /TOC -->"
+	// defaultTOCDepth 目錄預設列到 ### 標題
+	defaultTOCDepth = 3
+)
+
+// UpdateTOC 產生連到各標題的目錄，回傳是否有變更
+//
+// 目錄放在含有 <!-- TOC --> 的 markdown cell 中（取代到 <!-- /TOC --> 為止的內容）；
+// 沒有標記時更新 ID 為 toc 的 cell，insert 為 true 且兩者都沒有時在標題 cell 之後插入一個。
+// 列出第一個一級標題（notebook 的標題）以外、層級不超過 depth 的標題，錨點與 JupyterLab 相同。
+func UpdateTOC(nb *Notebook, depth int, insert bool) bool {
+	if depth <= 0 {
+		depth = defaultTOCDepth
+	}
+	toc := renderTOC(collectTOCHeadings(nb, depth))
+
+	changed := false
+	marked := false
+	for i := range nb.Cells {
+		cell := &nb.Cells[i]
+		if cell.CellType != "markdown" {
+			continue
+		}
+		text, ok := replaceTOCBlock(cell.Text(), toc)
+		if !ok {
+			continue
+		}
+		marked = true
+		if text != cell.Text() {
+			cell.Source = splitLines(text)
+			changed = true
+		}
+	}
+	if marked {
+		return changed
+	}
+
+	content := "**目錄**\n\n" + toc
+	for i := range nb.Cells {
+		if nb.Cells[i].ID == tocCellID {
+			if nb.Cells[i].Text() == content {
+				return false
+			}
+			nb.Cells[i].Source = splitLines(content)
+			return true
+		}
+	}
+	if !insert {
+		return false
+	}
+
+	cell := Cell{
+		CellType:   "markdown",
+		ID:         tocCellID,
+		Metadata:   CellMetadata{},
+		Source:     splitLines(content),
+		explicitID: true,
+	}
+	at := titleCellIndex(nb) + 1
+	nb.Cells = append(nb.Cells[:at], append([]Cell{cell}, nb.Cells[at:]...)...)
+	return true
+}
+
+// collectTOCHeadings 收集目錄要列出的標題，略過目錄本身與 notebook 的標題
+func collectTOCHeadings(nb *Notebook, depth int) []Heading {
+	renderer := &MarkdownRenderer{}
+	for _, cell := range nb.Cells {
+		if cell.CellType != "markdown" || cell.ID == tocCellID {
+			continue
+		}
+		text := cell.Text()
+		if before, after, ok := strings.Cut(text, tocStartMarker); ok {
+			_, rest, _ := strings.Cut(after, tocEndMarker)
+			text = before + rest
+		}
+		renderer.Render(text)
+	}
+
+	var headings []Heading
+	titleSeen := false
+	for _, heading := range renderer.Headings {
+		if heading.Level == 1 && !titleSeen {
+			titleSeen = true
+			continue
+		}
+		if heading.Level <= depth {
+			headings = append(headings, heading)
+		}
+	}
+	return headings
+}
+
+// renderTOC 輸出巢狀清單；縮排以最上層的標題為準
+func renderTOC(headings []Heading) string {
+	top := 0
+	for _, heading := range headings {
+		if top == 0 || heading.Level < top {
+			top = heading.Level
+		}
+	}
+
+	var b strings.Builder
+	for _, heading := range headings {
+		label := strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`).Replace(heading.Text)
+		fmt.Fprintf(&b, "%s- [%s](#%s)\n", strings.Repeat("  ", heading.Level-top), label, tocAnchor(heading.ID))
+	}
+	return b.String()
+}
+
+// tocAnchor 連結中的錨點；括號會打斷 Markdown 連結，改以百分比編碼（JupyterLab 與瀏覽器都會解碼）
+func tocAnchor(id string) string {
+	return strings.NewReplacer("(", "%28", ")", "%29", "<", "%3C", ">", "%3E").Replace(id)
+}
+
+// replaceTOCBlock 以新的目錄取代 <!-- TOC --> 與 <!-- /TOC --> 之間的內容；沒有標記時 ok 為 false
+func replaceTOCBlock(text, toc string) (string, bool) {
+	before, after, ok := strings.Cut(text, tocStartMarker)
+	if !ok {
+		return text, false
+	}
+	if _, rest, ok := strings.Cut(after, tocEndMarker); ok {
+		after = rest
+	}
+	return before + tocStartMarker + "\n" + toc + tocEndMarker + after, true
+}
+
+// titleCellIndex 回傳含有一級標題的第一個 markdown cell；沒有時回傳 -1（目錄放在最前面）
+func titleCellIndex(nb *Notebook) int {
+	for i, cell := range nb.Cells {
+		if cell.CellType != "markdown" {
+			continue
+		}
+		renderer := &MarkdownRenderer{}
+		renderer.Render(cell.Text())
+		for _, heading := range renderer.Headings {
+			if heading.Level == 1 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// runTOC toc 子命令：在既有的 notebook 中插入或更新目錄
+func runTOC(args []string) error {
+	fs := flag.NewFlagSet("toc", flag.ContinueOnError)
+	depth := fs.Int("depth", defaultTOCDepth, "列出的最深標題層級")
+	check := fs.Bool("check", false, "只檢查目錄是否最新，不寫入檔案")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s toc [--depth N] [--check] notebook.ipynb...\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	var stale []string
+	for _, path := range fs.Args() {
+		nb, err := ReadNotebook(path)
+		if err != nil {
+			return err
+		}
+		if !UpdateTOC(nb, *depth, true) {
+			fmt.Printf("✅ 目錄已是最新: %s\n", path)
+			continue
+		}
+		if *check {
+			stale = append(stale, path)
+			continue
+		}
+		data, err := nb.ToJSON()
+		if err != nil {
+			return fmt.Errorf("failed to convert to JSON: %w", err)
+		}
+		if err := writeFileAtomic(path, data, false); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Printf("📝 已更新目錄: %s\n", path)
+	}
+	if len(stale) > 0 {
+		return fmt.Errorf("table of contents is out of date: %s", strings.Join(stale, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUpdateTOC_InsertAfterTitle(t *testing.T) {
+	nb := NewNotebook()
+	nb.AddMarkdownCell("intro", "# 第十章 Go 的並行\n\n前言")
+	nb.AddMarkdownCell("", "## 何時該使用並行\n\n### 並行不等於並列 (parallelism)")
+	nb.AddCodeCell("", "// ## 不是標題")
+	nb.AddMarkdownCell("", "```\n## 也不是標題\n```\n\n## **select** 與 `default`\n\n#### 太深的標題")
+
+	if !UpdateTOC(nb, 3, true) {
+		t.Fatal("Expected TOC to be inserted")
+	}
+	if nb.Cells[1].ID != tocCellID {
+		t.Fatalf("TOC should follow the title cell, got cells %q, %q", nb.Cells[0].ID, nb.Cells[1].ID)
+	}
+
+	want := "**目錄**\n" +
+		"\n" +
+		"- [何時該使用並行](#何時該使用並行)\n" +
+		"  - [並行不等於並列 (parallelism)](#並行不等於並列-%28parallelism%29)\n" +
+		"- [select 與 default](#select-與-default)\n"
+	if got := nb.Cells[1].Text(); got != want {
+		t.Errorf("TOC mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// 再次產生時更新同一個 cell，沒有變更就不改寫
+	if UpdateTOC(nb, 3, true) {
+		t.Error("Second run should not change anything")
+	}
+	if len(nb.Cells) != 5 {
+		t.Errorf("TOC should not be inserted twice, got %d cells", len(nb.Cells))
+	}
+
+	UpdateTOC(nb, 2, true)
+	if strings.Contains(nb.Cells[1].Text(), "並列") {
+		t.Error("Depth 2 should exclude ### headings")
+	}
+}
+
+func TestUpdateTOC_Marker(t *testing.T) {
+	nb := NewNotebook()
+	nb.AddMarkdownCell("", "# 標題")
+	nb.AddMarkdownCell("", "前言\n\n"+tocStartMarker+"\n- 舊的目錄\n"+tocEndMarker+"\n\n結尾")
+	nb.AddMarkdownCell("", "## 小節 A")
+
+	if !UpdateTOC(nb, 3, true) {
+		t.Fatal("Expected marker block to be refreshed")
+	}
+	if len(nb.Cells) != 3 {
+		t.Errorf("Marker should prevent inserting a TOC cell, got %d cells", len(nb.Cells))
+	}
+	want := "前言\n\n" + tocStartMarker + "\n- [小節 A](#小節-A)\n" + tocEndMarker + "\n\n結尾"
+	if got := nb.Cells[1].Text(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUpdateTOC_NoInsert(t *testing.T) {
+	nb := NewNotebook()
+	nb.AddMarkdownCell("", "# 標題\n\n## 小節")
+	if UpdateTOC(nb, 3, false) || len(nb.Cells) != 1 {
+		t.Error("TOC should only be inserted when requested")
+	}
+}
+
+func TestConvert_TOCFrontMatter(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "note.md")
+	output := filepath.Join(dir, "note.ipynb")
+	writeTestFile(t, input, `---
+toc: true
+toc_depth: 2
+---
+<!-- MARKDOWN_CELL -->
+# 標題
+<!-- END_MARKDOWN_CELL -->
+
+<!-- MARKDOWN_CELL -->
+## 何時該使用並行
+### 細節
+<!-- END_MARKDOWN_CELL -->
+`)
+
+	if err := convert(input, output); err != nil {
+		t.Fatalf("convert failed: %v", err)
+	}
+	nb, err := ReadNotebook(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nb.Cells) != 3 || nb.Cells[1].ID != tocCellID {
+		t.Fatalf("Expected TOC cell after the title, got %d cells", len(nb.Cells))
+	}
+	if got := nb.Cells[1].Text(); got != "**目錄**\n\n- [何時該使用並行](#何時該使用並行)\n" {
+		t.Errorf("Unexpected TOC: %q", got)
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// similarityThreshold 兩個 cell 內容相似度高於此值才視為同一個 cell 的修改版
+const similarityThreshold = 0.5
+
+// UpdateReport 記錄 --update 模式對既有 notebook 做了哪些變更
+type UpdateReport struct {
+	Unchanged int
+	Edited    []string
+	Inserted  []string
+	Removed   []string
+	Moved     []string
+}
+
+// String 輸出人類可讀的摘要
+func (r UpdateReport) String() string {
+	return fmt.Sprintf("未變更 %d 個, 修改 %d 個, 新增 %d 個, 刪除 %d 個, 移動 %d 個",
+		r.Unchanged, len(r.Edited), len(r.Inserted), len(r.Removed), len(r.Moved))
+}
+
+// UpdateNotebook 將重新產生的 notebook 合併進既有的 notebook
+//
+// 比對順序：先比對 Markdown 中明確指定的 ID，再比對完全相同的內容，
+// 最後依內容相似度配對。內容沒變的 cell 保留 outputs、execution_count
+// 與 metadata；內容有改的 cell 保留 ID 但清除執行結果。
+func UpdateNotebook(existing, generated *Notebook) (*Notebook, UpdateReport) {
+	var report UpdateReport
+
+	matches := matchCells(existing.Cells, generated.Cells)
+
+	usedIDs := map[string]bool{}
+	for _, oldIndex := range matches {
+		if oldIndex >= 0 && existing.Cells[oldIndex].ID != "" {
+			usedIDs[existing.Cells[oldIndex].ID] = true
+		}
+	}
+
+	result := &Notebook{
+		Cells:         make([]Cell, 0, len(generated.Cells)),
+		Metadata:      existing.Metadata,
+		NBFormat:      generated.NBFormat,
+		NBFormatMinor: generated.NBFormatMinor,
+	}
+	if existing.NBFormat == generated.NBFormat && existing.NBFormatMinor > generated.NBFormatMinor {
+		result.NBFormatMinor = existing.NBFormatMinor
+	}
+
+	var matchedOrder []int
+	for i, cell := range generated.Cells {
+		oldIndex := matches[i]
+		if oldIndex < 0 {
+			cell.ID = uniqueCellID(cell.ID, usedIDs)
+			usedIDs[cell.ID] = true
+			report.Inserted = append(report.Inserted, cell.ID)
+			result.Cells = append(result.Cells, cell)
+			continue
+		}
+
+		old := existing.Cells[oldIndex]
+		if old.ID != "" {
+			cell.ID = old.ID
+		}
+		if old.Text() == cell.Text() {
+			cell.Metadata = mergeMetadata(old.Metadata, cell.Metadata)
+			cell.ExecutionCount = old.ExecutionCount
+			if cell.CellType == "code" {
+				cell.Outputs = old.Outputs
+			}
+			report.Unchanged++
+		} else {
+			report.Edited = append(report.Edited, cell.ID)
+		}
+
+		matchedOrder = append(matchedOrder, oldIndex)
+		result.Cells = append(result.Cells, cell)
+	}
+
+	matched := map[int]bool{}
+	for _, oldIndex := range matchedOrder {
+		matched[oldIndex] = true
+	}
+	for i, cell := range existing.Cells {
+		if !matched[i] {
+			report.Removed = append(report.Removed, cell.ID)
+		}
+	}
+
+	// 不在最長遞增子序列中的 cell 就是被移動過的
+	stable := longestIncreasing(matchedOrder)
+	for _, oldIndex := range matchedOrder {
+		if !stable[oldIndex] {
+			report.Moved = append(report.Moved, existing.Cells[oldIndex].ID)
+		}
+	}
+
+	return result, report
+}
+
+// matchCells 回傳每個新 cell 對應到的舊 cell 索引，沒有對應時為 -1
+func matchCells(oldCells, newCells []Cell) []int {
+	matches := make([]int, len(newCells))
+	used := make([]bool, len(oldCells))
+	for i := range matches {
+		matches[i] = -1
+	}
+
+	// 1. 明確指定的 ID
+	for i, cell := range newCells {
+		if !cell.explicitID {
+			continue
+		}
+		for j, old := range oldCells {
+			if !used[j] && old.ID == cell.ID && old.CellType == cell.CellType {
+				matches[i] = j
+				used[j] = true
+				break
+			}
+		}
+	}
+
+	// 2. 完全相同的內容
+	for i, cell := range newCells {
+		if matches[i] >= 0 {
+			continue
+		}
+		for j, old := range oldCells {
+			if !used[j] && old.CellType == cell.CellType && old.Text() == cell.Text() {
+				matches[i] = j
+				used[j] = true
+				break
+			}
+		}
+	}
+
+	// 3. 內容相似度，分數高的先配對
+	type candidate struct {
+		newIndex, oldIndex int
+		score              float64
+	}
+	var candidates []candidate
+	for i, cell := range newCells {
+		if matches[i] >= 0 {
+			continue
+		}
+		for j, old := range oldCells {
+			if used[j] || old.CellType != cell.CellType {
+				continue
+			}
+			if score := similarity(old.Text(), cell.Text()); score >= similarityThreshold {
+				candidates = append(candidates, candidate{i, j, score})
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return candidates[a].score > candidates[b].score
+	})
+	for _, c := range candidates {
+		if matches[c.newIndex] < 0 && !used[c.oldIndex] {
+			matches[c.newIndex] = c.oldIndex
+			used[c.oldIndex] = true
+		}
+	}
+
+	return matches
+}
+
+// similarity 以字元 bigram 的 Dice 係數計算兩段文字的相似度（0 ~ 1）
+func similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < 2 || len(rb) < 2 {
+		return 0
+	}
+
+	bigrams := map[[2]rune]int{}
+	for i := 0; i < len(ra)-1; i++ {
+		bigrams[[2]rune{ra[i], ra[i+1]}]++
+	}
+
+	common := 0
+	for i := 0; i < len(rb)-1; i++ {
+		key := [2]rune{rb[i], rb[i+1]}
+		if bigrams[key] > 0 {
+			bigrams[key]--
+			common++
+		}
+	}
+
+	return 2 * float64(common) / float64(len(ra)-1+len(rb)-1)
+}
+
+// longestIncreasing 回傳 values 最長遞增子序列中的元素集合
+func longestIncreasing(values []int) map[int]bool {
+	if len(values) == 0 {
+		return map[int]bool{}
+	}
+
+	// tails[k] 為長度 k+1 的遞增子序列結尾在 values 中的索引
+	var tails []int
+	prev := make([]int, len(values))
+	for i, v := range values {
+		k := sort.Search(len(tails), func(k int) bool { return values[tails[k]] >= v })
+		if k > 0 {
+			prev[i] = tails[k-1]
+		} else {
+			prev[i] = -1
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+
+	result := map[int]bool{}
+	for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+		result[values[i]] = true
+	}
+	return result
+}
+
+// mergeMetadata 以舊的 metadata 為基礎，再套用新產生的欄位
+func mergeMetadata(old, generated CellMetadata) CellMetadata {
+	merged := CellMetadata{}
+	for key, value := range old {
+		merged[key] = value
+	}
+	for key, value := range generated {
+		merged[key] = value
+	}
+	return merged
+}
+
+// uniqueCellID 確保新 cell 的 ID 不會與保留下來的 ID 重複
+func uniqueCellID(id string, used map[string]bool) string {
+	if !used[id] {
+		return id
+	}
+	for n := len(used); ; n++ {
+		candidate := fmt.Sprintf("cell-%d", n)
+		if !used[candidate] {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func parseString(t *testing.T, input string) *Notebook {
+	t.Helper()
+	notebook, err := NewParser(strings.NewReader(input)).Parse()
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return notebook
+}
+
+func TestUpdateNotebook_PreservesUnchangedOutputs(t *testing.T) {
+	existing := NewNotebook()
+	existing.AddMarkdownCell("intro", "# Title")
+	existing.AddCodeCell("abc123", "fmt.Println(1)")
+	count := 3
+	existing.Cells[1].ExecutionCount = &count
+	existing.Cells[1].Outputs = []any{map[string]any{"output_type": "stream", "text": "1\n"}}
+	existing.Cells[1].Metadata = CellMetadata{"collapsed": true}
+
+	generated := NewNotebook()
+	generated.AddMarkdownCell("cell-0", "# Title")
+	generated.AddCodeCell("cell-1", "fmt.Println(1)")
+
+	result, report := UpdateNotebook(existing, generated)
+
+	if report.Unchanged != 2 {
+		t.Errorf("Expected 2 unchanged cells, got %d", report.Unchanged)
+	}
+	code := result.Cells[1]
+	if code.ID != "abc123" {
+		t.Errorf("Expected existing ID abc123, got %s", code.ID)
+	}
+	if code.ExecutionCount == nil || *code.ExecutionCount != 3 {
+		t.Errorf("Execution count should be preserved")
+	}
+	if len(code.Outputs) != 1 {
+		t.Errorf("Outputs should be preserved, got %d", len(code.Outputs))
+	}
+	if code.Metadata["collapsed"] != true {
+		t.Errorf("Metadata should be preserved, got %v", code.Metadata)
+	}
+}
+
+func TestUpdateNotebook_ClearsEditedCells(t *testing.T) {
+	existing := NewNotebook()
+	existing.AddCodeCell("abc123", "package main\n\nfunc main() {\n    fmt.Println(\"hello\")\n}")
+	count := 1
+	existing.Cells[0].ExecutionCount = &count
+	existing.Cells[0].Outputs = []any{"hello"}
+
+	generated := NewNotebook()
+	generated.AddCodeCell("cell-0", "package main\n\nfunc main() {\n    fmt.Println(\"hello!\")\n}")
+
+	result, report := UpdateNotebook(existing, generated)
+
+	if len(report.Edited) != 1 || report.Edited[0] != "abc123" {
+		t.Fatalf("Expected abc123 to be edited, got %v", report.Edited)
+	}
+	if result.Cells[0].ExecutionCount != nil || len(result.Cells[0].Outputs) != 0 {
+		t.Error("Edited cell should have outputs cleared")
+	}
+}
+
+func TestUpdateNotebook_InsertRemoveMove(t *testing.T) {
+	existing := NewNotebook()
+	existing.AddMarkdownCell("a", "## 第一節 goroutine 介紹")
+	existing.AddMarkdownCell("b", "## 第二節 channel 介紹")
+	existing.AddMarkdownCell("c", "## 第三節 select 介紹")
+
+	generated := NewNotebook()
+	generated.AddMarkdownCell("cell-0", "## 第三節 select 介紹")
+	generated.AddMarkdownCell("cell-1", "## 第一節 goroutine 介紹")
+	generated.AddMarkdownCell("cell-2", "完全不同的新內容")
+
+	result, report := UpdateNotebook(existing, generated)
+
+	if len(report.Inserted) != 1 {
+		t.Errorf("Expected 1 inserted cell, got %v", report.Inserted)
+	}
+	if len(report.Removed) != 1 || report.Removed[0] != "b" {
+		t.Errorf("Expected b removed, got %v", report.Removed)
+	}
+	if len(report.Moved) != 1 {
+		t.Errorf("Expected 1 moved cell, got %v", report.Moved)
+	}
+
+	ids := map[string]bool{}
+	for _, cell := range result.Cells {
+		if ids[cell.ID] {
+			t.Errorf("Duplicate cell ID %s", cell.ID)
+		}
+		ids[cell.ID] = true
+	}
+}
+
+func TestUpdateNotebook_MatchesExplicitID(t *testing.T) {
+	existing := NewNotebook()
+	existing.AddCodeCell("setup", "var x = 1")
+
+	generated := parseString(t, `<!-- CODE_CELL id="setup" -->
+var completelyDifferent = "content"
+<!-- END_CODE_CELL -->`)
+
+	result, report := UpdateNotebook(existing, generated)
+
+	if len(report.Edited) != 1 || len(report.Inserted) != 0 {
+		t.Errorf("Explicit ID should match existing cell, got %+v", report)
+	}
+	if result.Cells[0].ID != "setup" {
+		t.Errorf("Expected ID setup, got %s", result.Cells[0].ID)
+	}
+}
+
+func TestIntegration_UpdateMode(t *testing.T) {
+	testDir := t.TempDir()
+	inputPath := filepath.Join(testDir, "test.md")
+	outputPath := filepath.Join(testDir, "test.ipynb")
+
+	existing := `{
+  "cells": [
+    {"cell_type": "code", "id": "kept", "metadata": {"tags": ["demo"]},
+     "execution_count": 5, "outputs": [{"output_type": "stream", "name": "stdout", "text": "hi\n"}],
+     "source": "fmt.Println(\"hi\")"}
+  ],
+  "metadata": {"kernelspec": {"display_name": "Go (gonb)", "language": "go", "name": "gonb"},
+               "language_info": {"name": "go", "version": "go1.24.5"}, "custom": {"a": 1}},
+  "nbformat": 4, "nbformat_minor": 5
+}`
+	if err := os.WriteFile(outputPath, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	input := "<!-- MARKDOWN_CELL -->\n# Title\n<!-- END_MARKDOWN_CELL -->\n\n<!-- CODE_CELL -->\n```go\nfmt.Println(\"hi\")\n```\n<!-- END_CODE_CELL -->"
+	if err := os.WriteFile(inputPath, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := convertWithOptions(inputPath, outputPath, convertOptions{Update: true}); err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+
+	notebook, err := ReadNotebook(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+
+	if len(notebook.Cells) != 2 {
+		t.Fatalf("Expected 2 cells, got %d", len(notebook.Cells))
+	}
+	if notebook.Cells[1].ID != "kept" || len(notebook.Cells[1].Outputs) != 1 {
+		t.Errorf("Code cell should keep its ID and outputs, got %+v", notebook.Cells[1])
+	}
+	if notebook.Metadata.Kernelspec.Name != "gonb" || notebook.Metadata.LanguageInfo.Version != "go1.24.5" {
+		t.Errorf("Notebook metadata should be preserved, got %+v", notebook.Metadata)
+	}
+	if _, ok := notebook.Metadata.Extra["custom"]; !ok {
+		t.Error("Custom notebook metadata should be preserved")
+	}
+}
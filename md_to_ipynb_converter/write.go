@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// writeFileAtomic 先寫入同目錄的暫存檔並 fsync，再 rename 成目標檔案
+//
+// 寫到一半失敗時目標檔案保持原樣；backup 為 true 且目標已存在時，
+// 會先以同樣的方式把舊內容保存成 path.bak。
+func writeFileAtomic(path string, data []byte, backup bool) error {
+	perm := fs.FileMode(0644)
+	old, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if info, statErr := os.Stat(path); statErr == nil {
+			perm = info.Mode().Perm()
+		}
+	case errors.Is(err, os.ErrNotExist):
+		backup = false
+	default:
+		return err
+	}
+
+	if backup {
+		if err := replaceFile(path+".bak", old, perm); err != nil {
+			return fmt.Errorf("failed to write backup: %w", err)
+		}
+	}
+	return replaceFile(path, data, perm)
+}
+
+// replaceFile 把 data 寫入同目錄的暫存檔並 fsync，再以 rename 取代 path
+func replaceFile(path string, data []byte, perm fs.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // rename 成功後這裡不會有作用
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+
+	// 讓 rename 本身也寫入磁碟；部分平台不支援對目錄 fsync，忽略錯誤
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic_CreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.ipynb")
+
+	if err := writeFileAtomic(path, []byte("new"), true); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "new" {
+		t.Errorf("Expected new, got %q", data)
+	}
+	if _, err := os.Stat(path + ".bak"); !os.IsNotExist(err) {
+		t.Error("No backup should be written for a new file")
+	}
+}
+
+func TestWriteFileAtomic_Backup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.ipynb")
+	if err := os.WriteFile(path, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFileAtomic(path, []byte("new"), true); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	backup, _ := os.ReadFile(path + ".bak")
+	if string(backup) != "old" {
+		t.Errorf("Backup should hold old content, got %q", backup)
+	}
+
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("File mode should be preserved, got %v", info.Mode().Perm())
+	}
+
+	// 再寫一次時既有的 .bak 也以 rename 取代
+	if err := writeFileAtomic(path, []byte("newer"), true); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	backup, _ = os.ReadFile(path + ".bak")
+	if string(backup) != "new" {
+		t.Errorf("Backup should hold the previous content, got %q", backup)
+	}
+	if info, _ := os.Stat(path + ".bak"); info.Mode().Perm() != 0600 {
+		t.Errorf("Backup mode should match the file, got %v", info.Mode().Perm())
+	}
+
+	// 不應留下暫存檔
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("Expected only the file and its backup, got %d entries", len(entries))
+	}
+}
+
+func TestWriteFileAtomic_MissingDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "test.ipynb")
+
+	if err := writeFileAtomic(path, []byte("new"), false); err == nil {
+		t.Error("Expected error for missing directory, got nil")
+	}
+}
+
+func TestIntegration_DryRun(t *testing.T) {
+	testDir := t.TempDir()
+	inputPath := filepath.Join(testDir, "test.md")
+	outputPath := filepath.Join(testDir, "test.ipynb")
+
+	if err := os.WriteFile(inputPath, []byte("<!-- MARKDOWN_CELL -->\n# Title\n<!-- END_MARKDOWN_CELL -->"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := convertWithOptions(inputPath, outputPath, convertOptions{DryRun: true}); err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+
+	if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Error("Dry run must not write the output file")
+	}
+}
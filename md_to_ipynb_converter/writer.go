@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+)
+
+// generatedIDRegex 符合 Parser 自動產生的 ID，這類 ID 不需要寫回標記
+var generatedIDRegex = regexp.MustCompile(`^cell-\d+$`)
+
+// WriteMarkdown 將 notebook 輸出成轉換器使用的標記 Markdown 格式
+func WriteMarkdown(w io.Writer, nb *Notebook) error {
+	for i, cell := range nb.Cells {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, renderMarkerCell(cell)+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderMarkerCell 將單一 cell 輸出成含開始與結束標記的區塊（結尾沒有換行）
+func renderMarkerCell(cell Cell) string {
+	marker := "MARKDOWN_CELL"
+	if cell.CellType == "code" {
+		marker = "CODE_CELL"
+	}
+
+	attrs := ""
+	if cell.ID != "" && !generatedIDRegex.MatchString(cell.ID) {
+		attrs = fmt.Sprintf(" id=%q", cell.ID)
+	}
+
+	return fmt.Sprintf("<!-- %s%s -->\n%s\n<!-- END_%s -->", marker, attrs, renderCellBody(cell.CellType, cell.Text()), marker)
+}
+
+// renderCellBody 輸出標記之間的內容；code cell 會加上 ```go fence
+// 內容原樣輸出，重新解析後會得到相同的 cell 內容
+func renderCellBody(cellType, text string) string {
+	if cellType == "code" {
+		return "```go\n" + text + "\n```"
+	}
+	return text
+}